@@ -0,0 +1,529 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/config"
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/database"
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/imaging"
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/services"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	// Load .env file
+	err := godotenv.Load()
+	if err != nil {
+		log.Printf("Warning: .env file not found: %v", err)
+	}
+
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+
+	switch os.Args[1] {
+	case "sync":
+		runSync(ctx, os.Args[2:])
+	case "rebuild":
+		runRebuild(ctx, os.Args[2:])
+	case "export-metadata":
+		runExportMetadata(ctx)
+	case "backfill-hashes":
+		runBackfillHashes(ctx)
+	case "backfill-exif":
+		runBackfillEXIF(ctx)
+	case "backfill-colors":
+		runBackfillColors(ctx)
+	case "backfill-placeholders":
+		runBackfillPlaceholders(ctx)
+	case "backfill-focal-points":
+		runBackfillFocalPoints(ctx)
+	case "backfill-sanitized":
+		runBackfillSanitized(ctx)
+	case "backfill-phashes":
+		runBackfillPerceptualHashes(ctx)
+	case "backfill-variants":
+		runBackfillVariants(ctx, os.Args[2:])
+	case "duplicates":
+		runDuplicates(ctx, os.Args[2:])
+	case "pending":
+		runPending(ctx, os.Args[2:])
+	case "prune-shuffle-bags":
+		runPruneShuffleBags(ctx, os.Args[2:])
+	case "oauth-login":
+		runOAuthLogin(ctx)
+	case "help", "-h", "--help":
+		printUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", os.Args[1])
+		printUsage()
+		os.Exit(2)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: admin <command> [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Commands:")
+	fmt.Fprintln(os.Stderr, "  sync [-apply]    Reconcile the Google Drive folder with the images table (dry run by default)")
+	fmt.Fprintln(os.Stderr, "  rebuild [-apply] Rebuild the images and locations tables from Drive metadata (dry run by default)")
+	fmt.Fprintln(os.Stderr, "  export-metadata  Copy database metadata to the Drive files so they can be rebuilt later")
+	fmt.Fprintln(os.Stderr, "  backfill-hashes  Store SHA-256 content hashes and MIME types for images uploaded before they were recorded")
+	fmt.Fprintln(os.Stderr, "  backfill-exif    Store capture time and camera settings for images uploaded before they were recorded")
+	fmt.Fprintln(os.Stderr, "  backfill-colors  Store the dominant color, palette and foreground of images uploaded before they were recorded")
+	fmt.Fprintln(os.Stderr, "  backfill-placeholders")
+	fmt.Fprintln(os.Stderr, "                   Store BlurHash strings and low-quality placeholders of images uploaded before they were recorded")
+	fmt.Fprintln(os.Stderr, "  backfill-focal-points")
+	fmt.Fprintln(os.Stderr, "                   Store the focal points of images uploaded before they were recorded")
+	fmt.Fprintln(os.Stderr, "  backfill-sanitized")
+	fmt.Fprintln(os.Stderr, "                   Serve metadata-stripped copies of images uploaded before sanitization, keeping the files as private originals")
+	fmt.Fprintln(os.Stderr, "  backfill-phashes Store perceptual hashes for images uploaded before they were recorded")
+	fmt.Fprintln(os.Stderr, "  backfill-variants [-all]")
+	fmt.Fprintln(os.Stderr, "                   Generate resized variants for images that have none (or all images)")
+	fmt.Fprintln(os.Stderr, "  duplicates [-threshold N]")
+	fmt.Fprintln(os.Stderr, "                   List clusters of near-duplicate images by perceptual hash distance")
+	fmt.Fprintln(os.Stderr, "  pending [-retry] List pending storage operations, optionally retrying the due ones")
+	fmt.Fprintln(os.Stderr, "  prune-shuffle-bags [-days N]")
+	fmt.Fprintln(os.Stderr, "                   Delete the shuffle bags of viewers not seen for N days (30 by default)")
+	fmt.Fprintln(os.Stderr, "  oauth-login      Authorize Google Drive from a terminal and store the OAuth token")
+}
+
+// runSync reconciles Drive with the database and prints the report as JSON
+func runSync(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("sync", flag.ExitOnError)
+	apply := flags.Bool("apply", false, "import untracked Drive images instead of only reporting them")
+	_ = flags.Parse(args)
+
+	driveUtil := newDriveUtil(ctx)
+	dbService := newDatabase(ctx)
+	defer dbService.Close()
+
+	syncService := services.NewSyncService(driveUtil, dbService)
+	report, err := syncService.Reconcile(ctx, !*apply)
+	if err != nil {
+		log.Fatalf("Sync failed: %v", err)
+	}
+
+	printJSON(report)
+}
+
+// runRebuild restores the images and locations tables from Drive and prints the report as JSON
+func runRebuild(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("rebuild", flag.ExitOnError)
+	apply := flags.Bool("apply", false, "write the restored images to the database instead of only reporting them")
+	_ = flags.Parse(args)
+
+	driveUtil := newDriveUtil(ctx)
+	dbService := newDatabase(ctx)
+	defer dbService.Close()
+
+	syncService := services.NewSyncService(driveUtil, dbService)
+	report, err := syncService.Rebuild(ctx, !*apply)
+	if err != nil {
+		log.Fatalf("Rebuild failed: %v", err)
+	}
+
+	printJSON(report)
+}
+
+// runExportMetadata writes the metadata of every database image to its Drive file
+func runExportMetadata(ctx context.Context) {
+	driveUtil := newDriveUtil(ctx)
+	dbService := newDatabase(ctx)
+	defer dbService.Close()
+
+	syncService := services.NewSyncService(driveUtil, dbService)
+	exported, errs, err := syncService.ExportMetadata(ctx)
+	if err != nil {
+		log.Fatalf("Export failed: %v", err)
+	}
+
+	printJSON(map[string]interface{}{
+		"exported": exported,
+		"errors":   errs,
+	})
+}
+
+// runBackfillHashes computes content hashes for images that have none
+func runBackfillHashes(ctx context.Context) {
+	dbService := newDatabase(ctx)
+	defer dbService.Close()
+
+	storage, err := services.NewStorageServiceFromEnv(ctx, newDriveConfig())
+	if err != nil {
+		log.Fatalf("Failed to create storage service: %v", err)
+	}
+
+	updated, errs, err := services.NewImageService(storage, dbService).BackfillContentHashes(ctx)
+	if err != nil {
+		log.Fatalf("Backfill failed: %v", err)
+	}
+
+	printJSON(map[string]interface{}{
+		"updated": updated,
+		"errors":  errs,
+	})
+}
+
+// runBackfillEXIF reads capture metadata for images that have none
+func runBackfillEXIF(ctx context.Context) {
+	dbService := newDatabase(ctx)
+	defer dbService.Close()
+
+	storage, err := services.NewStorageServiceFromEnv(ctx, newDriveConfig())
+	if err != nil {
+		log.Fatalf("Failed to create storage service: %v", err)
+	}
+
+	updated, errs, err := services.NewImageService(storage, dbService).BackfillCaptureMetadata(ctx)
+	if err != nil {
+		log.Fatalf("Backfill failed: %v", err)
+	}
+
+	printJSON(map[string]interface{}{
+		"updated": updated,
+		"errors":  errs,
+	})
+}
+
+// runBackfillColors computes color themes for existing images
+func runBackfillColors(ctx context.Context) {
+	dbService := newDatabase(ctx)
+	defer dbService.Close()
+
+	storage, err := services.NewStorageServiceFromEnv(ctx, newDriveConfig())
+	if err != nil {
+		log.Fatalf("Failed to create storage service: %v", err)
+	}
+
+	updated, errs, err := services.NewImageService(storage, dbService).BackfillColorThemes(ctx)
+	if err != nil {
+		log.Fatalf("Backfill failed: %v", err)
+	}
+
+	printJSON(map[string]interface{}{
+		"updated": updated,
+		"errors":  errs,
+	})
+}
+
+// runBackfillPlaceholders computes BlurHash strings and low-quality placeholders for existing images
+func runBackfillPlaceholders(ctx context.Context) {
+	dbService := newDatabase(ctx)
+	defer dbService.Close()
+
+	storage, err := services.NewStorageServiceFromEnv(ctx, newDriveConfig())
+	if err != nil {
+		log.Fatalf("Failed to create storage service: %v", err)
+	}
+
+	updated, errs, err := services.NewImageService(storage, dbService).BackfillPlaceholders(ctx)
+	if err != nil {
+		log.Fatalf("Backfill failed: %v", err)
+	}
+
+	printJSON(map[string]interface{}{
+		"updated": updated,
+		"errors":  errs,
+	})
+}
+
+// runBackfillFocalPoints detects and stores focal points for existing images
+func runBackfillFocalPoints(ctx context.Context) {
+	dbService := newDatabase(ctx)
+	defer dbService.Close()
+
+	storage, err := services.NewStorageServiceFromEnv(ctx, newDriveConfig())
+	if err != nil {
+		log.Fatalf("Failed to create storage service: %v", err)
+	}
+
+	updated, errs, err := services.NewImageService(storage, dbService).BackfillFocalPoints(ctx)
+	if err != nil {
+		log.Fatalf("Backfill failed: %v", err)
+	}
+
+	printJSON(map[string]interface{}{
+		"updated": updated,
+		"errors":  errs,
+	})
+}
+
+// runBackfillSanitized stores sanitized public copies of existing images
+func runBackfillSanitized(ctx context.Context) {
+	dbService := newDatabase(ctx)
+	defer dbService.Close()
+
+	storage, err := services.NewStorageServiceFromEnv(ctx, newDriveConfig())
+	if err != nil {
+		log.Fatalf("Failed to create storage service: %v", err)
+	}
+
+	imageService := services.NewImageServiceWithConfig(storage, dbService, services.LoadImageConfigFromEnv())
+	updated, errs, err := imageService.BackfillSanitizedCopies(ctx)
+	if err != nil {
+		log.Fatalf("Backfill failed: %v", err)
+	}
+
+	printJSON(map[string]interface{}{
+		"updated": updated,
+		"errors":  errs,
+	})
+}
+
+// runBackfillPerceptualHashes computes perceptual hashes for existing images
+func runBackfillPerceptualHashes(ctx context.Context) {
+	dbService := newDatabase(ctx)
+	defer dbService.Close()
+
+	storage, err := services.NewStorageServiceFromEnv(ctx, newDriveConfig())
+	if err != nil {
+		log.Fatalf("Failed to create storage service: %v", err)
+	}
+
+	updated, errs, err := services.NewImageService(storage, dbService).BackfillPerceptualHashes(ctx)
+	if err != nil {
+		log.Fatalf("Backfill failed: %v", err)
+	}
+
+	printJSON(map[string]interface{}{
+		"updated": updated,
+		"errors":  errs,
+	})
+}
+
+// runDuplicates prints the clusters of near-duplicate images as JSON
+func runDuplicates(ctx context.Context, args []string) {
+	config := services.LoadImageConfigFromEnv()
+	flags := flag.NewFlagSet("duplicates", flag.ExitOnError)
+	threshold := flags.Int("threshold", config.DuplicateThreshold, "largest perceptual hash distance (0-64) between near duplicates")
+	_ = flags.Parse(args)
+	if *threshold < 0 || *threshold > imaging.HashBits {
+		log.Fatalf("Threshold must be between 0 and %d", imaging.HashBits)
+	}
+
+	dbService := newDatabase(ctx)
+	defer dbService.Close()
+
+	// Only the database is read, so no storage backend is needed
+	report, err := services.NewImageServiceWithConfig(nil, dbService, config).FindDuplicates(ctx, *threshold)
+	if err != nil {
+		log.Fatalf("Failed to find duplicates: %v", err)
+	}
+
+	printJSON(report)
+}
+
+// runBackfillVariants generates resized variants for existing images
+func runBackfillVariants(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("backfill-variants", flag.ExitOnError)
+	all := flags.Bool("all", false, "regenerate the variants of every image, not only images without variants")
+	_ = flags.Parse(args)
+
+	dbService := newDatabase(ctx)
+	defer dbService.Close()
+
+	storage, err := services.NewStorageServiceFromEnv(ctx, newDriveConfig())
+	if err != nil {
+		log.Fatalf("Failed to create storage service: %v", err)
+	}
+	imageService := services.NewImageServiceWithConfig(storage, dbService, services.LoadImageConfigFromEnv())
+
+	imagesInterface, err := dbService.ListImages(ctx)
+	if err != nil {
+		log.Fatalf("Failed to list images: %v", err)
+	}
+
+	updated := []string{}
+	var errs []string
+	for _, imgInterface := range imagesInterface {
+		img, ok := imgInterface.(*pb.ImageMetadata)
+		if !ok {
+			continue
+		}
+
+		if !*all {
+			variants, err := dbService.ListImageVariants(ctx, img.Id)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("failed to list variants of %s: %v", img.Id, err))
+				continue
+			}
+			hasResized := false
+			for _, variant := range variants {
+				hasResized = hasResized || !services.IsCropVariant(variant)
+			}
+			if hasResized {
+				continue
+			}
+		}
+
+		if _, err := imageService.GenerateVariants(ctx, img.Id); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to generate variants of %s: %v", img.Id, err))
+			continue
+		}
+		updated = append(updated, img.Id)
+	}
+
+	printJSON(map[string]interface{}{
+		"updated": updated,
+		"errors":  errs,
+	})
+}
+
+// runPending lists pending storage operations and optionally retries them
+func runPending(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("pending", flag.ExitOnError)
+	retry := flags.Bool("retry", false, "retry the operations that are due before listing")
+	_ = flags.Parse(args)
+
+	dbService := newDatabase(ctx)
+	defer dbService.Close()
+
+	if *retry {
+		storage, err := services.NewStorageServiceFromEnv(ctx, newDriveConfig())
+		if err != nil {
+			log.Fatalf("Failed to create storage service: %v", err)
+		}
+
+		completed, err := services.NewOperationRetrier(storage, dbService).RunOnce(ctx)
+		if err != nil {
+			log.Fatalf("Retry failed: %v", err)
+		}
+		log.Printf("Completed %d pending operations", completed)
+	}
+
+	operations, err := dbService.ListPendingOperations(ctx)
+	if err != nil {
+		log.Fatalf("Failed to list pending operations: %v", err)
+	}
+
+	printJSON(operations)
+}
+
+// runPruneShuffleBags deletes the shuffle bags of viewers who have not been
+// seen for a while; they start a new bag on their next visit
+func runPruneShuffleBags(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("prune-shuffle-bags", flag.ExitOnError)
+	days := flags.Int("days", 30, "delete bags not used for this many days")
+	_ = flags.Parse(args)
+	if *days < 1 {
+		log.Fatalf("Days must be at least 1")
+	}
+
+	dbService := newDatabase(ctx)
+	defer dbService.Close()
+
+	deleted, err := dbService.DeleteShuffleBagsBefore(ctx, time.Now().AddDate(0, 0, -*days))
+	if err != nil {
+		log.Fatalf("Failed to prune shuffle bags: %v", err)
+	}
+	log.Printf("Deleted %d shuffle bags", deleted)
+}
+
+// runOAuthLogin authorizes Google Drive by pasting the authorization code into the terminal
+func runOAuthLogin(ctx context.Context) {
+	if services.DriveAuthMode(config.LoadConfig().GoogleDriveAuthMode) == services.DriveAuthModeServiceAccount {
+		log.Fatalf("oauth-login is not needed when GOOGLE_DRIVE_AUTH_MODE=service_account")
+	}
+
+	oauthConfigPath, tokenPath := oauthPaths()
+
+	oauthConfig, err := services.LoadOAuthConfig(oauthConfigPath)
+	if err != nil {
+		log.Fatalf("Failed to load OAuth config: %v", err)
+	}
+
+	flow := services.NewOAuthFlow(oauthConfig, services.NewFileTokenStore(tokenPath))
+	authURL, err := flow.AuthCodeURL()
+	if err != nil {
+		log.Fatalf("Failed to start authorization: %v", err)
+	}
+
+	parsedURL, err := url.Parse(authURL)
+	if err != nil {
+		log.Fatalf("Failed to parse authorization URL: %v", err)
+	}
+
+	fmt.Printf("Visit this URL to authorize Google Drive:\n%s\n\n", authURL)
+	fmt.Print("Enter the code parameter from the redirect URL: ")
+
+	var authCode string
+	if _, err := fmt.Scan(&authCode); err != nil {
+		log.Fatalf("Failed to read authorization code: %v", err)
+	}
+
+	token, err := flow.Exchange(ctx, parsedURL.Query().Get("state"), authCode)
+	if err != nil {
+		log.Fatalf("Authorization failed: %v", err)
+	}
+
+	fmt.Printf("Token saved to %s (expires %s)\n", tokenPath, token.Expiry.Format(time.RFC3339))
+}
+
+// newDriveUtil creates the Google Drive client from the environment, like the legacy server.
+// GOOGLE_DRIVE_AUTH_MODE selects OAuth2 or a service account.
+func newDriveUtil(ctx context.Context) *services.DriveUtilOAuth {
+	folderID := os.Getenv("GOOGLE_DRIVE_FOLDER_ID")
+	if folderID == "" {
+		log.Fatalf("GOOGLE_DRIVE_FOLDER_ID environment variable is required")
+	}
+
+	driveUtil, err := services.NewDriveUtilFromConfig(ctx, newDriveConfig())
+	if err != nil {
+		log.Fatalf("Failed to create Drive utility: %v", err)
+	}
+	return driveUtil
+}
+
+// newDriveConfig returns the Google Drive settings from the environment
+func newDriveConfig() services.DriveConfig {
+	driveConfig := services.NewDriveConfig(config.LoadConfig(), os.Getenv("GOOGLE_DRIVE_FOLDER_ID"))
+	driveConfig.OAuthConfigPath, driveConfig.TokenPath = oauthPaths()
+	return driveConfig
+}
+
+// oauthPaths returns the OAuth2 credentials and token paths in the secrets
+// directory (production) or the current directory (local)
+func oauthPaths() (string, string) {
+	oauthConfigPath := "/app/secrets/oauth_credentials.json"
+	if _, err := os.Stat(oauthConfigPath); os.IsNotExist(err) {
+		oauthConfigPath = "oauth_credentials.json" // Fallback to local development
+	}
+
+	tokenPath := "/app/secrets/token.json"
+	if _, err := os.Stat(tokenPath); os.IsNotExist(err) {
+		tokenPath = "token.json" // Fallback to local development
+	}
+
+	return oauthConfigPath, tokenPath
+}
+
+// newDatabase connects to the database selected by DATABASE_TYPE
+func newDatabase(ctx context.Context) interfaces.DatabaseService {
+	dbService, err := database.NewDatabaseServiceFromEnv(ctx)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	return dbService
+}
+
+// printJSON writes a value to stdout as indented JSON
+func printJSON(value interface{}) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		log.Fatalf("Failed to encode output: %v", err)
+	}
+}
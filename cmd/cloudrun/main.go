@@ -53,8 +53,11 @@ func main() {
 		cloudSQLPassword = os.Getenv("CLOUD_SQL_PASSWORD")
 	}
 
+	// Determine which storage backend to use for image files
+	storageType := services.GetStorageTypeFromEnv()
+
 	// Validate required configuration
-	if folderID == "" {
+	if storageType == services.StorageTypeDrive && folderID == "" {
 		log.Fatalf("GOOGLE_DRIVE_FOLDER_ID is required")
 	}
 	if mapsAPIKey == "" {
@@ -73,32 +76,32 @@ func main() {
 		log.Fatalf("CLOUD_SQL_PASSWORD is required")
 	}
 
-	// Handle OAuth2 credentials
+	// Select OAuth2 or service-account authentication for Google Drive
+	appConfig := config.LoadConfig()
+	driveAuthMode := services.DriveAuthMode(appConfig.GoogleDriveAuthMode)
+
+	// Handle OAuth2 credentials (only needed for Google Drive storage with OAuth2)
 	var oauthConfigPath, tokenPath string
 
-	if secretManager != nil {
+	if storageType != services.StorageTypeDrive {
+		log.Printf("Using %s storage backend, skipping OAuth2 credentials", storageType)
+	} else if driveAuthMode == services.DriveAuthModeServiceAccount {
+		log.Printf("Using service account for Google Drive, skipping OAuth2 credentials")
+	} else if secretManager != nil {
 		// In production, get credentials from Secret Manager
 		oauthConfigData, err := secretManager.GetSecret("oauth-credentials")
 		if err != nil {
 			log.Fatalf("Failed to get OAuth credentials from Secret Manager: %v", err)
 		}
 
-		tokenData, err := secretManager.GetSecret("oauth-token")
-		if err != nil {
-			log.Fatalf("Failed to get OAuth token from Secret Manager: %v", err)
-		}
-
-		// Write credentials to files
+		// Write credentials to file; the token itself is read from and
+		// written back to Secret Manager by the token store
 		oauthConfigPath = "/tmp/oauth_credentials.json"
 		tokenPath = "/tmp/token.json"
 
 		if err := os.WriteFile(oauthConfigPath, []byte(oauthConfigData), 0600); err != nil {
 			log.Fatalf("Failed to write OAuth credentials file: %v", err)
 		}
-
-		if err := os.WriteFile(tokenPath, []byte(tokenData), 0600); err != nil {
-			log.Fatalf("Failed to write OAuth token file: %v", err)
-		}
 	} else {
 		// In local development, use existing files
 		oauthConfigPath = "/app/secrets/oauth_credentials.json"
@@ -112,9 +115,23 @@ func main() {
 		}
 	}
 
-	driveUtil, err := services.NewDriveUtilOAuth(ctx, oauthConfigPath, tokenPath, folderID)
+	// Persist OAuth tokens (including refreshes) to Secret Manager in production
+	var tokenStore services.TokenStore
+	if storageType == services.StorageTypeDrive && driveAuthMode != services.DriveAuthModeServiceAccount {
+		tokenStore, err = services.NewTokenStoreFromEnv(secretManager, tokenPath)
+		if err != nil {
+			log.Fatalf("Failed to create OAuth token store: %v", err)
+		}
+	}
+
+	driveConfig := services.NewDriveConfig(appConfig, folderID)
+	driveConfig.OAuthConfigPath = oauthConfigPath
+	driveConfig.TokenPath = tokenPath
+	driveConfig.TokenStore = tokenStore
+
+	storage, err := services.NewStorageServiceWithType(ctx, storageType, driveConfig)
 	if err != nil {
-		log.Fatalf("Failed to create Drive utility: %v", err)
+		log.Fatalf("Failed to create storage service: %v", err)
 	}
 
 	// Create database service with fallback
@@ -127,18 +144,33 @@ func main() {
 	defer dbService.Close()
 
 	// Create services
-	imageService := services.NewImageService(driveUtil, dbService)
+	imageService := services.NewImageServiceWithConfig(storage, dbService, services.LoadImageConfigFromEnv())
 	locationService, err := services.NewLocationService(mapsAPIKey)
 	if err != nil {
 		log.Fatalf("Failed to create location service: %v", err)
 	}
+	imageService.SetLocationService(locationService)
+
+	// Retry storage cleanups left behind by failed uploads and deletes
+	services.NewOperationRetrier(storage, dbService).Start(ctx, services.GetRetryIntervalFromEnv())
+
+	// Drive reconciliation and OAuth authorization are only available with the Google Drive backend
+	var syncService *services.SyncService
+	var oauthFlow *services.OAuthFlow
+	if driveUtil, ok := storage.(*services.DriveUtilOAuth); ok {
+		syncService = services.NewSyncService(driveUtil, dbService)
+		oauthFlow = driveUtil.OAuthFlow()
+	}
 
 	// Create HTTP handler with direct service access
-	handler := handlers.NewDirectHTTPHandler(imageService, locationService)
+	adminConfig := middleware.GetAdminConfig()
+	handler := handlers.NewDirectHTTPHandler(imageService, locationService, adminConfig)
+	adminHandler := handlers.NewAdminHTTPHandler(syncService, oauthFlow, imageService, adminConfig)
 
 	// Setup routes
 	mux := http.NewServeMux()
 	handler.RegisterRoutes(mux)
+	adminHandler.RegisterRoutes(mux)
 
 	// Setup CORS middleware
 	corsConfig := middleware.GetCORSConfig()
@@ -172,14 +204,25 @@ func main() {
 	fmt.Println("CORS enabled for origins:", corsConfig.AllowedOrigins)
 	fmt.Println("Available endpoints:")
 	fmt.Println("  GET  /api/v1/images/current")
+	fmt.Println("  GET  /api/v1/images/current/raw")
 	fmt.Println("  POST /api/v1/images/upload")
 	fmt.Println("  GET  /api/v1/images/count")
 	fmt.Println("  GET  /api/v1/images")
 	fmt.Println("  GET  /api/v1/images/{id}")
+	fmt.Println("  GET  /api/v1/images/{id}/raw")
+	fmt.Println("  PATCH /api/v1/images/{id}")
 	fmt.Println("  DELETE /api/v1/images/{id}")
 	fmt.Println("  GET  /api/v1/location/coords?lat=37.7749&lng=-122.4194")
 	fmt.Println("  GET  /api/v1/location/name?name=San Francisco")
 	fmt.Println("  GET  /health")
+	fmt.Println("  GET  /api/v1/admin/sync (admin)")
+	fmt.Println("  POST /api/v1/admin/sync (admin)")
+	fmt.Println("  POST /api/v1/images/current/pin (admin)")
+	fmt.Println("  DELETE /api/v1/images/current/pin (admin)")
+	fmt.Println("  GET  /api/v1/images/current/pins (admin)")
+	fmt.Println("  GET  /api/v1/admin/oauth/authorize (admin)")
+	fmt.Println("  GET  /api/v1/admin/oauth/status (admin)")
+	fmt.Println("  GET  /api/v1/oauth/callback")
 
 	// Clean up Secret Manager
 	if secretManager != nil {
@@ -10,6 +10,7 @@ import (
 
 	"github.com/NirvekPanda/Background-Image-Drive-API/internal/handlers"
 	"github.com/NirvekPanda/Background-Image-Drive-API/internal/middleware"
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/services"
 	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
 	"github.com/joho/godotenv"
 	"google.golang.org/grpc"
@@ -35,7 +36,10 @@ func main() {
 	fmt.Printf("Attempting to connect to gRPC server at: %s\n", grpcAddr)
 
 	for i := 0; i < 10; i++ {
-		conn, err = grpc.NewClient(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		conn, err = grpc.NewClient(grpcAddr,
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(services.MaxUploadMessageSize(services.LoadUploadLimitsFromEnv()))),
+		)
 		if err == nil {
 			fmt.Printf("Successfully connected to gRPC server!\n")
 			break
@@ -67,7 +71,7 @@ func main() {
 	fmt.Println("gRPC connection test successful!")
 
 	// Create HTTP handler (both services use the same connection)
-	handler := handlers.NewHTTPHandler(conn, conn)
+	handler := handlers.NewHTTPHandler(conn, conn, middleware.GetAdminConfig())
 
 	// Setup routes
 	mux := http.NewServeMux()
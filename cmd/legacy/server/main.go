@@ -7,7 +7,9 @@ import (
 	"net"
 	"os"
 
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/config"
 	"github.com/NirvekPanda/Background-Image-Drive-API/internal/database"
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/middleware"
 	"github.com/NirvekPanda/Background-Image-Drive-API/internal/services"
 	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
 	"github.com/joho/godotenv"
@@ -22,13 +24,14 @@ func main() {
 		log.Printf("Warning: .env file not found: %v", err)
 	}
 
-	// Get Google Drive folder ID
+	// Get Google Drive folder ID (only required for Google Drive storage)
+	storageType := services.GetStorageTypeFromEnv()
 	folderID := os.Getenv("GOOGLE_DRIVE_FOLDER_ID")
-	if folderID == "" {
+	if storageType == services.StorageTypeDrive && folderID == "" {
 		log.Fatalf("GOOGLE_DRIVE_FOLDER_ID environment variable is required")
 	}
 
-	// Create Google Drive utility with OAuth2
+	// Create storage backend (Google Drive with OAuth2 or a service account by default)
 	ctx := context.Background()
 
 	// Check for OAuth2 credentials in secrets directory (production) or current directory (local)
@@ -42,9 +45,13 @@ func main() {
 		tokenPath = "token.json" // Fallback to local development
 	}
 
-	driveUtil, err := services.NewDriveUtilOAuth(ctx, oauthConfigPath, tokenPath, folderID)
+	driveConfig := services.NewDriveConfig(config.LoadConfig(), folderID)
+	driveConfig.OAuthConfigPath = oauthConfigPath
+	driveConfig.TokenPath = tokenPath
+
+	storage, err := services.NewStorageServiceWithType(ctx, storageType, driveConfig)
 	if err != nil {
-		log.Fatalf("Failed to create Drive utility: %v", err)
+		log.Fatalf("Failed to create storage service: %v", err)
 	}
 
 	// Get Google Maps API key
@@ -67,14 +74,23 @@ func main() {
 	defer dbService.Close()
 
 	// Create services
-	imageService := services.NewImageService(driveUtil, dbService)
+	imageConfig := services.LoadImageConfigFromEnv()
+	imageService := services.NewImageServiceWithConfig(storage, dbService, imageConfig)
 	locationService, err := services.NewLocationService(mapsAPIKey)
 	if err != nil {
 		log.Fatalf("Failed to create location service: %v", err)
 	}
+	imageService.SetLocationService(locationService)
+
+	// Retry storage cleanups left behind by failed uploads and deletes
+	services.NewOperationRetrier(storage, dbService).Start(ctx, services.GetRetryIntervalFromEnv())
 
-	// Create gRPC server
-	grpcServer := grpc.NewServer()
+	// Create gRPC server, accepting uploads up to the image byte limit. Admin
+	// calls need the ADMIN_API_KEY in the x-admin-key metadata.
+	grpcServer := grpc.NewServer(
+		grpc.MaxRecvMsgSize(services.MaxUploadMessageSize(imageConfig.Limits)),
+		grpc.UnaryInterceptor(middleware.AdminUnaryInterceptor(middleware.GetAdminConfig(), services.RequiresAdmin)),
+	)
 
 	// Register services
 	pb.RegisterImageServiceServer(grpcServer, imageService)
@@ -11,8 +11,11 @@ type Config struct {
 	HTTPPort string
 
 	// Google Drive configuration
-	GoogleDriveCredentialsPath string
-	GoogleDriveFolderID        string
+	GoogleDriveCredentialsPath    string // empty uses credentials.json when present
+	GoogleDriveFolderID           string
+	GoogleDriveAuthMode           string // "oauth" or "service_account"
+	GoogleDriveImpersonateSubject string // user to impersonate with domain-wide delegation
+	GoogleDriveSharedDriveID      string
 
 	// Google Maps configuration
 	GoogleMapsAPIKey string
@@ -30,16 +33,19 @@ type Config struct {
 // LoadConfig loads configuration from environment variables
 func LoadConfig() *Config {
 	return &Config{
-		GRPCPort:                   getEnv("GRPC_PORT", "50051"),
-		HTTPPort:                   getEnv("HTTP_PORT", "8080"),
-		GoogleDriveCredentialsPath: getEnv("GOOGLE_DRIVE_CREDENTIALS_PATH", "credentials.json"),
-		GoogleDriveFolderID:        getEnv("GOOGLE_DRIVE_FOLDER_ID", ""),
-		GoogleMapsAPIKey:           getEnv("GOOGLE_MAPS_API_KEY", ""),
-		CloudSQLConnectionName:     getEnv("CLOUD_SQL_CONNECTION_NAME", ""),
-		CloudSQLDatabase:           getEnv("CLOUD_SQL_DATABASE", ""),
-		CloudSQLUser:               getEnv("CLOUD_SQL_USER", ""),
-		CloudSQLPassword:           getEnv("CLOUD_SQL_PASSWORD", ""),
-		DatabaseURL:                getEnv("DATABASE_URL", ""),
+		GRPCPort:                      getEnv("GRPC_PORT", "50051"),
+		HTTPPort:                      getEnv("HTTP_PORT", "8080"),
+		GoogleDriveCredentialsPath:    getEnv("GOOGLE_DRIVE_CREDENTIALS_PATH", ""),
+		GoogleDriveFolderID:           getEnv("GOOGLE_DRIVE_FOLDER_ID", ""),
+		GoogleDriveAuthMode:           getEnv("GOOGLE_DRIVE_AUTH_MODE", "oauth"),
+		GoogleDriveImpersonateSubject: getEnv("GOOGLE_DRIVE_IMPERSONATE_SUBJECT", ""),
+		GoogleDriveSharedDriveID:      getEnv("GOOGLE_DRIVE_SHARED_DRIVE_ID", ""),
+		GoogleMapsAPIKey:              getEnv("GOOGLE_MAPS_API_KEY", ""),
+		CloudSQLConnectionName:        getEnv("CLOUD_SQL_CONNECTION_NAME", ""),
+		CloudSQLDatabase:              getEnv("CLOUD_SQL_DATABASE", ""),
+		CloudSQLUser:                  getEnv("CLOUD_SQL_USER", ""),
+		CloudSQLPassword:              getEnv("CLOUD_SQL_PASSWORD", ""),
+		DatabaseURL:                   getEnv("DATABASE_URL", ""),
 	}
 }
 
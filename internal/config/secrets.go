@@ -30,29 +30,15 @@ func NewSecretManager(ctx context.Context) (*SecretManager, error) {
 
 // GetSecret retrieves a secret from Google Cloud Secret Manager
 func (sm *SecretManager) GetSecret(secretName string) (string, error) {
-	projectID := os.Getenv("GOOGLE_CLOUD_PROJECT")
-	if projectID == "" {
-		// Try to get project ID from other environment variables
-		projectID = os.Getenv("GCP_PROJECT")
-		if projectID == "" {
-			projectID = os.Getenv("GCLOUD_PROJECT")
-			if projectID == "" {
-				// In Cloud Run, the project ID is available in the metadata
-				// For now, hardcode it since we know it
-				projectID = "portfolio-420-69"
-			}
-		}
-	}
-
 	// Create the request
 	req := &secretmanagerpb.AccessSecretVersionRequest{
-		Name: fmt.Sprintf("projects/%s/secrets/%s/versions/latest", projectID, secretName),
+		Name: fmt.Sprintf("projects/%s/secrets/%s/versions/latest", projectID(), secretName),
 	}
 
 	// Call the API
 	result, err := sm.client.AccessSecretVersion(sm.ctx, req)
 	if err != nil {
-		return "", fmt.Errorf("failed to access secret %s: %v", secretName, err)
+		return "", fmt.Errorf("failed to access secret %s: %w", secretName, err)
 	}
 
 	return string(result.Payload.Data), nil
@@ -70,6 +56,40 @@ func (sm *SecretManager) GetSecretWithFallback(secretName, envVar string) string
 	return os.Getenv(envVar)
 }
 
+// AddSecretVersion stores data as a new version of an existing secret
+func (sm *SecretManager) AddSecretVersion(secretName string, data []byte) error {
+	req := &secretmanagerpb.AddSecretVersionRequest{
+		Parent: fmt.Sprintf("projects/%s/secrets/%s", projectID(), secretName),
+		Payload: &secretmanagerpb.SecretPayload{
+			Data: data,
+		},
+	}
+
+	if _, err := sm.client.AddSecretVersion(sm.ctx, req); err != nil {
+		return fmt.Errorf("failed to add version to secret %s: %v", secretName, err)
+	}
+
+	return nil
+}
+
+// projectID returns the Google Cloud project that holds the secrets
+func projectID() string {
+	projectID := os.Getenv("GOOGLE_CLOUD_PROJECT")
+	if projectID == "" {
+		// Try to get project ID from other environment variables
+		projectID = os.Getenv("GCP_PROJECT")
+		if projectID == "" {
+			projectID = os.Getenv("GCLOUD_PROJECT")
+			if projectID == "" {
+				// In Cloud Run, the project ID is available in the metadata
+				// For now, hardcode it since we know it
+				projectID = "portfolio-420-69"
+			}
+		}
+	}
+	return projectID
+}
+
 // Close closes the Secret Manager client
 func (sm *SecretManager) Close() error {
 	return sm.client.Close()
@@ -4,10 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
 	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // BaseDatabaseService implements the DatabaseService interface
@@ -45,17 +47,112 @@ func (d *BaseDatabaseService) CreateImage(ctx context.Context, image interface{}
 		_ = tx.Rollback() // Ignore rollback error in defer
 	}()
 
+	// Keep the provided creation time (e.g. when rebuilding from Drive)
+	var createdAt interface{}
+	if img.CreatedAt != nil {
+		createdAt = img.CreatedAt.AsTime().UTC()
+	}
+
+	var takenAt sql.NullTime
+	if img.TakenAt != nil {
+		takenAt = sql.NullTime{Time: img.TakenAt.AsTime().UTC(), Valid: true}
+	}
+
+	var publishAt, expireAt sql.NullTime
+	if img.PublishAt != nil {
+		publishAt = sql.NullTime{Time: img.PublishAt.AsTime().UTC(), Valid: true}
+	}
+	if img.ExpireAt != nil {
+		expireAt = sql.NullTime{Time: img.ExpireAt.AsTime().UTC(), Valid: true}
+	}
+
+	var dominantColor, palette, foreground sql.NullString
+	var averageLuminance sql.NullFloat64
+	if theme := img.ColorTheme; theme != nil {
+		dominantColor = nullString(theme.DominantColor)
+		palette = nullString(strings.Join(theme.Palette, ","))
+		averageLuminance = sql.NullFloat64{Float64: theme.AverageLuminance, Valid: true}
+		foreground = nullString(theme.Foreground)
+	}
+
 	// Insert image
 	query := `
-		INSERT INTO images (id, title, description, drive_file_id)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO images (
+			id, title, description, drive_file_id, content_hash, mime_type, created_at,
+			taken_at, camera_make, camera_model, lens_model, focal_length_mm, aperture, iso, exposure_time,
+			dominant_color, palette, average_luminance, foreground, blurhash, lqip,
+			focal_x, focal_y, original_file_id, perceptual_hash, publish_at, expire_at,
+			time_of_day, weight
+		)
+		VALUES (
+			$1, $2, $3, $4, $5, $6, COALESCE($7, CURRENT_TIMESTAMP),
+			$8, $9, $10, $11, $12, $13, $14, $15,
+			$16, $17, $18, $19, $20, $21,
+			$22, $23, $24, $25, $26, $27,
+			$28, $29
+		)
 		ON CONFLICT (id) DO UPDATE SET
 			title = EXCLUDED.title,
 			description = EXCLUDED.description,
 			drive_file_id = EXCLUDED.drive_file_id,
+			content_hash = EXCLUDED.content_hash,
+			mime_type = EXCLUDED.mime_type,
+			created_at = COALESCE($7, images.created_at),
+			taken_at = EXCLUDED.taken_at,
+			camera_make = EXCLUDED.camera_make,
+			camera_model = EXCLUDED.camera_model,
+			lens_model = EXCLUDED.lens_model,
+			focal_length_mm = EXCLUDED.focal_length_mm,
+			aperture = EXCLUDED.aperture,
+			iso = EXCLUDED.iso,
+			exposure_time = EXCLUDED.exposure_time,
+			dominant_color = EXCLUDED.dominant_color,
+			palette = EXCLUDED.palette,
+			average_luminance = EXCLUDED.average_luminance,
+			foreground = EXCLUDED.foreground,
+			blurhash = EXCLUDED.blurhash,
+			lqip = EXCLUDED.lqip,
+			focal_x = EXCLUDED.focal_x,
+			focal_y = EXCLUDED.focal_y,
+			original_file_id = EXCLUDED.original_file_id,
+			perceptual_hash = EXCLUDED.perceptual_hash,
+			publish_at = EXCLUDED.publish_at,
+			expire_at = EXCLUDED.expire_at,
+			time_of_day = EXCLUDED.time_of_day,
+			weight = EXCLUDED.weight,
 			updated_at = CURRENT_TIMESTAMP
 	`
-	_, err = tx.ExecContext(ctx, query, img.Id, img.Title, img.Description, img.DriveFileId)
+	_, err = tx.ExecContext(ctx, query,
+		img.Id,
+		img.Title,
+		img.Description,
+		img.DriveFileId,
+		nullString(img.ContentHash),
+		nullString(img.MimeType),
+		createdAt,
+		takenAt,
+		nullString(img.CameraMake),
+		nullString(img.CameraModel),
+		nullString(img.LensModel),
+		nullFloat64(img.FocalLengthMm),
+		nullFloat64(img.Aperture),
+		sql.NullInt32{Int32: img.Iso, Valid: img.Iso != 0},
+		nullFloat64(img.ExposureTime),
+		dominantColor,
+		palette,
+		averageLuminance,
+		foreground,
+		nullString(img.Blurhash),
+		nullString(img.Lqip),
+		img.FocalX,
+		img.FocalY,
+		nullString(img.OriginalFileId),
+		nullString(img.PerceptualHash),
+		publishAt,
+		expireAt,
+		nullString(timeOfDayNames[img.TimeOfDay]),
+		nullFloat64(img.Weight),
+	)
 	if err != nil {
 		return fmt.Errorf("failed to insert image: %v", err)
 	}
@@ -90,34 +187,149 @@ func (d *BaseDatabaseService) CreateImage(ctx context.Context, image interface{}
 	return tx.Commit()
 }
 
-// GetImage retrieves an image by ID
-func (d *BaseDatabaseService) GetImage(ctx context.Context, imageID string) (interface{}, error) {
-	query := `
-		SELECT i.id, i.title, i.description, i.drive_file_id, i.created_at,
-		       l.latitude, l.longitude, l.name, l.country, l.city, l.address
-		FROM images i
-		LEFT JOIN locations l ON i.id = l.image_id
-		WHERE i.id = $1
-	`
+// imageColumns selects an image with its location; scan the rows with scanImage
+const imageColumns = `
+		i.id, i.title, i.description, i.drive_file_id, i.content_hash, i.mime_type, i.created_at,
+		i.taken_at, i.camera_make, i.camera_model, i.lens_model, i.focal_length_mm, i.aperture, i.iso, i.exposure_time,
+		i.dominant_color, i.palette, i.average_luminance, i.foreground, i.blurhash, i.lqip,
+		i.focal_x, i.focal_y, i.original_file_id, i.perceptual_hash, i.publish_at, i.expire_at,
+		i.time_of_day, i.weight,
+		l.latitude, l.longitude, l.name, l.country, l.city, l.address
+`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
+// scanImage scans a row selected with imageColumns
+func scanImage(row rowScanner) (*pb.ImageMetadata, error) {
 	var image pb.ImageMetadata
-	var location pb.Location
+	var contentHash, mimeType sql.NullString
 	var createdAt time.Time
-
-	err := d.db.QueryRowContext(ctx, query, imageID).Scan(
+	var takenAt sql.NullTime
+	var cameraMake, cameraModel, lensModel sql.NullString
+	var focalLength, aperture, exposureTime sql.NullFloat64
+	var iso sql.NullInt32
+	var dominantColor, palette, foreground, blurhash, lqip sql.NullString
+	var averageLuminance sql.NullFloat64
+	var focalX, focalY sql.NullFloat64
+	var originalFileID, perceptualHash sql.NullString
+	var publishAt, expireAt sql.NullTime
+	var timeOfDay sql.NullString
+	var weight sql.NullFloat64
+	var latitude, longitude sql.NullFloat64
+	var name, country, city, address sql.NullString
+
+	err := row.Scan(
 		&image.Id,
 		&image.Title,
 		&image.Description,
 		&image.DriveFileId,
+		&contentHash,
+		&mimeType,
 		&createdAt,
-		&location.Latitude,
-		&location.Longitude,
-		&location.Name,
-		&location.Country,
-		&location.City,
-		&location.Address,
+		&takenAt,
+		&cameraMake,
+		&cameraModel,
+		&lensModel,
+		&focalLength,
+		&aperture,
+		&iso,
+		&exposureTime,
+		&dominantColor,
+		&palette,
+		&averageLuminance,
+		&foreground,
+		&blurhash,
+		&lqip,
+		&focalX,
+		&focalY,
+		&originalFileID,
+		&perceptualHash,
+		&publishAt,
+		&expireAt,
+		&timeOfDay,
+		&weight,
+		&latitude,
+		&longitude,
+		&name,
+		&country,
+		&city,
+		&address,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	image.ContentHash = contentHash.String
+	image.MimeType = mimeType.String
+	image.CreatedAt = timestamppb.New(createdAt)
+	if takenAt.Valid {
+		image.TakenAt = timestamppb.New(takenAt.Time)
+	}
+	image.CameraMake = cameraMake.String
+	image.CameraModel = cameraModel.String
+	image.LensModel = lensModel.String
+	image.FocalLengthMm = focalLength.Float64
+	image.Aperture = aperture.Float64
+	image.Iso = iso.Int32
+	image.ExposureTime = exposureTime.Float64
+	image.Blurhash = blurhash.String
+	image.Lqip = lqip.String
+	image.OriginalFileId = originalFileID.String
+	image.PerceptualHash = perceptualHash.String
+	if publishAt.Valid {
+		image.PublishAt = timestamppb.New(publishAt.Time)
+	}
+	if expireAt.Valid {
+		image.ExpireAt = timestamppb.New(expireAt.Time)
+	}
+	image.TimeOfDay = timeOfDayTags[timeOfDay.String]
+	image.Weight = 1
+	if weight.Valid {
+		image.Weight = weight.Float64
+	}
+	if focalX.Valid && focalY.Valid {
+		image.FocalX = &focalX.Float64
+		image.FocalY = &focalY.Float64
+	}
+	if dominantColor.Valid {
+		image.ColorTheme = &pb.ColorTheme{
+			DominantColor:    dominantColor.String,
+			AverageLuminance: averageLuminance.Float64,
+			Foreground:       foreground.String,
+		}
+		if palette.String != "" {
+			image.ColorTheme.Palette = strings.Split(palette.String, ",")
+		}
+	}
+
+	// Only set location if it has data
+	if latitude.Float64 != 0 || longitude.Float64 != 0 || name.String != "" {
+		image.Location = &pb.Location{
+			Latitude:  latitude.Float64,
+			Longitude: longitude.Float64,
+			Name:      name.String,
+			Country:   country.String,
+			City:      city.String,
+			Address:   address.String,
+		}
+	}
+
+	return &image, nil
+}
 
+// GetImage retrieves an image by ID
+func (d *BaseDatabaseService) GetImage(ctx context.Context, imageID string) (interface{}, error) {
+	query := `
+		SELECT ` + imageColumns + `
+		FROM images i
+		LEFT JOIN locations l ON i.id = l.image_id
+		WHERE i.id = $1
+	`
+
+	image, err := scanImage(d.db.QueryRowContext(ctx, query, imageID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("image not found")
@@ -125,19 +337,35 @@ func (d *BaseDatabaseService) GetImage(ctx context.Context, imageID string) (int
 		return nil, fmt.Errorf("failed to get image: %v", err)
 	}
 
-	// Only set location if it has data
-	if location.Latitude != 0 || location.Longitude != 0 || location.Name != "" {
-		image.Location = &location
+	return image, nil
+}
+
+// GetImageByContentHash retrieves the oldest image with the given SHA-256 content hash
+func (d *BaseDatabaseService) GetImageByContentHash(ctx context.Context, contentHash string) (interface{}, error) {
+	query := `
+		SELECT ` + imageColumns + `
+		FROM images i
+		LEFT JOIN locations l ON i.id = l.image_id
+		WHERE i.content_hash = $1
+		ORDER BY i.created_at ASC
+		LIMIT 1
+	`
+
+	image, err := scanImage(d.db.QueryRowContext(ctx, query, contentHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("image not found")
+		}
+		return nil, fmt.Errorf("failed to get image: %v", err)
 	}
 
-	return &image, nil
+	return image, nil
 }
 
 // ListImages retrieves all images
 func (d *BaseDatabaseService) ListImages(ctx context.Context) ([]interface{}, error) {
 	query := `
-		SELECT i.id, i.title, i.description, i.drive_file_id, i.created_at,
-		       l.latitude, l.longitude, l.name, l.country, l.city, l.address
+		SELECT ` + imageColumns + `
 		FROM images i
 		LEFT JOIN locations l ON i.id = l.image_id
 		ORDER BY i.created_at DESC
@@ -151,33 +379,11 @@ func (d *BaseDatabaseService) ListImages(ctx context.Context) ([]interface{}, er
 
 	var images []interface{}
 	for rows.Next() {
-		var image pb.ImageMetadata
-		var location pb.Location
-		var createdAt time.Time
-
-		err := rows.Scan(
-			&image.Id,
-			&image.Title,
-			&image.Description,
-			&image.DriveFileId,
-			&createdAt,
-			&location.Latitude,
-			&location.Longitude,
-			&location.Name,
-			&location.Country,
-			&location.City,
-			&location.Address,
-		)
+		image, err := scanImage(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan image: %v", err)
 		}
-
-		// Only set location if it has data
-		if location.Latitude != 0 || location.Longitude != 0 || location.Name != "" {
-			image.Location = &location
-		}
-
-		images = append(images, &image)
+		images = append(images, image)
 	}
 
 	return images, nil
@@ -196,6 +402,36 @@ func (d *BaseDatabaseService) GetImageCount(ctx context.Context) (int32, error)
 	return count, nil
 }
 
+// visibleCondition matches images inside their publish window; it takes the
+// same time twice, as two consecutive parameters
+const visibleCondition = `(i.publish_at IS NULL OR i.publish_at <= $%d) AND (i.expire_at IS NULL OR i.expire_at > $%d)`
+
+// CountImagesByVisibility counts images in each Visibility state at a time.
+// An image that is not yet published counts as scheduled even if its expiry
+// has also passed.
+func (d *BaseDatabaseService) CountImagesByVisibility(ctx context.Context, at time.Time) (map[string]int32, error) {
+	query := `
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(CASE WHEN publish_at > $1 THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN (publish_at IS NULL OR publish_at <= $2) AND expire_at <= $3 THEN 1 ELSE 0 END), 0)
+		FROM images
+	`
+	at = at.UTC()
+	var total, scheduled, expired int32
+
+	err := d.db.QueryRowContext(ctx, query, at, at, at).Scan(&total, &scheduled, &expired)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count images by visibility: %v", err)
+	}
+
+	return map[string]int32{
+		interfaces.VisibilityVisible:   total - scheduled - expired,
+		interfaces.VisibilityScheduled: scheduled,
+		interfaces.VisibilityExpired:   expired,
+	}, nil
+}
+
 // DeleteImage deletes an image and its location data
 func (d *BaseDatabaseService) DeleteImage(ctx context.Context, imageID string) error {
 	query := "DELETE FROM images WHERE id = $1"
@@ -216,35 +452,19 @@ func (d *BaseDatabaseService) DeleteImage(ctx context.Context, imageID string) e
 	return nil
 }
 
-// GetCurrentImage returns the most recently created image
-func (d *BaseDatabaseService) GetCurrentImage(ctx context.Context) (interface{}, error) {
+// GetCurrentImage returns the most recently created image visible at a time
+func (d *BaseDatabaseService) GetCurrentImage(ctx context.Context, visibleAt time.Time) (interface{}, error) {
 	query := `
-		SELECT i.id, i.title, i.description, i.drive_file_id, i.created_at,
-		       l.latitude, l.longitude, l.name, l.country, l.city, l.address
+		SELECT ` + imageColumns + `
 		FROM images i
 		LEFT JOIN locations l ON i.id = l.image_id
+		WHERE ` + fmt.Sprintf(visibleCondition, 1, 2) + `
 		ORDER BY i.created_at DESC
 		LIMIT 1
 	`
 
-	var image pb.ImageMetadata
-	var location pb.Location
-	var createdAt time.Time
-
-	err := d.db.QueryRowContext(ctx, query).Scan(
-		&image.Id,
-		&image.Title,
-		&image.Description,
-		&image.DriveFileId,
-		&createdAt,
-		&location.Latitude,
-		&location.Longitude,
-		&location.Name,
-		&location.Country,
-		&location.City,
-		&location.Address,
-	)
-
+	visibleAt = visibleAt.UTC()
+	image, err := scanImage(d.db.QueryRowContext(ctx, query, visibleAt, visibleAt))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("no images found")
@@ -252,12 +472,31 @@ func (d *BaseDatabaseService) GetCurrentImage(ctx context.Context) (interface{},
 		return nil, fmt.Errorf("failed to get current image: %v", err)
 	}
 
-	// Only set location if it has data
-	if location.Latitude != 0 || location.Longitude != 0 || location.Name != "" {
-		image.Location = &location
+	return image, nil
+}
+
+// GetImageAtPosition returns the image at a zero-based position in upload
+// order, oldest first, among the images visible at a time
+func (d *BaseDatabaseService) GetImageAtPosition(ctx context.Context, position int, visibleAt time.Time) (interface{}, error) {
+	query := `
+		SELECT ` + imageColumns + `
+		FROM images i
+		LEFT JOIN locations l ON i.id = l.image_id
+		WHERE ` + fmt.Sprintf(visibleCondition, 1, 2) + `
+		ORDER BY i.created_at ASC, i.id ASC
+		LIMIT 1 OFFSET $3
+	`
+
+	visibleAt = visibleAt.UTC()
+	image, err := scanImage(d.db.QueryRowContext(ctx, query, visibleAt, visibleAt, position))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("no image at position %d", position)
+		}
+		return nil, fmt.Errorf("failed to get image at position %d: %v", position, err)
 	}
 
-	return &image, nil
+	return image, nil
 }
 
 // CreateLocation creates a location record
@@ -367,3 +606,29 @@ func (d *BaseDatabaseService) DeleteLocation(ctx context.Context, imageID string
 	_, err := d.db.ExecContext(ctx, query, imageID)
 	return err
 }
+
+// nullString stores empty strings as NULL
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// timeOfDayNames maps time of day tags to the values stored in images.time_of_day
+var timeOfDayNames = map[pb.TimeOfDay]string{
+	pb.TimeOfDay_TIME_OF_DAY_DAWN:  "dawn",
+	pb.TimeOfDay_TIME_OF_DAY_DAY:   "day",
+	pb.TimeOfDay_TIME_OF_DAY_DUSK:  "dusk",
+	pb.TimeOfDay_TIME_OF_DAY_NIGHT: "night",
+}
+
+// timeOfDayTags is the inverse of timeOfDayNames
+var timeOfDayTags = map[string]pb.TimeOfDay{
+	"dawn":  pb.TimeOfDay_TIME_OF_DAY_DAWN,
+	"day":   pb.TimeOfDay_TIME_OF_DAY_DAY,
+	"dusk":  pb.TimeOfDay_TIME_OF_DAY_DUSK,
+	"night": pb.TimeOfDay_TIME_OF_DAY_NIGHT,
+}
+
+// nullFloat64 stores zero as NULL
+func nullFloat64(value float64) sql.NullFloat64 {
+	return sql.NullFloat64{Float64: value, Valid: value != 0}
+}
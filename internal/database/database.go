@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
 )
@@ -47,6 +48,11 @@ func (d *LegacyDatabaseService) ListImages(ctx context.Context) ([]interface{},
 	return d.service.ListImages(ctx)
 }
 
+// QueryImages retrieves the images matching a query
+func (d *LegacyDatabaseService) QueryImages(ctx context.Context, query *interfaces.ImageQuery) ([]interface{}, error) {
+	return d.service.QueryImages(ctx, query)
+}
+
 // GetImageCount returns the total number of images
 func (d *LegacyDatabaseService) GetImageCount(ctx context.Context) (int32, error) {
 	return d.service.GetImageCount(ctx)
@@ -57,9 +63,49 @@ func (d *LegacyDatabaseService) DeleteImage(ctx context.Context, imageID string)
 	return d.service.DeleteImage(ctx, imageID)
 }
 
-// GetCurrentImage returns the most recently created image
-func (d *LegacyDatabaseService) GetCurrentImage(ctx context.Context) (interface{}, error) {
-	return d.service.GetCurrentImage(ctx)
+// CountImagesByVisibility counts images in each visibility state at a time
+func (d *LegacyDatabaseService) CountImagesByVisibility(ctx context.Context, at time.Time) (map[string]int32, error) {
+	return d.service.CountImagesByVisibility(ctx, at)
+}
+
+// GetCurrentImage returns the most recently created image visible at a time
+func (d *LegacyDatabaseService) GetCurrentImage(ctx context.Context, visibleAt time.Time) (interface{}, error) {
+	return d.service.GetCurrentImage(ctx, visibleAt)
+}
+
+// GetImageAtPosition returns the image at a position in upload order among visible images
+func (d *LegacyDatabaseService) GetImageAtPosition(ctx context.Context, position int, visibleAt time.Time) (interface{}, error) {
+	return d.service.GetImageAtPosition(ctx, position, visibleAt)
+}
+
+// GetShuffleBag returns a viewer's shuffle bag, or nil
+func (d *LegacyDatabaseService) GetShuffleBag(ctx context.Context, tokenHash string) (*interfaces.ShuffleBag, error) {
+	return d.service.GetShuffleBag(ctx, tokenHash)
+}
+
+// SaveShuffleBag creates or replaces a viewer's shuffle bag
+func (d *LegacyDatabaseService) SaveShuffleBag(ctx context.Context, bag *interfaces.ShuffleBag) error {
+	return d.service.SaveShuffleBag(ctx, bag)
+}
+
+// DeleteShuffleBagsBefore removes the shuffle bags not used since a time
+func (d *LegacyDatabaseService) DeleteShuffleBagsBefore(ctx context.Context, before time.Time) (int64, error) {
+	return d.service.DeleteShuffleBagsBefore(ctx, before)
+}
+
+// GetImageByContentHash retrieves an image by its SHA-256 content hash
+func (d *LegacyDatabaseService) GetImageByContentHash(ctx context.Context, contentHash string) (interface{}, error) {
+	return d.service.GetImageByContentHash(ctx, contentHash)
+}
+
+// CreateIdempotencyKey stores an idempotency key unless it already exists
+func (d *LegacyDatabaseService) CreateIdempotencyKey(ctx context.Context, key, imageID, contentHash string) (string, string, error) {
+	return d.service.CreateIdempotencyKey(ctx, key, imageID, contentHash)
+}
+
+// DeleteIdempotencyKey removes an idempotency key
+func (d *LegacyDatabaseService) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	return d.service.DeleteIdempotencyKey(ctx, key)
 }
 
 // CreateLocation creates a location record
@@ -82,6 +128,81 @@ func (d *LegacyDatabaseService) DeleteLocation(ctx context.Context, imageID stri
 	return d.service.DeleteLocation(ctx, imageID)
 }
 
+// CreateImageVariant stores an image variant
+func (d *LegacyDatabaseService) CreateImageVariant(ctx context.Context, variant *interfaces.ImageVariant) error {
+	return d.service.CreateImageVariant(ctx, variant)
+}
+
+// CreateImageVariantIfAbsent stores an image variant unless one with the same name exists
+func (d *LegacyDatabaseService) CreateImageVariantIfAbsent(ctx context.Context, variant *interfaces.ImageVariant) (bool, error) {
+	return d.service.CreateImageVariantIfAbsent(ctx, variant)
+}
+
+// ListImageVariants returns the variants of an image
+func (d *LegacyDatabaseService) ListImageVariants(ctx context.Context, imageID string) ([]*interfaces.ImageVariant, error) {
+	return d.service.ListImageVariants(ctx, imageID)
+}
+
+// DeleteImageVariants deletes all variants of an image
+func (d *LegacyDatabaseService) DeleteImageVariants(ctx context.Context, imageID string) error {
+	return d.service.DeleteImageVariants(ctx, imageID)
+}
+
+// DeleteImageVariant deletes a single variant of an image
+func (d *LegacyDatabaseService) DeleteImageVariant(ctx context.Context, imageID, name string) error {
+	return d.service.DeleteImageVariant(ctx, imageID, name)
+}
+
+// CreatePendingOperation records a pending storage operation
+func (d *LegacyDatabaseService) CreatePendingOperation(ctx context.Context, op *interfaces.PendingOperation) error {
+	return d.service.CreatePendingOperation(ctx, op)
+}
+
+// ListPendingOperations returns all pending storage operations
+func (d *LegacyDatabaseService) ListPendingOperations(ctx context.Context) ([]*interfaces.PendingOperation, error) {
+	return d.service.ListPendingOperations(ctx)
+}
+
+// UpdatePendingOperation records a failed attempt of a pending operation
+func (d *LegacyDatabaseService) UpdatePendingOperation(ctx context.Context, op *interfaces.PendingOperation) error {
+	return d.service.UpdatePendingOperation(ctx, op)
+}
+
+// DeletePendingOperation removes a completed pending operation
+func (d *LegacyDatabaseService) DeletePendingOperation(ctx context.Context, id int64) error {
+	return d.service.DeletePendingOperation(ctx, id)
+}
+
+// GetRotationSettings returns the stored rotation settings
+func (d *LegacyDatabaseService) GetRotationSettings(ctx context.Context) (*interfaces.RotationSettings, error) {
+	return d.service.GetRotationSettings(ctx)
+}
+
+// SaveRotationSettings replaces the stored rotation settings
+func (d *LegacyDatabaseService) SaveRotationSettings(ctx context.Context, settings *interfaces.RotationSettings) error {
+	return d.service.SaveRotationSettings(ctx, settings)
+}
+
+// CreateImagePin records a pin and sets its ID
+func (d *LegacyDatabaseService) CreateImagePin(ctx context.Context, pin *interfaces.ImagePin) error {
+	return d.service.CreateImagePin(ctx, pin)
+}
+
+// GetLatestImagePin returns the newest pin that was not unpinned
+func (d *LegacyDatabaseService) GetLatestImagePin(ctx context.Context) (*interfaces.ImagePin, error) {
+	return d.service.GetLatestImagePin(ctx)
+}
+
+// EndImagePin records that a pin was unpinned
+func (d *LegacyDatabaseService) EndImagePin(ctx context.Context, id int64, unpinnedAt time.Time) error {
+	return d.service.EndImagePin(ctx, id, unpinnedAt)
+}
+
+// ListImagePins returns the pin history, newest first
+func (d *LegacyDatabaseService) ListImagePins(ctx context.Context, limit int) ([]*interfaces.ImagePin, error) {
+	return d.service.ListImagePins(ctx, limit)
+}
+
 // NewDatabaseServiceLegacy creates a new database service (legacy function for backward compatibility)
 func NewDatabaseServiceLegacy(connectionString string) (*LegacyDatabaseService, error) {
 	return nil, fmt.Errorf("use NewLegacyDatabaseService or NewDatabaseServiceWithType instead")
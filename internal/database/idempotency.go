@@ -0,0 +1,37 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// CreateIdempotencyKey stores key for imageID unless the key already exists,
+// and returns the image ID and content hash stored for the key
+func (d *BaseDatabaseService) CreateIdempotencyKey(ctx context.Context, key, imageID, contentHash string) (string, string, error) {
+	insertQuery := `
+		INSERT INTO idempotency_keys (key, image_id, content_hash)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO NOTHING
+	`
+	if _, err := d.db.ExecContext(ctx, insertQuery, key, imageID, contentHash); err != nil {
+		return "", "", fmt.Errorf("failed to create idempotency key: %v", err)
+	}
+
+	selectQuery := "SELECT image_id, content_hash FROM idempotency_keys WHERE key = $1"
+	var storedImageID, storedContentHash string
+	if err := d.db.QueryRowContext(ctx, selectQuery, key).Scan(&storedImageID, &storedContentHash); err != nil {
+		return "", "", fmt.Errorf("failed to get idempotency key: %v", err)
+	}
+
+	return storedImageID, storedContentHash, nil
+}
+
+// DeleteIdempotencyKey removes an idempotency key so the upload can be retried
+func (d *BaseDatabaseService) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	query := "DELETE FROM idempotency_keys WHERE key = $1"
+	if _, err := d.db.ExecContext(ctx, query, key); err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+)
+
+// CreateImagePin records a pin and sets its ID
+func (d *BaseDatabaseService) CreateImagePin(ctx context.Context, pin *interfaces.ImagePin) error {
+	query := `
+		INSERT INTO image_pins (image_id, pinned_at, until)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`
+	var until sql.NullTime
+	if pin.Until != nil {
+		until = sql.NullTime{Time: pin.Until.UTC(), Valid: true}
+	}
+
+	err := d.db.QueryRowContext(ctx, query, pin.ImageID, pin.PinnedAt.UTC(), until).Scan(&pin.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create image pin: %v", err)
+	}
+
+	return nil
+}
+
+// GetLatestImagePin returns the newest pin that was not unpinned, or nil.
+// Whether it has expired is left to the caller.
+func (d *BaseDatabaseService) GetLatestImagePin(ctx context.Context) (*interfaces.ImagePin, error) {
+	query := `
+		SELECT id, image_id, pinned_at, until, unpinned_at
+		FROM image_pins
+		WHERE unpinned_at IS NULL
+		ORDER BY pinned_at DESC, id DESC
+		LIMIT 1
+	`
+
+	pin, err := scanImagePin(d.db.QueryRowContext(ctx, query))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get image pin: %v", err)
+	}
+
+	return pin, nil
+}
+
+// EndImagePin records that a pin was unpinned
+func (d *BaseDatabaseService) EndImagePin(ctx context.Context, id int64, unpinnedAt time.Time) error {
+	query := "UPDATE image_pins SET unpinned_at = $1 WHERE id = $2"
+	if _, err := d.db.ExecContext(ctx, query, unpinnedAt.UTC(), id); err != nil {
+		return fmt.Errorf("failed to end image pin: %v", err)
+	}
+
+	return nil
+}
+
+// ListImagePins returns up to limit pins, newest first
+func (d *BaseDatabaseService) ListImagePins(ctx context.Context, limit int) ([]*interfaces.ImagePin, error) {
+	query := `
+		SELECT id, image_id, pinned_at, until, unpinned_at
+		FROM image_pins
+		ORDER BY pinned_at DESC, id DESC
+		LIMIT $1
+	`
+
+	rows, err := d.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list image pins: %v", err)
+	}
+	defer rows.Close()
+
+	pins := []*interfaces.ImagePin{}
+	for rows.Next() {
+		pin, err := scanImagePin(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan image pin: %v", err)
+		}
+		pins = append(pins, pin)
+	}
+
+	return pins, rows.Err()
+}
+
+// scanImagePin scans a row of id, image_id, pinned_at, until and unpinned_at
+func scanImagePin(row rowScanner) (*interfaces.ImagePin, error) {
+	var pin interfaces.ImagePin
+	var until, unpinnedAt sql.NullTime
+	if err := row.Scan(&pin.ID, &pin.ImageID, &pin.PinnedAt, &until, &unpinnedAt); err != nil {
+		return nil, err
+	}
+
+	if until.Valid {
+		pin.Until = &until.Time
+	}
+	if unpinnedAt.Valid {
+		pin.UnpinnedAt = &unpinnedAt.Time
+	}
+	return &pin, nil
+}
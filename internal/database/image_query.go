@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+)
+
+// imageSortColumns maps ImageQuery sort keys to ORDER BY expressions
+var imageSortColumns = map[string][]string{
+	interfaces.SortByCreatedAt:    {"i.created_at"},
+	interfaces.SortByTakenAt:      {"i.taken_at"},
+	interfaces.SortByCamera:       {"LOWER(i.camera_make)", "LOWER(i.camera_model)"},
+	interfaces.SortByFocalLength:  {"i.focal_length_mm"},
+	interfaces.SortByAperture:     {"i.aperture"},
+	interfaces.SortByISO:          {"i.iso"},
+	interfaces.SortByExposureTime: {"i.exposure_time"},
+}
+
+// QueryImages retrieves the images matching a query. Images without a value
+// for the sort column are listed last.
+func (d *BaseDatabaseService) QueryImages(ctx context.Context, query *interfaces.ImageQuery) ([]interface{}, error) {
+	if query == nil {
+		query = &interfaces.ImageQuery{}
+	}
+
+	sortBy := query.SortBy
+	if sortBy == "" {
+		sortBy = interfaces.SortByCreatedAt
+	}
+	sortColumns, ok := imageSortColumns[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort key: %s", query.SortBy)
+	}
+	direction := "DESC"
+	if query.Ascending {
+		direction = "ASC"
+	}
+
+	var conditions []string
+	var args []interface{}
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if query.TakenAfter != nil {
+		addCondition("i.taken_at >= $%d", query.TakenAfter.UTC())
+	}
+	if query.TakenBefore != nil {
+		addCondition("i.taken_at < $%d", query.TakenBefore.UTC())
+	}
+	if query.CameraMake != "" {
+		addCondition("LOWER(i.camera_make) = LOWER($%d)", query.CameraMake)
+	}
+	if query.CameraModel != "" {
+		addCondition("LOWER(i.camera_model) = LOWER($%d)", query.CameraModel)
+	}
+	if query.LensModel != "" {
+		addCondition("LOWER(i.lens_model) = LOWER($%d)", query.LensModel)
+	}
+	if query.MinISO != nil {
+		addCondition("i.iso >= $%d", *query.MinISO)
+	}
+	if query.MaxISO != nil {
+		addCondition("i.iso <= $%d", *query.MaxISO)
+	}
+	if query.MinFocalLengthMM != nil {
+		addCondition("i.focal_length_mm >= $%d", *query.MinFocalLengthMM)
+	}
+	if query.MaxFocalLengthMM != nil {
+		addCondition("i.focal_length_mm <= $%d", *query.MaxFocalLengthMM)
+	}
+	if query.TimeOfDay != "" {
+		addCondition("i.time_of_day = $%d", query.TimeOfDay)
+	}
+	if query.VisibleAt != nil {
+		visibleAt := query.VisibleAt.UTC()
+		args = append(args, visibleAt, visibleAt)
+		conditions = append(conditions, fmt.Sprintf(visibleCondition, len(args)-1, len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var order []string
+	for _, column := range sortColumns {
+		order = append(order, column+" "+direction+" NULLS LAST")
+	}
+	if sortBy != interfaces.SortByCreatedAt {
+		order = append(order, "i.created_at DESC")
+	}
+
+	sqlQuery := `
+		SELECT ` + imageColumns + `
+		FROM images i
+		LEFT JOIN locations l ON i.id = l.image_id
+		` + where + `
+		ORDER BY ` + strings.Join(order, ", ")
+
+	rows, err := d.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query images: %v", err)
+	}
+	defer rows.Close()
+
+	var images []interface{}
+	for rows.Next() {
+		image, err := scanImage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan image: %v", err)
+		}
+		images = append(images, image)
+	}
+
+	return images, nil
+}
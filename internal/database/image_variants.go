@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+)
+
+// CreateImageVariant stores an image variant, replacing one with the same name
+func (d *BaseDatabaseService) CreateImageVariant(ctx context.Context, variant *interfaces.ImageVariant) error {
+	query := `
+		INSERT INTO image_variants (image_id, name, width, height, storage_file_id, mime_type, size_bytes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (image_id, name) DO UPDATE SET
+			width = EXCLUDED.width,
+			height = EXCLUDED.height,
+			storage_file_id = EXCLUDED.storage_file_id,
+			mime_type = EXCLUDED.mime_type,
+			size_bytes = EXCLUDED.size_bytes,
+			created_at = CURRENT_TIMESTAMP
+	`
+	_, err := d.db.ExecContext(ctx, query,
+		variant.ImageID,
+		variant.Name,
+		variant.Width,
+		variant.Height,
+		variant.StorageFileID,
+		variant.MimeType,
+		variant.SizeBytes,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create image variant: %v", err)
+	}
+
+	return nil
+}
+
+// CreateImageVariantIfAbsent stores an image variant unless one with the same
+// name exists, and reports whether it was stored
+func (d *BaseDatabaseService) CreateImageVariantIfAbsent(ctx context.Context, variant *interfaces.ImageVariant) (bool, error) {
+	query := `
+		INSERT INTO image_variants (image_id, name, width, height, storage_file_id, mime_type, size_bytes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (image_id, name) DO NOTHING
+	`
+	result, err := d.db.ExecContext(ctx, query,
+		variant.ImageID,
+		variant.Name,
+		variant.Width,
+		variant.Height,
+		variant.StorageFileID,
+		variant.MimeType,
+		variant.SizeBytes,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to create image variant: %v", err)
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to create image variant: %v", err)
+	}
+
+	return inserted > 0, nil
+}
+
+// ListImageVariants returns the variants of an image, narrowest first
+func (d *BaseDatabaseService) ListImageVariants(ctx context.Context, imageID string) ([]*interfaces.ImageVariant, error) {
+	query := `
+		SELECT image_id, name, width, height, storage_file_id, mime_type, size_bytes, created_at
+		FROM image_variants
+		WHERE image_id = $1
+		ORDER BY width ASC
+	`
+
+	rows, err := d.db.QueryContext(ctx, query, imageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list image variants: %v", err)
+	}
+	defer rows.Close()
+
+	var variants []*interfaces.ImageVariant
+	for rows.Next() {
+		var variant interfaces.ImageVariant
+		err := rows.Scan(
+			&variant.ImageID,
+			&variant.Name,
+			&variant.Width,
+			&variant.Height,
+			&variant.StorageFileID,
+			&variant.MimeType,
+			&variant.SizeBytes,
+			&variant.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan image variant: %v", err)
+		}
+		variants = append(variants, &variant)
+	}
+
+	return variants, rows.Err()
+}
+
+// DeleteImageVariants deletes all variants of an image
+func (d *BaseDatabaseService) DeleteImageVariants(ctx context.Context, imageID string) error {
+	query := "DELETE FROM image_variants WHERE image_id = $1"
+	if _, err := d.db.ExecContext(ctx, query, imageID); err != nil {
+		return fmt.Errorf("failed to delete image variants: %v", err)
+	}
+
+	return nil
+}
+
+// DeleteImageVariant deletes a single variant of an image
+func (d *BaseDatabaseService) DeleteImageVariant(ctx context.Context, imageID, name string) error {
+	query := "DELETE FROM image_variants WHERE image_id = $1 AND name = $2"
+	if _, err := d.db.ExecContext(ctx, query, imageID, name); err != nil {
+		return fmt.Errorf("failed to delete image variant: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+)
+
+// CreatePendingOperation records a pending storage operation and sets its ID
+func (d *BaseDatabaseService) CreatePendingOperation(ctx context.Context, op *interfaces.PendingOperation) error {
+	query := `
+		INSERT INTO pending_operations (operation, image_id, file_id, attempts, last_error, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	err := d.db.QueryRowContext(ctx, query,
+		op.Operation,
+		op.ImageID,
+		op.FileID,
+		op.Attempts,
+		op.LastError,
+		op.NextAttemptAt.UTC(),
+	).Scan(&op.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create pending operation: %v", err)
+	}
+
+	return nil
+}
+
+// ListPendingOperations returns all pending storage operations, oldest attempt first
+func (d *BaseDatabaseService) ListPendingOperations(ctx context.Context) ([]*interfaces.PendingOperation, error) {
+	query := `
+		SELECT id, operation, image_id, file_id, attempts, last_error, next_attempt_at, created_at
+		FROM pending_operations
+		ORDER BY next_attempt_at ASC
+	`
+
+	rows, err := d.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending operations: %v", err)
+	}
+	defer rows.Close()
+
+	var operations []*interfaces.PendingOperation
+	for rows.Next() {
+		var op interfaces.PendingOperation
+		err := rows.Scan(
+			&op.ID,
+			&op.Operation,
+			&op.ImageID,
+			&op.FileID,
+			&op.Attempts,
+			&op.LastError,
+			&op.NextAttemptAt,
+			&op.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pending operation: %v", err)
+		}
+		operations = append(operations, &op)
+	}
+
+	return operations, rows.Err()
+}
+
+// UpdatePendingOperation stores the attempt count, last error and next attempt time
+func (d *BaseDatabaseService) UpdatePendingOperation(ctx context.Context, op *interfaces.PendingOperation) error {
+	query := `
+		UPDATE pending_operations SET
+			attempts = $2,
+			last_error = $3,
+			next_attempt_at = $4
+		WHERE id = $1
+	`
+	_, err := d.db.ExecContext(ctx, query, op.ID, op.Attempts, op.LastError, op.NextAttemptAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to update pending operation: %v", err)
+	}
+
+	return nil
+}
+
+// DeletePendingOperation removes a completed pending operation
+func (d *BaseDatabaseService) DeletePendingOperation(ctx context.Context, id int64) error {
+	query := "DELETE FROM pending_operations WHERE id = $1"
+	if _, err := d.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete pending operation: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+)
+
+// rotationSettingsID is the key of the single rotation_settings row
+const rotationSettingsID = 1
+
+// GetRotationSettings returns the stored rotation settings, or nil when none were saved
+func (d *BaseDatabaseService) GetRotationSettings(ctx context.Context) (*interfaces.RotationSettings, error) {
+	query := `
+		SELECT strategy, interval_minutes, timezone, seed, updated_at
+		FROM rotation_settings
+		WHERE id = $1
+	`
+
+	var settings interfaces.RotationSettings
+	err := d.db.QueryRowContext(ctx, query, rotationSettingsID).Scan(
+		&settings.Strategy,
+		&settings.IntervalMinutes,
+		&settings.Timezone,
+		&settings.Seed,
+		&settings.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get rotation settings: %v", err)
+	}
+
+	return &settings, nil
+}
+
+// SaveRotationSettings replaces the stored rotation settings
+func (d *BaseDatabaseService) SaveRotationSettings(ctx context.Context, settings *interfaces.RotationSettings) error {
+	query := `
+		INSERT INTO rotation_settings (id, strategy, interval_minutes, timezone, seed, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO UPDATE SET
+			strategy = EXCLUDED.strategy,
+			interval_minutes = EXCLUDED.interval_minutes,
+			timezone = EXCLUDED.timezone,
+			seed = EXCLUDED.seed,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := d.db.ExecContext(ctx, query,
+		rotationSettingsID,
+		settings.Strategy,
+		settings.IntervalMinutes,
+		settings.Timezone,
+		settings.Seed,
+		settings.UpdatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save rotation settings: %v", err)
+	}
+
+	return nil
+}
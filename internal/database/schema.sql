@@ -21,11 +21,127 @@ CREATE TABLE IF NOT EXISTS locations (
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Add SHA-256 content hashes to existing images tables
+ALTER TABLE images ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64);
+
+-- Add the detected MIME type of the stored file
+ALTER TABLE images ADD COLUMN IF NOT EXISTS mime_type VARCHAR(50);
+
+-- Add capture metadata read from EXIF
+ALTER TABLE images ADD COLUMN IF NOT EXISTS taken_at TIMESTAMP;
+ALTER TABLE images ADD COLUMN IF NOT EXISTS camera_make VARCHAR(255);
+ALTER TABLE images ADD COLUMN IF NOT EXISTS camera_model VARCHAR(255);
+ALTER TABLE images ADD COLUMN IF NOT EXISTS lens_model VARCHAR(255);
+ALTER TABLE images ADD COLUMN IF NOT EXISTS focal_length_mm DOUBLE PRECISION;
+ALTER TABLE images ADD COLUMN IF NOT EXISTS aperture DOUBLE PRECISION;
+ALTER TABLE images ADD COLUMN IF NOT EXISTS iso INTEGER;
+ALTER TABLE images ADD COLUMN IF NOT EXISTS exposure_time DOUBLE PRECISION;
+
+-- Add color analysis used for theming
+ALTER TABLE images ADD COLUMN IF NOT EXISTS dominant_color VARCHAR(7);
+ALTER TABLE images ADD COLUMN IF NOT EXISTS palette TEXT;
+ALTER TABLE images ADD COLUMN IF NOT EXISTS average_luminance DOUBLE PRECISION;
+ALTER TABLE images ADD COLUMN IF NOT EXISTS foreground VARCHAR(5);
+
+-- Add placeholders painted while the full image loads
+ALTER TABLE images ADD COLUMN IF NOT EXISTS blurhash TEXT;
+ALTER TABLE images ADD COLUMN IF NOT EXISTS lqip TEXT;
+
+-- Add the focal point kept in view by crop variants
+ALTER TABLE images ADD COLUMN IF NOT EXISTS focal_x DOUBLE PRECISION;
+ALTER TABLE images ADD COLUMN IF NOT EXISTS focal_y DOUBLE PRECISION;
+
+-- Add the private unsanitized upload kept alongside the public copy
+ALTER TABLE images ADD COLUMN IF NOT EXISTS original_file_id VARCHAR(255);
+
+-- Add the perceptual hash used to find near-duplicate images
+ALTER TABLE images ADD COLUMN IF NOT EXISTS perceptual_hash VARCHAR(16);
+
+-- Add the publish window; images are hidden before publish_at and from expire_at on
+ALTER TABLE images ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP;
+ALTER TABLE images ADD COLUMN IF NOT EXISTS expire_at TIMESTAMP;
+
+-- Add the part of the day an image shows: dawn, day, dusk or night
+ALTER TABLE images ADD COLUMN IF NOT EXISTS time_of_day VARCHAR(8);
+
+-- Add the relative chance of being drawn by the weighted_random rotation; NULL counts as 1
+ALTER TABLE images ADD COLUMN IF NOT EXISTS weight DOUBLE PRECISION;
+
+-- Create table for resized image variants
+CREATE TABLE IF NOT EXISTS image_variants (
+    image_id VARCHAR(255) NOT NULL REFERENCES images(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    storage_file_id VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (image_id, name)
+);
+
+-- Create table for upload idempotency keys
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key VARCHAR(255) PRIMARY KEY,
+    image_id VARCHAR(255) NOT NULL,
+    content_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create table for storage operations that still need to be completed or compensated
+CREATE TABLE IF NOT EXISTS pending_operations (
+    id SERIAL PRIMARY KEY,
+    operation VARCHAR(50) NOT NULL,
+    image_id VARCHAR(255) NOT NULL,
+    file_id VARCHAR(255) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create table for the rotation of the current image (a single row)
+CREATE TABLE IF NOT EXISTS rotation_settings (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    strategy VARCHAR(50) NOT NULL,
+    interval_minutes INTEGER NOT NULL DEFAULT 0,
+    timezone VARCHAR(255) NOT NULL DEFAULT 'UTC',
+    seed BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- Add the seed of the weighted_random and shuffle strategies
+ALTER TABLE rotation_settings ADD COLUMN IF NOT EXISTS seed BIGINT NOT NULL DEFAULT 0;
+
+-- Create table for the history of images pinned as the current image
+CREATE TABLE IF NOT EXISTS image_pins (
+    id SERIAL PRIMARY KEY,
+    image_id VARCHAR(255) NOT NULL,
+    pinned_at TIMESTAMP NOT NULL,
+    until TIMESTAMP,
+    unpinned_at TIMESTAMP
+);
+
+-- Create table for the per-viewer state of the shuffle rotation strategy
+CREATE TABLE IF NOT EXISTS shuffle_bags (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    round INTEGER NOT NULL,
+    image_ids TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    slot BIGINT NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
 -- Create indexes for better performance
 CREATE INDEX IF NOT EXISTS idx_images_drive_file_id ON images(drive_file_id);
 CREATE INDEX IF NOT EXISTS idx_images_created_at ON images(created_at);
+CREATE INDEX IF NOT EXISTS idx_images_content_hash ON images(content_hash);
+CREATE INDEX IF NOT EXISTS idx_images_taken_at ON images(taken_at);
 CREATE INDEX IF NOT EXISTS idx_locations_image_id ON locations(image_id);
 CREATE INDEX IF NOT EXISTS idx_locations_coordinates ON locations(latitude, longitude);
+CREATE INDEX IF NOT EXISTS idx_pending_operations_next_attempt_at ON pending_operations(next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_image_pins_pinned_at ON image_pins(pinned_at);
+CREATE INDEX IF NOT EXISTS idx_shuffle_bags_updated_at ON shuffle_bags(updated_at);
 
 -- Create a function to update the updated_at timestamp
 CREATE OR REPLACE FUNCTION update_updated_at_column()
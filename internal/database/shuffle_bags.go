@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+)
+
+// GetShuffleBag returns the shuffle bag stored for a token hash, or nil
+func (d *BaseDatabaseService) GetShuffleBag(ctx context.Context, tokenHash string) (*interfaces.ShuffleBag, error) {
+	query := `
+		SELECT token_hash, round, image_ids, position, slot, updated_at
+		FROM shuffle_bags
+		WHERE token_hash = $1
+	`
+
+	bag := &interfaces.ShuffleBag{}
+	var imageIDs string
+	err := d.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&bag.TokenHash,
+		&bag.Round,
+		&imageIDs,
+		&bag.Position,
+		&bag.Slot,
+		&bag.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get shuffle bag: %v", err)
+	}
+
+	if err := json.Unmarshal([]byte(imageIDs), &bag.ImageIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode shuffle bag: %v", err)
+	}
+
+	return bag, nil
+}
+
+// SaveShuffleBag creates or replaces the shuffle bag of a token hash
+func (d *BaseDatabaseService) SaveShuffleBag(ctx context.Context, bag *interfaces.ShuffleBag) error {
+	imageIDs, err := json.Marshal(bag.ImageIDs)
+	if err != nil {
+		return fmt.Errorf("failed to encode shuffle bag: %v", err)
+	}
+
+	query := `
+		INSERT INTO shuffle_bags (token_hash, round, image_ids, position, slot, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (token_hash) DO UPDATE SET
+			round = EXCLUDED.round,
+			image_ids = EXCLUDED.image_ids,
+			position = EXCLUDED.position,
+			slot = EXCLUDED.slot,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err = d.db.ExecContext(ctx, query,
+		bag.TokenHash,
+		bag.Round,
+		string(imageIDs),
+		bag.Position,
+		bag.Slot,
+		bag.UpdatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save shuffle bag: %v", err)
+	}
+
+	return nil
+}
+
+// DeleteShuffleBagsBefore removes the shuffle bags not used since before and
+// returns how many were removed
+func (d *BaseDatabaseService) DeleteShuffleBagsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := d.db.ExecContext(ctx, "DELETE FROM shuffle_bags WHERE updated_at < $1", before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete shuffle bags: %v", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count deleted shuffle bags: %v", err)
+	}
+
+	return deleted, nil
+}
@@ -49,6 +49,30 @@ func createSQLiteTables(db *sql.DB) error {
 			title TEXT,
 			description TEXT,
 			drive_file_id TEXT,
+			content_hash TEXT,
+			mime_type TEXT,
+			taken_at DATETIME,
+			camera_make TEXT,
+			camera_model TEXT,
+			lens_model TEXT,
+			focal_length_mm REAL,
+			aperture REAL,
+			iso INTEGER,
+			exposure_time REAL,
+			dominant_color TEXT,
+			palette TEXT,
+			average_luminance REAL,
+			foreground TEXT,
+			blurhash TEXT,
+			lqip TEXT,
+			focal_x REAL,
+			focal_y REAL,
+			original_file_id TEXT,
+			perceptual_hash TEXT,
+			publish_at DATETIME,
+			expire_at DATETIME,
+			time_of_day TEXT,
+			weight REAL,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		)
@@ -68,10 +92,90 @@ func createSQLiteTables(db *sql.DB) error {
 		)
 	`
 
+	// Create image variants table
+	imageVariantsTable := `
+		CREATE TABLE IF NOT EXISTS image_variants (
+			image_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			width INTEGER NOT NULL,
+			height INTEGER NOT NULL,
+			storage_file_id TEXT NOT NULL,
+			mime_type TEXT NOT NULL,
+			size_bytes INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (image_id, name),
+			FOREIGN KEY (image_id) REFERENCES images (id) ON DELETE CASCADE
+		)
+	`
+
+	// Create idempotency keys table
+	idempotencyKeysTable := `
+		CREATE TABLE IF NOT EXISTS idempotency_keys (
+			key TEXT PRIMARY KEY,
+			image_id TEXT NOT NULL,
+			content_hash TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+
+	// Create pending operations table
+	pendingOperationsTable := `
+		CREATE TABLE IF NOT EXISTS pending_operations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			operation TEXT NOT NULL,
+			image_id TEXT NOT NULL,
+			file_id TEXT NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			next_attempt_at DATETIME NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+
+	// Create rotation settings table (a single row)
+	rotationSettingsTable := `
+		CREATE TABLE IF NOT EXISTS rotation_settings (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			strategy TEXT NOT NULL,
+			interval_minutes INTEGER NOT NULL DEFAULT 0,
+			timezone TEXT NOT NULL DEFAULT 'UTC',
+			seed INTEGER NOT NULL DEFAULT 0,
+			updated_at DATETIME NOT NULL
+		)
+	`
+
+	// Create image pins table
+	imagePinsTable := `
+		CREATE TABLE IF NOT EXISTS image_pins (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			image_id TEXT NOT NULL,
+			pinned_at DATETIME NOT NULL,
+			until DATETIME,
+			unpinned_at DATETIME
+		)
+	`
+
+	// Create shuffle bags table, the per-viewer state of the shuffle strategy
+	shuffleBagsTable := `
+		CREATE TABLE IF NOT EXISTS shuffle_bags (
+			token_hash TEXT PRIMARY KEY,
+			round INTEGER NOT NULL,
+			image_ids TEXT NOT NULL,
+			position INTEGER NOT NULL,
+			slot INTEGER NOT NULL,
+			updated_at DATETIME NOT NULL
+		)
+	`
+
 	// Create indexes for better performance
 	indexes := []string{
 		"CREATE INDEX IF NOT EXISTS idx_images_created_at ON images(created_at)",
+		"CREATE INDEX IF NOT EXISTS idx_images_content_hash ON images(content_hash)",
+		"CREATE INDEX IF NOT EXISTS idx_images_taken_at ON images(taken_at)",
 		"CREATE INDEX IF NOT EXISTS idx_locations_image_id ON locations(image_id)",
+		"CREATE INDEX IF NOT EXISTS idx_pending_operations_next_attempt_at ON pending_operations(next_attempt_at)",
+		"CREATE INDEX IF NOT EXISTS idx_image_pins_pinned_at ON image_pins(pinned_at)",
+		"CREATE INDEX IF NOT EXISTS idx_shuffle_bags_updated_at ON shuffle_bags(updated_at)",
 	}
 
 	// Execute table creation
@@ -83,6 +187,65 @@ func createSQLiteTables(db *sql.DB) error {
 		return fmt.Errorf("failed to create locations table: %v", err)
 	}
 
+	// Add columns introduced after the images table was first created
+	for _, column := range []struct{ name, definition string }{
+		{"content_hash", "TEXT"},
+		{"mime_type", "TEXT"},
+		{"taken_at", "DATETIME"},
+		{"camera_make", "TEXT"},
+		{"camera_model", "TEXT"},
+		{"lens_model", "TEXT"},
+		{"focal_length_mm", "REAL"},
+		{"aperture", "REAL"},
+		{"iso", "INTEGER"},
+		{"exposure_time", "REAL"},
+		{"dominant_color", "TEXT"},
+		{"palette", "TEXT"},
+		{"average_luminance", "REAL"},
+		{"foreground", "TEXT"},
+		{"blurhash", "TEXT"},
+		{"lqip", "TEXT"},
+		{"focal_x", "REAL"},
+		{"focal_y", "REAL"},
+		{"original_file_id", "TEXT"},
+		{"perceptual_hash", "TEXT"},
+		{"publish_at", "DATETIME"},
+		{"expire_at", "DATETIME"},
+		{"time_of_day", "TEXT"},
+		{"weight", "REAL"},
+	} {
+		if err := addSQLiteColumn(db, "images", column.name, column.definition); err != nil {
+			return err
+		}
+	}
+
+	if _, err := db.Exec(imageVariantsTable); err != nil {
+		return fmt.Errorf("failed to create image_variants table: %v", err)
+	}
+
+	if _, err := db.Exec(idempotencyKeysTable); err != nil {
+		return fmt.Errorf("failed to create idempotency_keys table: %v", err)
+	}
+
+	if _, err := db.Exec(pendingOperationsTable); err != nil {
+		return fmt.Errorf("failed to create pending_operations table: %v", err)
+	}
+
+	if _, err := db.Exec(rotationSettingsTable); err != nil {
+		return fmt.Errorf("failed to create rotation_settings table: %v", err)
+	}
+	if err := addSQLiteColumn(db, "rotation_settings", "seed", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(imagePinsTable); err != nil {
+		return fmt.Errorf("failed to create image_pins table: %v", err)
+	}
+
+	if _, err := db.Exec(shuffleBagsTable); err != nil {
+		return fmt.Errorf("failed to create shuffle_bags table: %v", err)
+	}
+
 	// Execute index creation
 	for _, index := range indexes {
 		if _, err := db.Exec(index); err != nil {
@@ -92,3 +255,33 @@ func createSQLiteTables(db *sql.DB) error {
 
 	return nil
 }
+
+// addSQLiteColumn adds a column to an existing table unless it is already present
+func addSQLiteColumn(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s table: %v", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, primaryKey int
+		var name, columnType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &primaryKey); err != nil {
+			return fmt.Errorf("failed to inspect %s table: %v", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to inspect %s table: %v", table, err)
+	}
+	rows.Close()
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add %s.%s column: %v", table, column, err)
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/middleware"
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/services"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+)
+
+// AdminHTTPHandler serves administrative endpoints protected by the admin API key
+type AdminHTTPHandler struct {
+	syncService  *services.SyncService
+	oauthFlow    *services.OAuthFlow
+	imageService *services.ImageService
+	adminConfig  *middleware.AdminConfig
+}
+
+// NewAdminHTTPHandler creates a new admin HTTP handler.
+// syncService and oauthFlow may be nil when the storage backend is not Google Drive;
+// oauthFlow is also nil when Drive uses a service account.
+func NewAdminHTTPHandler(syncService *services.SyncService, oauthFlow *services.OAuthFlow, imageService *services.ImageService, adminConfig *middleware.AdminConfig) *AdminHTTPHandler {
+	return &AdminHTTPHandler{
+		syncService:  syncService,
+		oauthFlow:    oauthFlow,
+		imageService: imageService,
+		adminConfig:  adminConfig,
+	}
+}
+
+// RegisterRoutes sets up all admin HTTP routes
+func (h *AdminHTTPHandler) RegisterRoutes(mux *http.ServeMux) {
+	adminAuth := middleware.AdminAuth(h.adminConfig)
+
+	// Drive reconciliation endpoints
+	mux.Handle("GET /api/v1/admin/sync", adminAuth(http.HandlerFunc(h.previewSync)))
+	mux.Handle("POST /api/v1/admin/sync", adminAuth(http.HandlerFunc(h.applySync)))
+
+	// Rotation of the current image
+	mux.Handle("GET /api/v1/admin/rotation", adminAuth(http.HandlerFunc(h.getRotation)))
+	mux.Handle("PUT /api/v1/admin/rotation", adminAuth(http.HandlerFunc(h.updateRotation)))
+
+	// Pins of the current image, which override the rotation
+	mux.Handle("POST /api/v1/images/current/pin", adminAuth(http.HandlerFunc(h.pinCurrentImage)))
+	mux.Handle("DELETE /api/v1/images/current/pin", adminAuth(http.HandlerFunc(h.unpinCurrentImage)))
+	mux.Handle("GET /api/v1/images/current/pins", adminAuth(http.HandlerFunc(h.listPins)))
+
+	// OAuth2 authorization endpoints (the callback is protected by the state parameter)
+	mux.Handle("GET /api/v1/admin/oauth/authorize", adminAuth(http.HandlerFunc(h.authorizeOAuth)))
+	mux.Handle("GET /api/v1/admin/oauth/status", adminAuth(http.HandlerFunc(h.getOAuthStatus)))
+	mux.HandleFunc("GET /api/v1/oauth/callback", h.oauthCallback)
+}
+
+// GET /api/v1/admin/sync
+func (h *AdminHTTPHandler) previewSync(w http.ResponseWriter, r *http.Request) {
+	h.runSync(w, true)
+}
+
+// POST /api/v1/admin/sync?dry_run=false
+func (h *AdminHTTPHandler) applySync(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if dryRunStr := r.URL.Query().Get("dry_run"); dryRunStr != "" {
+		value, err := strconv.ParseBool(dryRunStr)
+		if err != nil {
+			http.Error(w, "Invalid dry_run value", http.StatusBadRequest)
+			return
+		}
+		dryRun = value
+	}
+
+	h.runSync(w, dryRun)
+}
+
+// runSync reconciles Drive with the database and writes the report
+func (h *AdminHTTPHandler) runSync(w http.ResponseWriter, dryRun bool) {
+	if h.syncService == nil {
+		http.Error(w, "Sync requires the Google Drive storage backend", http.StatusNotImplemented)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	report, err := h.syncService.Reconcile(ctx, dryRun)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to sync: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GET /api/v1/admin/rotation
+func (h *AdminHTTPHandler) getRotation(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rotation, err := h.imageService.GetRotation(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get rotation settings: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(rotation); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// PUT /api/v1/admin/rotation with a JSON body of strategy, interval_minutes and timezone
+func (h *AdminHTTPHandler) updateRotation(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var settings interfaces.RotationSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	rotation, err := h.imageService.UpdateRotation(ctx, &settings)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to update rotation settings: %v", err), httpStatusFromError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(rotation); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// POST /api/v1/images/current/pin with a JSON body of image_id and an optional until
+func (h *AdminHTTPHandler) pinCurrentImage(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := decodePinRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.imageService.PinCurrentImage(ctx, req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to pin image: %v", err), httpStatusFromError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// DELETE /api/v1/images/current/pin
+func (h *AdminHTTPHandler) unpinCurrentImage(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.imageService.UnpinCurrentImage(ctx, &pb.UnpinCurrentImageRequest{})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to unpin image: %v", err), httpStatusFromError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GET /api/v1/images/current/pins?limit=
+func (h *AdminHTTPHandler) listPins(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := &pb.ListPinsRequest{}
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		req.Limit = int32(limit)
+	}
+
+	resp, err := h.imageService.ListPins(ctx, req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list pins: %v", err), httpStatusFromError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GET /api/v1/admin/oauth/authorize
+func (h *AdminHTTPHandler) authorizeOAuth(w http.ResponseWriter, r *http.Request) {
+	if h.oauthFlow == nil {
+		http.Error(w, "OAuth requires the Google Drive storage backend with GOOGLE_DRIVE_AUTH_MODE=oauth", http.StatusNotImplemented)
+		return
+	}
+
+	authURL, err := h.oauthFlow.AuthCodeURL()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to start authorization: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Allow API clients to fetch the URL instead of following the redirect
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]string{"auth_url": authURL}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// GET /api/v1/oauth/callback?state=...&code=...
+func (h *AdminHTTPHandler) oauthCallback(w http.ResponseWriter, r *http.Request) {
+	if h.oauthFlow == nil {
+		http.Error(w, "OAuth requires the Google Drive storage backend with GOOGLE_DRIVE_AUTH_MODE=oauth", http.StatusNotImplemented)
+		return
+	}
+
+	if errParam := r.URL.Query().Get("error"); errParam != "" {
+		http.Error(w, fmt.Sprintf("Authorization denied: %s", errParam), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	token, err := h.oauthFlow.Exchange(ctx, r.URL.Query().Get("state"), r.URL.Query().Get("code"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Authorization failed: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Google Drive authorized successfully",
+		"expiry":  token.Expiry,
+	}); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GET /api/v1/admin/oauth/status
+func (h *AdminHTTPHandler) getOAuthStatus(w http.ResponseWriter, r *http.Request) {
+	if h.oauthFlow == nil {
+		http.Error(w, "OAuth requires the Google Drive storage backend with GOOGLE_DRIVE_AUTH_MODE=oauth", http.StatusNotImplemented)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	status, err := h.oauthFlow.Status(ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get OAuth status: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
@@ -1,29 +1,41 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/imaging"
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/middleware"
 	"github.com/NirvekPanda/Background-Image-Drive-API/internal/services"
 	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // DirectHTTPHandler contains the services directly (no gRPC)
 type DirectHTTPHandler struct {
 	imageService    *services.ImageService
 	locationService *services.LocationService
+	adminConfig     *middleware.AdminConfig
 }
 
-// NewDirectHTTPHandler creates a new HTTP handler with direct service access
-func NewDirectHTTPHandler(imageService *services.ImageService, locationService *services.LocationService) *DirectHTTPHandler {
+// NewDirectHTTPHandler creates a new HTTP handler with direct service access.
+// Requests carrying the admin key in adminConfig may see unpublished and expired images.
+func NewDirectHTTPHandler(imageService *services.ImageService, locationService *services.LocationService, adminConfig *middleware.AdminConfig) *DirectHTTPHandler {
 	return &DirectHTTPHandler{
 		imageService:    imageService,
 		locationService: locationService,
+		adminConfig:     adminConfig,
 	}
 }
 
@@ -31,10 +43,14 @@ func NewDirectHTTPHandler(imageService *services.ImageService, locationService *
 func (h *DirectHTTPHandler) RegisterRoutes(mux *http.ServeMux) {
 	// Image endpoints
 	mux.HandleFunc("GET /api/v1/images/current", h.getCurrentImage)
+	mux.HandleFunc("GET /api/v1/images/current/raw", h.getCurrentImageRaw)
 	mux.HandleFunc("POST /api/v1/images/upload", h.uploadImage)
 	mux.HandleFunc("GET /api/v1/images/count", h.getImageCount)
 	mux.HandleFunc("GET /api/v1/images", h.listImages)
 	mux.HandleFunc("GET /api/v1/images/{id}", h.getImageById)
+	mux.HandleFunc("GET /api/v1/images/{id}/raw", h.getImageRaw)
+	mux.HandleFunc("GET /api/v1/images/{id}/similar", h.getSimilarImages)
+	mux.HandleFunc("PATCH /api/v1/images/{id}", h.updateImage)
 	mux.HandleFunc("DELETE /api/v1/images/{id}", h.deleteImage)
 
 	// Location endpoints
@@ -45,14 +61,20 @@ func (h *DirectHTTPHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /health", h.healthCheck)
 }
 
-// GET /api/v1/images/current
+// GET /api/v1/images/current?mode=time_of_day&lat=&lng=&tz=
 func (h *DirectHTTPHandler) getCurrentImage(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	image, err := h.imageService.GetCurrentImage(ctx, &pb.GetCurrentImageRequest{})
+	req, err := decodeCurrentImageRequest(r)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get current image: %v", err), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	image, err := h.imageService.GetCurrentImage(ctx, req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get current image: %v", err), httpStatusFromError(err))
 		return
 	}
 
@@ -60,30 +82,49 @@ func (h *DirectHTTPHandler) getCurrentImage(w http.ResponseWriter, r *http.Reque
 	json.NewEncoder(w).Encode(image)
 }
 
-// POST /api/v1/images/upload
-func (h *DirectHTTPHandler) uploadImage(w http.ResponseWriter, r *http.Request) {
+// GET /api/v1/images/current/raw
+func (h *DirectHTTPHandler) getCurrentImageRaw(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// Parse multipart form (10MB max)
-	err := r.ParseMultipartForm(10 << 20)
+	opts, err := parseImageDataOptions(r)
 	if err != nil {
-		http.Error(w, "Failed to parse form data", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	// Get file from form
-	file, _, err := r.FormFile("image")
+	req, err := decodeCurrentImageRequest(r)
 	if err != nil {
-		http.Error(w, "Image file is required", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	defer file.Close()
 
-	// Read file data
-	imageData, err := io.ReadAll(file)
+	imageData, err := h.imageService.GetCurrentImageData(ctx, req, opts)
 	if err != nil {
-		http.Error(w, "Failed to read image data", http.StatusInternalServerError)
+		if status.Code(err) == codes.InvalidArgument {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeImageDataError(w, err)
+		return
+	}
+
+	// The current image changes over time, so clients must revalidate. A viewer's
+	// shuffle bag is their own, so shared caches must not keep it.
+	if req.ViewerToken != "" {
+		w.Header().Set("Cache-Control", "private, no-cache")
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	serveImageData(w, r, imageData)
+}
+
+// POST /api/v1/images/upload
+func (h *DirectHTTPHandler) uploadImage(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	imageData, ok := readUploadForm(w, r, h.imageService.UploadLimits())
+	if !ok {
 		return
 	}
 
@@ -115,35 +156,59 @@ func (h *DirectHTTPHandler) uploadImage(w http.ResponseWriter, r *http.Request)
 
 	// Create upload request
 	req := &pb.UploadImageRequest{
-		Title:       title,
-		Description: description,
-		Location:    location,
-		ImageData:   imageData,
+		Title:          title,
+		Description:    description,
+		Location:       location,
+		ImageData:      imageData,
+		IdempotencyKey: r.Header.Get("Idempotency-Key"),
+	}
+	if err := parseDuplicateOptions(r, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := parsePublishWindow(r, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var err error
+	if req.TimeOfDay, err = parseTimeOfDay(r.FormValue("time_of_day")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Weight, err = parseWeight(r.FormValue("weight")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
 	// Call service directly
 	resp, err := h.imageService.UploadImage(ctx, req)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to upload image: %v", err), http.StatusInternalServerError)
+		writeUploadError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+	if resp.Replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		return
 	}
 }
 
-// GET /api/v1/images/count
+// GET /api/v1/images/count?visibility=
 func (h *DirectHTTPHandler) getImageCount(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	resp, err := h.imageService.GetImageCount(ctx, &pb.GetImageCountRequest{})
+	req := &pb.GetImageCountRequest{Visibility: r.URL.Query().Get("visibility")}
+	resp, err := h.imageService.GetImageCount(ctx, req)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get image count: %v", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Failed to get image count: %v", err), httpStatusFromError(err))
 		return
 	}
 
@@ -159,9 +224,19 @@ func (h *DirectHTTPHandler) listImages(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	resp, err := h.imageService.ListImages(ctx, &pb.ListImagesRequest{})
+	req, err := decodeListImagesRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var ok bool
+	if req.IncludeHidden, ok = includeHidden(w, r, h.adminConfig); !ok {
+		return
+	}
+
+	resp, err := h.imageService.ListImages(ctx, req)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to list images: %v", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Failed to list images: %v", err), httpStatusFromError(err))
 		return
 	}
 
@@ -186,6 +261,10 @@ func (h *DirectHTTPHandler) getImageById(w http.ResponseWriter, r *http.Request)
 	req := &pb.GetImageByIdRequest{
 		ImageId: imageId,
 	}
+	var ok bool
+	if req.IncludeHidden, ok = includeHidden(w, r, h.adminConfig); !ok {
+		return
+	}
 
 	resp, err := h.imageService.GetImageById(ctx, req)
 	if err != nil {
@@ -200,6 +279,119 @@ func (h *DirectHTTPHandler) getImageById(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// GET /api/v1/images/{id}/similar
+func (h *DirectHTTPHandler) getSimilarImages(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := decodeSimilarImagesRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var ok bool
+	if req.IncludeHidden, ok = includeHidden(w, r, h.adminConfig); !ok {
+		return
+	}
+
+	resp, err := h.imageService.GetSimilarImages(ctx, req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to find similar images: %v", err), httpStatusFromError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GET /api/v1/images/{id}/raw
+func (h *DirectHTTPHandler) getImageRaw(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	imageId := r.PathValue("id")
+	if imageId == "" {
+		http.Error(w, "Image ID is required", http.StatusBadRequest)
+		return
+	}
+
+	opts, err := parseImageDataOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var ok bool
+	if opts.IncludeHidden, ok = includeHidden(w, r, h.adminConfig); !ok {
+		return
+	}
+
+	imageData, err := h.imageService.GetImageData(ctx, imageId, opts)
+	if err != nil {
+		writeImageDataError(w, err)
+		return
+	}
+
+	if opts.IncludeHidden {
+		// Keep shared caches from serving an unpublished image to everyone
+		w.Header().Set("Cache-Control", "private, no-store")
+	} else {
+		w.Header().Set("Cache-Control", publicCacheControl(imageData.Metadata, time.Now()))
+	}
+	serveImageData(w, r, imageData)
+}
+
+// maxImageCacheAge is how long caches may keep image bytes, in seconds
+const maxImageCacheAge = 24 * 60 * 60
+
+// publicCacheControl lets caches keep an image for a day, but no longer than
+// until it expires, so an expired image stops being served on time
+func publicCacheControl(image *pb.ImageMetadata, now time.Time) string {
+	maxAge := int64(maxImageCacheAge)
+	if image.GetExpireAt() != nil {
+		maxAge = min(maxAge, int64(image.ExpireAt.AsTime().Sub(now)/time.Second))
+		if maxAge <= 0 {
+			return "no-store"
+		}
+	}
+	return fmt.Sprintf("public, max-age=%d", maxAge)
+}
+
+// PATCH /api/v1/images/{id}
+func (h *DirectHTTPHandler) updateImage(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	imageId := r.PathValue("id")
+	if imageId == "" {
+		http.Error(w, "Image ID is required", http.StatusBadRequest)
+		return
+	}
+
+	req, err := decodeUpdateImageRequest(r, imageId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !authorizeUpdate(w, r, req, h.adminConfig) {
+		return
+	}
+
+	resp, err := h.imageService.UpdateImage(ctx, req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to update image: %v", err), httpStatusFromError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
 // DELETE /api/v1/images/{id}
 func (h *DirectHTTPHandler) deleteImage(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -310,3 +502,441 @@ func (h *DirectHTTPHandler) healthCheck(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(health)
 }
+
+// serveImageData writes image bytes with Content-Type, Content-Length and Range support
+func serveImageData(w http.ResponseWriter, r *http.Request, imageData *services.ImageData) {
+	contentType := imageData.MimeType
+	if contentType == "" {
+		contentType = http.DetectContentType(imageData.Data)
+	}
+	w.Header().Set("Content-Type", contentType)
+	if imageData.FileID != "" {
+		w.Header().Set("ETag", fmt.Sprintf("%q", imageData.FileID))
+	}
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(imageData.Data))
+}
+
+// maxVariantWidth bounds the w query parameter of the raw image endpoints
+const maxVariantWidth = 10000
+
+// parseImageDataOptions reads the optional w (width) and aspect query parameters of the raw image endpoints
+func parseImageDataOptions(r *http.Request) (services.ImageDataOptions, error) {
+	opts := services.ImageDataOptions{}
+	if value := r.URL.Query().Get("w"); value != "" {
+		width, err := strconv.Atoi(value)
+		if err != nil || width <= 0 || width > maxVariantWidth {
+			return opts, fmt.Errorf("w must be a positive integer up to %d", maxVariantWidth)
+		}
+		opts.Width = width
+	}
+	if value := r.URL.Query().Get("aspect"); value != "" {
+		aspect, err := imaging.ParseAspectRatio(value)
+		if err != nil {
+			return opts, err
+		}
+		opts.Aspect = aspect
+	}
+	return opts, nil
+}
+
+// writeImageDataError maps image download errors to HTTP status codes
+func writeImageDataError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrImageNotFound) {
+		http.Error(w, "Image not found", http.StatusNotFound)
+		return
+	}
+	if status.Code(err) == codes.InvalidArgument {
+		http.Error(w, status.Convert(err).Message(), http.StatusBadRequest)
+		return
+	}
+	http.Error(w, fmt.Sprintf("Failed to get image data: %v", err), http.StatusBadGateway)
+}
+
+// decodeUpdateImageRequest parses a JSON body with optional title, description,
+// location, focal point, publish window and time_of_day fields. publish_at and
+// expire_at are RFC 3339 timestamps; null clears them. An empty time_of_day
+// removes the tag.
+func decodeUpdateImageRequest(r *http.Request, imageID string) (*pb.UpdateImageRequest, error) {
+	body := struct {
+		*pb.UpdateImageRequest
+		PublishAt json.RawMessage `json:"publish_at"`
+		ExpireAt  json.RawMessage `json:"expire_at"`
+		TimeOfDay *string         `json:"time_of_day"`
+	}{UpdateImageRequest: &pb.UpdateImageRequest{}}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid JSON body: %v", err)
+	}
+
+	req := body.UpdateImageRequest
+	var err error
+	if req.PublishAt, req.ClearPublishAt, err = decodeOptionalTime(body.PublishAt, req.ClearPublishAt); err != nil {
+		return nil, fmt.Errorf("invalid publish_at: %v", err)
+	}
+	if req.ExpireAt, req.ClearExpireAt, err = decodeOptionalTime(body.ExpireAt, req.ClearExpireAt); err != nil {
+		return nil, fmt.Errorf("invalid expire_at: %v", err)
+	}
+	if body.TimeOfDay != nil {
+		timeOfDay, err := parseTimeOfDay(*body.TimeOfDay)
+		if err != nil {
+			return nil, err
+		}
+		req.TimeOfDay = &timeOfDay
+	}
+	req.ImageId = imageID
+	return req, nil
+}
+
+// decodeOptionalTime decodes a JSON RFC 3339 timestamp. A JSON null sets
+// clear; an absent value leaves clear as it was.
+func decodeOptionalTime(raw json.RawMessage, clear bool) (*timestamppb.Timestamp, bool, error) {
+	if len(raw) == 0 {
+		return nil, clear, nil
+	}
+	if string(raw) == "null" {
+		return nil, true, nil
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, clear, fmt.Errorf("expected an RFC 3339 string or null")
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, clear, fmt.Errorf("expected RFC 3339, got %q", value)
+	}
+	return timestamppb.New(t), clear, nil
+}
+
+// decodePinRequest reads the image_id and optional until (RFC 3339) of a pin request
+func decodePinRequest(r *http.Request) (*pb.PinCurrentImageRequest, error) {
+	var body struct {
+		ImageID string `json:"image_id"`
+		Until   string `json:"until"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid JSON body: %v", err)
+	}
+
+	req := &pb.PinCurrentImageRequest{ImageId: body.ImageID}
+	if body.Until != "" {
+		until, err := time.Parse(time.RFC3339, body.Until)
+		if err != nil {
+			return nil, fmt.Errorf("invalid until: expected RFC 3339, got %q", body.Until)
+		}
+		req.Until = timestamppb.New(until)
+	}
+	return req, nil
+}
+
+// decodeListImagesRequest parses the filter and sort query parameters of GET /api/v1/images.
+// Dates are RFC 3339 timestamps or YYYY-MM-DD days.
+func decodeListImagesRequest(r *http.Request) (*pb.ListImagesRequest, error) {
+	query := r.URL.Query()
+	req := &pb.ListImagesRequest{
+		CameraMake:  query.Get("camera_make"),
+		CameraModel: query.Get("camera_model"),
+		LensModel:   query.Get("lens_model"),
+		SortBy:      query.Get("sort"),
+		SortOrder:   query.Get("order"),
+	}
+
+	var err error
+	if req.TimeOfDay, err = parseTimeOfDay(query.Get("time_of_day")); err != nil {
+		return nil, err
+	}
+	if req.TakenAfter, err = parseQueryTime(query.Get("taken_after")); err != nil {
+		return nil, fmt.Errorf("invalid taken_after: %v", err)
+	}
+	if req.TakenBefore, err = parseQueryTime(query.Get("taken_before")); err != nil {
+		return nil, fmt.Errorf("invalid taken_before: %v", err)
+	}
+
+	for name, target := range map[string]**int32{"min_iso": &req.MinIso, "max_iso": &req.MaxIso} {
+		if value := query.Get(name); value != "" {
+			iso, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %v", name, err)
+			}
+			*target = proto.Int32(int32(iso))
+		}
+	}
+
+	for name, target := range map[string]**float64{"min_focal_length": &req.MinFocalLengthMm, "max_focal_length": &req.MaxFocalLengthMm} {
+		if value := query.Get(name); value != "" {
+			focalLength, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %v", name, err)
+			}
+			*target = proto.Float64(focalLength)
+		}
+	}
+
+	return req, nil
+}
+
+// decodeSimilarImagesRequest reads the image ID, max_distance and limit of a similar images request
+func decodeSimilarImagesRequest(r *http.Request) (*pb.GetSimilarImagesRequest, error) {
+	req := &pb.GetSimilarImagesRequest{ImageId: r.PathValue("id")}
+	if req.ImageId == "" {
+		return nil, fmt.Errorf("image ID is required")
+	}
+
+	query := r.URL.Query()
+	if value := query.Get("max_distance"); value != "" {
+		distance, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max_distance: %v", err)
+		}
+		req.MaxDistance = proto.Int32(int32(distance))
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid limit: %v", err)
+		}
+		req.Limit = int32(limit)
+	}
+
+	return req, nil
+}
+
+// parseDuplicateOptions reads the duplicate_policy (allow, warn or reject) and
+// duplicate_threshold form fields of an upload
+func parseDuplicateOptions(r *http.Request, req *pb.UploadImageRequest) error {
+	switch policy := r.FormValue("duplicate_policy"); policy {
+	case "", "allow":
+		req.DuplicatePolicy = pb.DuplicatePolicy_DUPLICATE_POLICY_ALLOW
+	case "warn":
+		req.DuplicatePolicy = pb.DuplicatePolicy_DUPLICATE_POLICY_WARN
+	case "reject":
+		req.DuplicatePolicy = pb.DuplicatePolicy_DUPLICATE_POLICY_REJECT
+	default:
+		return fmt.Errorf("duplicate_policy must be allow, warn or reject, got %q", policy)
+	}
+
+	if value := r.FormValue("duplicate_threshold"); value != "" {
+		threshold, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid duplicate_threshold: %v", err)
+		}
+		req.DuplicateThreshold = proto.Int32(int32(threshold))
+	}
+	return nil
+}
+
+// decodeCurrentImageRequest reads the mode (rotation or time_of_day), the
+// viewer's lat, lng and tz, and the seed and viewer_token query parameters of a
+// current image request
+func decodeCurrentImageRequest(r *http.Request) (*pb.GetCurrentImageRequest, error) {
+	query := r.URL.Query()
+	req := &pb.GetCurrentImageRequest{Timezone: query.Get("tz")}
+
+	switch mode := query.Get("mode"); mode {
+	case "", "rotation":
+		req.Mode = pb.CurrentImageMode_CURRENT_IMAGE_MODE_ROTATION
+	case "time_of_day":
+		req.Mode = pb.CurrentImageMode_CURRENT_IMAGE_MODE_TIME_OF_DAY
+	default:
+		return nil, fmt.Errorf("mode must be rotation or time_of_day, got %q", mode)
+	}
+
+	for name, target := range map[string]**float64{"lat": &req.Latitude, "lng": &req.Longitude} {
+		if value := query.Get(name); value != "" {
+			coordinate, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %v", name, err)
+			}
+			*target = proto.Float64(coordinate)
+		}
+	}
+
+	if value := query.Get("seed"); value != "" {
+		seed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seed: %v", err)
+		}
+		req.Seed = proto.Int64(seed)
+	}
+	req.ViewerToken = query.Get("viewer_token")
+
+	return req, nil
+}
+
+// parseTimeOfDay parses a dawn, day, dusk or night tag; empty values are untagged
+func parseTimeOfDay(value string) (pb.TimeOfDay, error) {
+	if value == "" {
+		return pb.TimeOfDay_TIME_OF_DAY_UNSPECIFIED, nil
+	}
+	timeOfDay, ok := pb.TimeOfDay_value["TIME_OF_DAY_"+strings.ToUpper(value)]
+	if !ok || timeOfDay == int32(pb.TimeOfDay_TIME_OF_DAY_UNSPECIFIED) {
+		return 0, fmt.Errorf("time_of_day must be dawn, day, dusk or night, got %q", value)
+	}
+	return pb.TimeOfDay(timeOfDay), nil
+}
+
+// parseWeight parses the optional weight form field of an upload
+func parseWeight(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	weight, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid weight: %v", err)
+	}
+	return proto.Float64(weight), nil
+}
+
+// parsePublishWindow reads the optional publish_at and expire_at form fields
+// of an upload, as RFC 3339 timestamps or YYYY-MM-DD days
+func parsePublishWindow(r *http.Request, req *pb.UploadImageRequest) error {
+	var err error
+	if req.PublishAt, err = parseQueryTime(r.FormValue("publish_at")); err != nil {
+		return fmt.Errorf("invalid publish_at: %v", err)
+	}
+	if req.ExpireAt, err = parseQueryTime(r.FormValue("expire_at")); err != nil {
+		return fmt.Errorf("invalid expire_at: %v", err)
+	}
+	return nil
+}
+
+// authorizeUpdate rejects changes to the publish window by callers without the
+// admin key, since they would reveal or hide images. On failure it writes the
+// error response and returns false.
+func authorizeUpdate(w http.ResponseWriter, r *http.Request, req *pb.UpdateImageRequest, adminConfig *middleware.AdminConfig) bool {
+	if services.EditsPublishWindow(req) && !adminConfig.Authorized(r) {
+		http.Error(w, "publish_at and expire_at require the admin API key", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// includeHidden reads the include_hidden query parameter, which only admins may
+// set. On failure it writes the error response and returns false.
+func includeHidden(w http.ResponseWriter, r *http.Request, adminConfig *middleware.AdminConfig) (bool, bool) {
+	value := r.URL.Query().Get("include_hidden")
+	if value == "" {
+		return false, true
+	}
+
+	include, err := strconv.ParseBool(value)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid include_hidden: %v", err), http.StatusBadRequest)
+		return false, false
+	}
+	if include && !adminConfig.Authorized(r) {
+		http.Error(w, "include_hidden requires the admin API key", http.StatusUnauthorized)
+		return false, false
+	}
+	return include, true
+}
+
+// parseQueryTime parses an RFC 3339 timestamp or a YYYY-MM-DD day; empty values return nil
+func parseQueryTime(value string) (*timestamppb.Timestamp, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		if t, err = time.Parse(time.DateOnly, value); err != nil {
+			return nil, fmt.Errorf("expected RFC 3339 or YYYY-MM-DD, got %q", value)
+		}
+	}
+	return timestamppb.New(t), nil
+}
+
+// httpStatusFromError maps gRPC status codes returned by the image service to HTTP status codes
+func httpStatusFromError(err error) int {
+	// Also matches the error after a gRPC round trip, which keeps the code and message
+	if errors.Is(err, services.ErrUnsupportedImageType) {
+		return http.StatusUnsupportedMediaType
+	}
+
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.ResourceExhausted:
+		return http.StatusRequestEntityTooLarge
+	case codes.Aborted, codes.AlreadyExists:
+		return http.StatusConflict
+	case codes.FailedPrecondition:
+		return http.StatusUnprocessableEntity
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// uploadFormOverhead is the room left for form fields and multipart headers beyond the image byte limit
+const uploadFormOverhead = 1 << 20
+
+// readUploadForm parses an upload form and returns the image file's data. The body
+// is capped at the byte limit while reading, so oversized uploads are never buffered
+// whole. It writes the error response and returns false when the form is unusable.
+func readUploadForm(w http.ResponseWriter, r *http.Request, limits imaging.Limits) ([]byte, bool) {
+	if limits.MaxBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, limits.MaxBytes+uploadFormOverhead)
+	}
+
+	// Parse multipart form, keeping up to 10MB in memory
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeLimitError(w, http.StatusRequestEntityTooLarge, &imaging.LimitError{
+				Limit:  imaging.LimitBytes,
+				Actual: r.ContentLength,
+				Max:    limits.MaxBytes,
+			})
+			return nil, false
+		}
+		http.Error(w, "Failed to parse form data", http.StatusBadRequest)
+		return nil, false
+	}
+
+	// Get file from form
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		http.Error(w, "Image file is required", http.StatusBadRequest)
+		return nil, false
+	}
+	defer file.Close()
+
+	// Read file data
+	imageData, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "Failed to read image data", http.StatusInternalServerError)
+		return nil, false
+	}
+	return imageData, true
+}
+
+// writeUploadError writes an UploadImage error, describing violated upload limits as JSON
+func writeUploadError(w http.ResponseWriter, err error) {
+	statusCode := httpStatusFromError(err)
+	if limitErr := services.UploadLimitFromError(err); limitErr != nil {
+		writeLimitError(w, statusCode, limitErr)
+		return
+	}
+	http.Error(w, fmt.Sprintf("Failed to upload image: %v", err), statusCode)
+}
+
+// writeLimitError writes a violated upload limit as a JSON error body
+func writeLimitError(w http.ResponseWriter, statusCode int, limitErr *imaging.LimitError) {
+	body := map[string]interface{}{
+		"error":   "image_limit_exceeded",
+		"message": limitErr.Error(),
+		"limit":   limitErr.Limit,
+		"max":     limitErr.Max,
+	}
+	if limitErr.Actual > 0 {
+		body["actual"] = limitErr.Actual
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(body)
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/imaging"
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/middleware"
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/services"
 	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
 	"google.golang.org/grpc"
 )
@@ -17,24 +19,36 @@ import (
 type HTTPHandler struct {
 	imageClient    pb.ImageServiceClient
 	locationClient pb.LocationServiceClient
+	uploadLimits   imaging.Limits
+	adminConfig    *middleware.AdminConfig
 }
 
-// NewHTTPHandler creates a new HTTP handler with gRPC clients
-func NewHTTPHandler(imageConn, locationConn *grpc.ClientConn) *HTTPHandler {
+// NewHTTPHandler creates a new HTTP handler with gRPC clients. Requests
+// carrying the admin key in adminConfig may see unpublished and expired images.
+func NewHTTPHandler(imageConn, locationConn *grpc.ClientConn, adminConfig *middleware.AdminConfig) *HTTPHandler {
 	return &HTTPHandler{
 		imageClient:    pb.NewImageServiceClient(imageConn),
 		locationClient: pb.NewLocationServiceClient(locationConn),
+		uploadLimits:   services.LoadUploadLimitsFromEnv(),
+		adminConfig:    adminConfig,
 	}
 }
 
 // RegisterRoutes sets up all HTTP routes
 func (h *HTTPHandler) RegisterRoutes(mux *http.ServeMux) {
+	adminAuth := middleware.AdminAuth(h.adminConfig)
+
 	// Image endpoints
 	mux.HandleFunc("GET /api/v1/images/current", h.getCurrentImage)
+	mux.Handle("POST /api/v1/images/current/pin", adminAuth(http.HandlerFunc(h.pinCurrentImage)))
+	mux.Handle("DELETE /api/v1/images/current/pin", adminAuth(http.HandlerFunc(h.unpinCurrentImage)))
+	mux.Handle("GET /api/v1/images/current/pins", adminAuth(http.HandlerFunc(h.listPins)))
 	mux.HandleFunc("POST /api/v1/images/upload", h.uploadImage)
 	mux.HandleFunc("GET /api/v1/images/count", h.getImageCount)
 	mux.HandleFunc("GET /api/v1/images", h.listImages)
 	mux.HandleFunc("GET /api/v1/images/{id}", h.getImageById)
+	mux.HandleFunc("GET /api/v1/images/{id}/similar", h.getSimilarImages)
+	mux.HandleFunc("PATCH /api/v1/images/{id}", h.updateImage)
 	mux.HandleFunc("DELETE /api/v1/images/{id}", h.deleteImage)
 
 	// Location endpoints
@@ -45,14 +59,20 @@ func (h *HTTPHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /health", h.healthCheck)
 }
 
-// GET /api/v1/images/current
+// GET /api/v1/images/current?mode=time_of_day&lat=&lng=&tz=
 func (h *HTTPHandler) getCurrentImage(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	resp, err := h.imageClient.GetCurrentImage(ctx, &pb.GetCurrentImageRequest{})
+	req, err := decodeCurrentImageRequest(r)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get current image: %v", err), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.imageClient.GetCurrentImage(ctx, req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get current image: %v", err), httpStatusFromError(err))
 		return
 	}
 
@@ -60,30 +80,77 @@ func (h *HTTPHandler) getCurrentImage(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
-// POST /api/v1/images/upload
-func (h *HTTPHandler) uploadImage(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+// POST /api/v1/images/current/pin with a JSON body of image_id and an optional until
+func (h *HTTPHandler) pinCurrentImage(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+	ctx = middleware.ForwardAdminKey(ctx, r)
 
-	// Parse multipart form (10MB max)
-	err := r.ParseMultipartForm(10 << 20)
+	req, err := decodePinRequest(r)
 	if err != nil {
-		http.Error(w, "Failed to parse form data", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Get file from form
-	file, _, err := r.FormFile("image")
+	resp, err := h.imageClient.PinCurrentImage(ctx, req)
 	if err != nil {
-		http.Error(w, "Image file is required", http.StatusBadRequest)
+		http.Error(w, fmt.Sprintf("Failed to pin image: %v", err), httpStatusFromError(err))
 		return
 	}
-	defer file.Close()
 
-	// Read file data
-	imageData, err := io.ReadAll(file)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// DELETE /api/v1/images/current/pin
+func (h *HTTPHandler) unpinCurrentImage(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = middleware.ForwardAdminKey(ctx, r)
+
+	resp, err := h.imageClient.UnpinCurrentImage(ctx, &pb.UnpinCurrentImageRequest{})
 	if err != nil {
-		http.Error(w, "Failed to read image data", http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Failed to unpin image: %v", err), httpStatusFromError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// GET /api/v1/images/current/pins?limit=
+func (h *HTTPHandler) listPins(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = middleware.ForwardAdminKey(ctx, r)
+
+	req := &pb.ListPinsRequest{}
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		req.Limit = int32(limit)
+	}
+
+	resp, err := h.imageClient.ListPins(ctx, req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list pins: %v", err), httpStatusFromError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// POST /api/v1/images/upload
+func (h *HTTPHandler) uploadImage(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	imageData, ok := readUploadForm(w, r, h.uploadLimits)
+	if !ok {
 		return
 	}
 
@@ -115,32 +182,56 @@ func (h *HTTPHandler) uploadImage(w http.ResponseWriter, r *http.Request) {
 
 	// Create upload request
 	req := &pb.UploadImageRequest{
-		Title:       title,
-		Description: description,
-		Location:    location,
-		ImageData:   imageData,
+		Title:          title,
+		Description:    description,
+		Location:       location,
+		ImageData:      imageData,
+		IdempotencyKey: r.Header.Get("Idempotency-Key"),
+	}
+	if err := parseDuplicateOptions(r, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := parsePublishWindow(r, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var err error
+	if req.TimeOfDay, err = parseTimeOfDay(r.FormValue("time_of_day")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Weight, err = parseWeight(r.FormValue("weight")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
 	// Call gRPC service
 	resp, err := h.imageClient.UploadImage(ctx, req)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to upload image: %v", err), http.StatusInternalServerError)
+		writeUploadError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+	if resp.Replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
 	json.NewEncoder(w).Encode(resp)
 }
 
-// GET /api/v1/images/count
+// GET /api/v1/images/count?visibility=
 func (h *HTTPHandler) getImageCount(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	resp, err := h.imageClient.GetImageCount(ctx, &pb.GetImageCountRequest{})
+	req := &pb.GetImageCountRequest{Visibility: r.URL.Query().Get("visibility")}
+	resp, err := h.imageClient.GetImageCount(ctx, req)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get image count: %v", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Failed to get image count: %v", err), httpStatusFromError(err))
 		return
 	}
 
@@ -152,10 +243,21 @@ func (h *HTTPHandler) getImageCount(w http.ResponseWriter, r *http.Request) {
 func (h *HTTPHandler) listImages(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
+	ctx = middleware.ForwardAdminKey(ctx, r)
+
+	req, err := decodeListImagesRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var ok bool
+	if req.IncludeHidden, ok = includeHidden(w, r, h.adminConfig); !ok {
+		return
+	}
 
-	resp, err := h.imageClient.ListImages(ctx, &pb.ListImagesRequest{})
+	resp, err := h.imageClient.ListImages(ctx, req)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to list images: %v", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Failed to list images: %v", err), httpStatusFromError(err))
 		return
 	}
 
@@ -167,6 +269,7 @@ func (h *HTTPHandler) listImages(w http.ResponseWriter, r *http.Request) {
 func (h *HTTPHandler) getImageById(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+	ctx = middleware.ForwardAdminKey(ctx, r)
 
 	imageId := r.PathValue("id")
 	if imageId == "" {
@@ -177,6 +280,10 @@ func (h *HTTPHandler) getImageById(w http.ResponseWriter, r *http.Request) {
 	req := &pb.GetImageByIdRequest{
 		ImageId: imageId,
 	}
+	var ok bool
+	if req.IncludeHidden, ok = includeHidden(w, r, h.adminConfig); !ok {
+		return
+	}
 
 	resp, err := h.imageClient.GetImageById(ctx, req)
 	if err != nil {
@@ -188,6 +295,63 @@ func (h *HTTPHandler) getImageById(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+// GET /api/v1/images/{id}/similar
+func (h *HTTPHandler) getSimilarImages(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ctx = middleware.ForwardAdminKey(ctx, r)
+
+	req, err := decodeSimilarImagesRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var ok bool
+	if req.IncludeHidden, ok = includeHidden(w, r, h.adminConfig); !ok {
+		return
+	}
+
+	resp, err := h.imageClient.GetSimilarImages(ctx, req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to find similar images: %v", err), httpStatusFromError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// PATCH /api/v1/images/{id}
+func (h *HTTPHandler) updateImage(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ctx = middleware.ForwardAdminKey(ctx, r)
+
+	imageId := r.PathValue("id")
+	if imageId == "" {
+		http.Error(w, "Image ID is required", http.StatusBadRequest)
+		return
+	}
+
+	req, err := decodeUpdateImageRequest(r, imageId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !authorizeUpdate(w, r, req, h.adminConfig) {
+		return
+	}
+
+	resp, err := h.imageClient.UpdateImage(ctx, req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to update image: %v", err), httpStatusFromError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
 // DELETE /api/v1/images/{id}
 func (h *HTTPHandler) deleteImage(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -0,0 +1,155 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+// maxAspectTerm bounds each side of an aspect ratio
+const maxAspectTerm = 100
+
+// focalSampleWidth is the width images are scaled to before detecting the focal point
+const focalSampleWidth = 128
+
+// focalGridSize is the number of cells per side used to detect the focal point
+const focalGridSize = 8
+
+// AspectRatio is a width:height ratio in lowest terms
+type AspectRatio struct {
+	Width  int
+	Height int
+}
+
+// ParseAspectRatio parses a ratio such as "16:9" and reduces it to lowest terms
+func ParseAspectRatio(value string) (AspectRatio, error) {
+	widthText, heightText, ok := strings.Cut(value, ":")
+	if !ok {
+		return AspectRatio{}, fmt.Errorf("aspect ratio must look like 16:9")
+	}
+	width, err := strconv.Atoi(widthText)
+	if err != nil || width <= 0 || width > maxAspectTerm {
+		return AspectRatio{}, fmt.Errorf("aspect ratio terms must be integers between 1 and %d", maxAspectTerm)
+	}
+	height, err := strconv.Atoi(heightText)
+	if err != nil || height <= 0 || height > maxAspectTerm {
+		return AspectRatio{}, fmt.Errorf("aspect ratio terms must be integers between 1 and %d", maxAspectTerm)
+	}
+
+	divisor := gcd(width, height)
+	return AspectRatio{Width: width / divisor, Height: height / divisor}, nil
+}
+
+// IsZero reports whether no aspect ratio is set
+func (a AspectRatio) IsZero() bool {
+	return a.Width == 0 || a.Height == 0
+}
+
+// String formats the ratio as width:height
+func (a AspectRatio) String() string {
+	return fmt.Sprintf("%d:%d", a.Width, a.Height)
+}
+
+// FocalPoint returns the most detailed area of img as fractions of its width
+// and height. The image is divided into a grid and the centroid of the cells
+// whose luminance entropy is close to the maximum is returned.
+func FocalPoint(img image.Image) (float64, float64) {
+	if img.Bounds().Dx() > focalSampleWidth {
+		img = ResizeToWidth(img, focalSampleWidth)
+	}
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < focalGridSize || height < focalGridSize {
+		return 0.5, 0.5
+	}
+
+	var entropies [focalGridSize][focalGridSize]float64
+	maximum := 0.0
+	for row := 0; row < focalGridSize; row++ {
+		for column := 0; column < focalGridSize; column++ {
+			cell := image.Rect(
+				bounds.Min.X+column*width/focalGridSize, bounds.Min.Y+row*height/focalGridSize,
+				bounds.Min.X+(column+1)*width/focalGridSize, bounds.Min.Y+(row+1)*height/focalGridSize,
+			)
+			entropies[row][column] = luminanceEntropy(img, cell)
+			maximum = math.Max(maximum, entropies[row][column])
+		}
+	}
+	if maximum == 0 {
+		// Flat image
+		return 0.5, 0.5
+	}
+
+	var x, y, total float64
+	for row := 0; row < focalGridSize; row++ {
+		for column := 0; column < focalGridSize; column++ {
+			entropy := entropies[row][column]
+			if entropy < maximum*0.9 {
+				continue
+			}
+			x += entropy * (float64(column) + 0.5) / focalGridSize
+			y += entropy * (float64(row) + 0.5) / focalGridSize
+			total += entropy
+		}
+	}
+	return x / total, y / total
+}
+
+// CropToAspect returns the largest region of img with the given aspect ratio,
+// centred as closely as possible on the focal point (fractions of the width and height)
+func CropToAspect(img image.Image, aspect AspectRatio, focalX, focalY float64) image.Image {
+	bounds := img.Bounds()
+	if aspect.IsZero() || bounds.Empty() {
+		return img
+	}
+
+	width, height := bounds.Dx(), bounds.Dy()
+	if width*aspect.Height > height*aspect.Width {
+		width = max(1, height*aspect.Width/aspect.Height)
+	} else {
+		height = max(1, width*aspect.Height/aspect.Width)
+	}
+
+	left := clamp(int(math.Round(focalX*float64(bounds.Dx())-float64(width)/2)), 0, bounds.Dx()-width)
+	top := clamp(int(math.Round(focalY*float64(bounds.Dy())-float64(height)/2)), 0, bounds.Dy()-height)
+	region := image.Rect(0, 0, width, height).Add(bounds.Min).Add(image.Pt(left, top))
+
+	cropped := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(cropped, cropped.Bounds(), img, region.Min, draw.Src)
+	return cropped
+}
+
+// luminanceEntropy returns the Shannon entropy of the luminance histogram of a region of img
+func luminanceEntropy(img image.Image, region image.Rectangle) float64 {
+	var histogram [32]int
+	count := 0
+	for y := region.Min.Y; y < region.Max.Y; y++ {
+		for x := region.Min.X; x < region.Max.X; x++ {
+			gray := color.GrayModel.Convert(img.At(x, y)).(color.Gray)
+			histogram[gray.Y/8]++
+			count++
+		}
+	}
+
+	entropy := 0.0
+	for _, n := range histogram {
+		if n == 0 {
+			continue
+		}
+		p := float64(n) / float64(count)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,73 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// detailedImage returns a flat gray image with a noisy square around (x, y)
+func detailedImage(width, height, x, y int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for py := 0; py < height; py++ {
+		for px := 0; px < width; px++ {
+			c := color.RGBA{R: 128, G: 128, B: 128, A: 255}
+			if px >= x-20 && px < x+20 && py >= y-20 && py < y+20 {
+				v := uint8((px*97 + py*31) % 256)
+				c = color.RGBA{R: v, G: 255 - v, B: v / 2, A: 255}
+			}
+			img.Set(px, py, c)
+		}
+	}
+	return img
+}
+
+func TestFocalPoint(t *testing.T) {
+	x, y := FocalPoint(detailedImage(400, 200, 300, 60))
+	if math.Abs(x-0.75) > 0.1 || math.Abs(y-0.3) > 0.1 {
+		t.Errorf("Expected a focal point near 0.75,0.3, got %.2f,%.2f", x, y)
+	}
+
+	x, y = FocalPoint(image.NewRGBA(image.Rect(0, 0, 100, 100)))
+	if x != 0.5 || y != 0.5 {
+		t.Errorf("Expected the centre of a flat image, got %.2f,%.2f", x, y)
+	}
+}
+
+func TestCropToAspect(t *testing.T) {
+	img := detailedImage(400, 200, 300, 60)
+
+	tests := []struct {
+		aspect         AspectRatio
+		focalX, focalY float64
+		expected       image.Rectangle
+	}{
+		{AspectRatio{9, 16}, 0.75, 0.3, image.Rect(244, 0, 356, 200)},
+		{AspectRatio{9, 16}, 1, 0.5, image.Rect(288, 0, 400, 200)},
+		{AspectRatio{9, 16}, 0, 0.5, image.Rect(0, 0, 112, 200)},
+		{AspectRatio{4, 1}, 0.5, 0.9, image.Rect(0, 100, 400, 200)},
+	}
+	for _, tt := range tests {
+		cropped := CropToAspect(img, tt.aspect, tt.focalX, tt.focalY)
+		if cropped.Bounds().Size() != tt.expected.Size() {
+			t.Errorf("%s: expected size %v, got %v", tt.aspect, tt.expected.Size(), cropped.Bounds().Size())
+			continue
+		}
+		if cropped.At(0, 0) != img.At(tt.expected.Min.X, tt.expected.Min.Y) {
+			t.Errorf("%s at %.2f,%.2f: expected the crop to start at %v", tt.aspect, tt.focalX, tt.focalY, tt.expected.Min)
+		}
+	}
+}
+
+func TestParseAspectRatio(t *testing.T) {
+	aspect, err := ParseAspectRatio("18:32")
+	if err != nil || aspect != (AspectRatio{9, 16}) {
+		t.Errorf("Expected 9:16, got %v (%v)", aspect, err)
+	}
+	for _, value := range []string{"", "16", "0:9", "16:x", "1000:1"} {
+		if _, err := ParseAspectRatio(value); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math/bits"
+	"strconv"
+
+	"golang.org/x/image/draw"
+)
+
+// HashBits is the number of bits in a perceptual hash
+const HashBits = 64
+
+// DHash returns the difference hash of img: each bit records whether a cell of
+// a 9x8 grayscale thumbnail is brighter than its right neighbour. Copies of an
+// image at other sizes, qualities or with small edits hash to within a few bits.
+func DHash(img image.Image) uint64 {
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return 0
+	}
+
+	thumbnail := image.NewGray(image.Rect(0, 0, 9, 8))
+	draw.CatmullRom.Scale(thumbnail, thumbnail.Bounds(), img, bounds, draw.Src, nil)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if thumbnail.GrayAt(x, y).Y > thumbnail.GrayAt(x+1, y).Y {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// FormatHash encodes a perceptual hash as 16 hex digits
+func FormatHash(hash uint64) string {
+	return fmt.Sprintf("%016x", hash)
+}
+
+// ParseHash decodes a perceptual hash encoded by FormatHash
+func ParseHash(value string) (uint64, error) {
+	if len(value) != 16 {
+		return 0, fmt.Errorf("perceptual hash must be 16 hex digits, got %q", value)
+	}
+	hash, err := strconv.ParseUint(value, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid perceptual hash %q: %v", value, err)
+	}
+	return hash, nil
+}
+
+// HammingDistance returns the number of bits that differ between two hashes
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
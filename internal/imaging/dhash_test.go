@@ -0,0 +1,53 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// gradientImage returns an image with a diagonal gradient and a dark band
+func gradientImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := uint8((x*255/width + y*255/height) / 2)
+			if x > width/3 && x < width/2 {
+				v /= 4
+			}
+			img.Set(x, y, color.RGBA{R: v, G: v, B: 255 - v, A: 255})
+		}
+	}
+	return img
+}
+
+func TestDHash(t *testing.T) {
+	original := DHash(gradientImage(640, 480))
+
+	// A smaller copy is a near duplicate
+	if distance := HammingDistance(original, DHash(ResizeToWidth(gradientImage(640, 480), 160))); distance > 4 {
+		t.Errorf("Expected a resized copy within 4 bits, got %d", distance)
+	}
+
+	// A mirrored copy is not
+	if distance := HammingDistance(original, DHash(Orient(gradientImage(640, 480), 2))); distance < 20 {
+		t.Errorf("Expected a mirrored copy to be at least 20 bits away, got %d", distance)
+	}
+
+	if DHash(image.NewRGBA(image.Rect(0, 0, 0, 0))) != 0 {
+		t.Error("Expected an empty image to hash to 0")
+	}
+}
+
+func TestParseHash(t *testing.T) {
+	hash, err := ParseHash(FormatHash(0x00ff00ff00ff00ff))
+	if err != nil || hash != 0x00ff00ff00ff00ff {
+		t.Fatalf("Expected the hash to round trip, got %x %v", hash, err)
+	}
+
+	for _, value := range []string{"", "ff", "zzzzzzzzzzzzzzzz"} {
+		if _, err := ParseHash(value); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// exifDateTimeLayout is the format of EXIF date/time values
+const exifDateTimeLayout = "2006:01:02 15:04:05"
+
+// ErrNoEXIF is returned for images without EXIF data
+var ErrNoEXIF = errors.New("no EXIF data")
+
+// exifHeader prefixes the TIFF block in JPEG APP1 segments
+var exifHeader = []byte("Exif\x00\x00")
+
+// EXIF holds the EXIF fields used by the image service
+type EXIF struct {
+	// HasLocation reports whether GPS coordinates were found
+	HasLocation bool
+	Latitude    float64
+	Longitude   float64
+
+	// TakenAt is the capture time. EXIF stores local wall-clock time without
+	// a zone, so it is returned as if it were UTC.
+	TakenAt       *time.Time
+	CameraMake    string
+	CameraModel   string
+	LensModel     string
+	FocalLengthMM float64
+	// Aperture is the f-number
+	Aperture float64
+	ISO      int
+	// ExposureTime is in seconds
+	ExposureTime float64
+
+	// Orientation is the EXIF orientation (1-8) the image has to be turned by
+	// for display; 1 when the tag is missing
+	Orientation int
+}
+
+// ReadEXIF extracts EXIF data from a JPEG, PNG or WebP image
+func ReadEXIF(data []byte) (result *EXIF, err error) {
+	// The EXIF decoder panics on some malformed values, e.g. zero denominators
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("failed to read EXIF data: %v", r)
+		}
+	}()
+
+	payload := exifPayload(data)
+	if payload == nil {
+		return nil, ErrNoEXIF
+	}
+
+	x, err := exif.Decode(bytes.NewReader(payload))
+	if err != nil {
+		if exif.IsCriticalError(err) {
+			return nil, fmt.Errorf("failed to read EXIF data: %v", err)
+		}
+		// Non-critical errors leave the tags that could be parsed
+	}
+
+	result = &EXIF{Orientation: 1}
+	if latitude, longitude, err := x.LatLong(); err == nil && validCoordinates(latitude, longitude) {
+		result.HasLocation = true
+		result.Latitude = latitude
+		result.Longitude = longitude
+	}
+
+	if value := exifString(x, exif.DateTimeOriginal); value != "" {
+		if takenAt, err := time.Parse(exifDateTimeLayout, value); err == nil {
+			result.TakenAt = &takenAt
+		}
+	}
+	result.CameraMake = exifString(x, exif.Make)
+	result.CameraModel = exifString(x, exif.Model)
+	result.LensModel = exifString(x, exif.LensModel)
+	result.FocalLengthMM = exifFloat(x, exif.FocalLength)
+	result.Aperture = exifFloat(x, exif.FNumber)
+	result.ExposureTime = exifFloat(x, exif.ExposureTime)
+	if tag, err := x.Get(exif.ISOSpeedRatings); err == nil {
+		if iso, err := tag.Int(0); err == nil && iso > 0 {
+			result.ISO = iso
+		}
+	}
+
+	if tag, err := x.Get(exif.Orientation); err == nil {
+		if orientation, err := tag.Int(0); err == nil && orientation >= 1 && orientation <= 8 {
+			result.Orientation = orientation
+		}
+	}
+
+	return result, nil
+}
+
+// exifString returns a trimmed string tag, or "" when it is missing
+func exifString(x *exif.Exif, name exif.FieldName) string {
+	tag, err := x.Get(name)
+	if err != nil {
+		return ""
+	}
+	value, err := tag.StringVal()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(value, "\x00"))
+}
+
+// exifFloat returns a rational tag as a float, or 0 when it is missing
+func exifFloat(x *exif.Exif, name exif.FieldName) float64 {
+	tag, err := x.Get(name)
+	if err != nil {
+		return 0
+	}
+	// Rat2 rather than Rat: a zero denominator would panic in big.NewRat
+	numerator, denominator, err := tag.Rat2(0)
+	if err != nil || denominator == 0 {
+		return 0
+	}
+	return float64(numerator) / float64(denominator)
+}
+
+// exifPayload returns the embedded EXIF TIFF block, or nil when there is none
+func exifPayload(data []byte) []byte {
+	format, err := DetectFormat(data)
+	if err != nil {
+		return nil
+	}
+
+	switch format {
+	case FormatJPEG:
+		return jpegEXIFSegment(data)
+	case FormatPNG:
+		return pngChunk(data, "eXIf")
+	case FormatWebP:
+		chunk := webpChunk(data, "EXIF")
+		// Some encoders keep the JPEG APP1 prefix
+		return bytes.TrimPrefix(chunk, exifHeader)
+	default:
+		return nil
+	}
+}
+
+// jpegEXIFSegment returns the TIFF block of the JPEG APP1 EXIF segment, or nil
+func jpegEXIFSegment(data []byte) []byte {
+	offset := 2 // SOI marker
+	for offset+4 <= len(data) && data[offset] == 0xff {
+		marker := data[offset+1]
+		if marker == 0xda || marker == 0xd9 {
+			// Metadata segments precede the image data
+			return nil
+		}
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+		start := offset + 4
+		end := offset + 2 + length
+		if length < 2 || end > len(data) {
+			return nil
+		}
+		if marker == 0xe1 && bytes.HasPrefix(data[start:end], exifHeader) {
+			return data[start+len(exifHeader) : end]
+		}
+		offset = end
+	}
+	return nil
+}
+
+// pngChunk returns the data of the first PNG chunk of the given type, or nil
+func pngChunk(data []byte, chunkType string) []byte {
+	offset := 8 // PNG signature
+	for offset+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[offset:]))
+		name := string(data[offset+4 : offset+8])
+		start := offset + 8
+		end := start + length
+		if length < 0 || end+4 > len(data) {
+			return nil
+		}
+		if name == chunkType {
+			return data[start:end]
+		}
+		if name == "IDAT" || name == "IEND" {
+			// eXIf must precede the image data
+			return nil
+		}
+		offset = end + 4 // Skip the CRC
+	}
+	return nil
+}
+
+// webpChunk returns the data of the first WebP chunk of the given type, or nil
+func webpChunk(data []byte, chunkType string) []byte {
+	offset := 12 // RIFF header
+	for offset+8 <= len(data) {
+		name := string(data[offset : offset+4])
+		length := int(binary.LittleEndian.Uint32(data[offset+4:]))
+		start := offset + 8
+		end := start + length
+		if length < 0 || end > len(data) {
+			return nil
+		}
+		if name == chunkType {
+			return data[start:end]
+		}
+		offset = end + length%2 // Chunks are padded to an even size
+	}
+	return nil
+}
+
+// validCoordinates rejects out-of-range values and the 0,0 placeholder written by some cameras
+func validCoordinates(latitude, longitude float64) bool {
+	if latitude == 0 && longitude == 0 {
+		return false
+	}
+	return latitude >= -90 && latitude <= 90 && longitude >= -180 && longitude <= 180
+}
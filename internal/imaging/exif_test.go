@@ -0,0 +1,253 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"testing"
+	"time"
+)
+
+// tiffEntry is a single IFD entry of a test EXIF block
+type tiffEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+// TIFF field types
+const (
+	tiffASCII    = 2
+	tiffShort    = 3
+	tiffLong     = 4
+	tiffRational = 5
+)
+
+func asciiEntry(tag uint16, value string) tiffEntry {
+	return tiffEntry{tag, tiffASCII, uint32(len(value) + 1), append([]byte(value), 0)}
+}
+
+func shortEntry(tag uint16, value uint16) tiffEntry {
+	return tiffEntry{tag, tiffShort, 1, binary.LittleEndian.AppendUint16(nil, value)}
+}
+
+func longEntry(tag uint16, value uint32) tiffEntry {
+	return tiffEntry{tag, tiffLong, 1, binary.LittleEndian.AppendUint32(nil, value)}
+}
+
+func rationalEntry(tag uint16, values ...[2]uint32) tiffEntry {
+	var data []byte
+	for _, v := range values {
+		data = binary.LittleEndian.AppendUint32(data, v[0])
+		data = binary.LittleEndian.AppendUint32(data, v[1])
+	}
+	return tiffEntry{tag, tiffRational, uint32(len(values)), data}
+}
+
+// degreesEntry encodes decimal degrees as degrees, minutes and seconds
+func degreesEntry(tag uint16, degrees float64) tiffEntry {
+	degrees = math.Abs(degrees)
+	d := math.Floor(degrees)
+	m := math.Floor((degrees - d) * 60)
+	s := ((degrees-d)*60 - m) * 60
+	return rationalEntry(tag, [2]uint32{uint32(d), 1}, [2]uint32{uint32(m), 1}, [2]uint32{uint32(s * 10000), 10000})
+}
+
+// gpsEntries returns GPS IFD entries for the given coordinates
+func gpsEntries(latitude, longitude float64) []tiffEntry {
+	latitudeRef, longitudeRef := "N", "E"
+	if latitude < 0 {
+		latitudeRef = "S"
+	}
+	if longitude < 0 {
+		longitudeRef = "W"
+	}
+	return []tiffEntry{
+		asciiEntry(0x0001, latitudeRef),
+		degreesEntry(0x0002, latitude),
+		asciiEntry(0x0003, longitudeRef),
+		degreesEntry(0x0004, longitude),
+	}
+}
+
+func ifdSize(entries []tiffEntry) int {
+	size := 2 + 12*len(entries) + 4
+	for _, e := range entries {
+		if len(e.value) > 4 {
+			size += len(e.value) + len(e.value)%2
+		}
+	}
+	return size
+}
+
+func writeIFD(buf *bytes.Buffer, entries []tiffEntry, offset int) {
+	dataOffset := offset + 2 + 12*len(entries) + 4
+	var data []byte
+	_ = binary.Write(buf, binary.LittleEndian, uint16(len(entries)))
+	for _, e := range entries {
+		_ = binary.Write(buf, binary.LittleEndian, e.tag)
+		_ = binary.Write(buf, binary.LittleEndian, e.typ)
+		_ = binary.Write(buf, binary.LittleEndian, e.count)
+		if len(e.value) <= 4 {
+			value := make([]byte, 4)
+			copy(value, e.value)
+			buf.Write(value)
+			continue
+		}
+		_ = binary.Write(buf, binary.LittleEndian, uint32(dataOffset+len(data)))
+		data = append(data, e.value...)
+		if len(e.value)%2 == 1 {
+			data = append(data, 0)
+		}
+	}
+	_ = binary.Write(buf, binary.LittleEndian, uint32(0))
+	buf.Write(data)
+}
+
+// buildTIFF builds a little-endian EXIF TIFF block with optional Exif and GPS sub-IFDs
+func buildTIFF(ifd0, exifIFD, gpsIFD []tiffEntry) []byte {
+	ifd0 = append([]tiffEntry(nil), ifd0...)
+	exifPointer, gpsPointer := -1, -1
+	if exifIFD != nil {
+		exifPointer = len(ifd0)
+		ifd0 = append(ifd0, longEntry(0x8769, 0))
+	}
+	if gpsIFD != nil {
+		gpsPointer = len(ifd0)
+		ifd0 = append(ifd0, longEntry(0x8825, 0))
+	}
+
+	exifOffset := 8 + ifdSize(ifd0)
+	gpsOffset := exifOffset
+	if exifIFD != nil {
+		gpsOffset += ifdSize(exifIFD)
+	}
+	if exifPointer >= 0 {
+		ifd0[exifPointer] = longEntry(0x8769, uint32(exifOffset))
+	}
+	if gpsPointer >= 0 {
+		ifd0[gpsPointer] = longEntry(0x8825, uint32(gpsOffset))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("II*\x00")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(8))
+	writeIFD(&buf, ifd0, 8)
+	if exifIFD != nil {
+		writeIFD(&buf, exifIFD, exifOffset)
+	}
+	if gpsIFD != nil {
+		writeIFD(&buf, gpsIFD, gpsOffset)
+	}
+	return buf.Bytes()
+}
+
+// jpegWithEXIF returns a small JPEG carrying the given TIFF block in an APP1 segment
+func jpegWithEXIF(t *testing.T, tiff []byte) []byte {
+	t.Helper()
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, image.NewGray(image.Rect(0, 0, 8, 4)), nil); err != nil {
+		t.Fatalf("Failed to encode JPEG: %v", err)
+	}
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	var buf bytes.Buffer
+	buf.Write([]byte{0xff, 0xd8, 0xff, 0xe1})
+	_ = binary.Write(&buf, binary.BigEndian, uint16(len(payload)+2))
+	buf.Write(payload)
+	buf.Write(encoded.Bytes()[2:])
+	return buf.Bytes()
+}
+
+// pngWithEXIF returns a small PNG carrying the given TIFF block in an eXIf chunk
+func pngWithEXIF(t *testing.T, tiff []byte) []byte {
+	t.Helper()
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, image.NewGray(image.Rect(0, 0, 8, 4))); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	data := encoded.Bytes()
+
+	// Insert the chunk after the signature and IHDR chunk
+	ihdrEnd := 8 + 8 + int(binary.BigEndian.Uint32(data[8:])) + 4
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(tiff)))
+	chunk = append(chunk, "eXIf"...)
+	chunk = append(chunk, tiff...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+
+	result := append([]byte(nil), data[:ihdrEnd]...)
+	result = append(result, chunk...)
+	return append(result, data[ihdrEnd:]...)
+}
+
+func TestReadEXIFLocation(t *testing.T) {
+	tiff := buildTIFF(nil, nil, gpsEntries(47.3769, -8.5417))
+
+	for name, data := range map[string][]byte{
+		"jpeg": jpegWithEXIF(t, tiff),
+		"png":  pngWithEXIF(t, tiff),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := Validate(data); err != nil {
+				t.Fatalf("Test image does not decode: %v", err)
+			}
+
+			metadata, err := ReadEXIF(data)
+			if err != nil {
+				t.Fatalf("ReadEXIF failed: %v", err)
+			}
+			if !metadata.HasLocation {
+				t.Fatal("Expected a GPS location")
+			}
+			if math.Abs(metadata.Latitude-47.3769) > 1e-4 || math.Abs(metadata.Longitude+8.5417) > 1e-4 {
+				t.Errorf("Expected 47.3769,-8.5417, got %v,%v", metadata.Latitude, metadata.Longitude)
+			}
+		})
+	}
+
+	t.Run("no exif", func(t *testing.T) {
+		var encoded bytes.Buffer
+		_ = png.Encode(&encoded, image.NewGray(image.Rect(0, 0, 2, 2)))
+		if _, err := ReadEXIF(encoded.Bytes()); err != ErrNoEXIF {
+			t.Errorf("Expected ErrNoEXIF, got %v", err)
+		}
+	})
+}
+
+func TestReadEXIFCapture(t *testing.T) {
+	tiff := buildTIFF(
+		[]tiffEntry{asciiEntry(0x010f, "FUJIFILM"), asciiEntry(0x0110, "X-T4")},
+		[]tiffEntry{
+			rationalEntry(0x829a, [2]uint32{1, 250}),
+			rationalEntry(0x829d, [2]uint32{28, 10}),
+			shortEntry(0x8827, 400),
+			asciiEntry(0x9003, "2023:07:14 18:32:05"),
+			rationalEntry(0x920a, [2]uint32{35, 1}),
+			asciiEntry(0xa434, "XF35mmF1.4 R"),
+		},
+		nil,
+	)
+
+	metadata, err := ReadEXIF(jpegWithEXIF(t, tiff))
+	if err != nil {
+		t.Fatalf("ReadEXIF failed: %v", err)
+	}
+
+	if metadata.HasLocation {
+		t.Error("Expected no GPS location")
+	}
+	if metadata.TakenAt == nil || !metadata.TakenAt.Equal(time.Date(2023, 7, 14, 18, 32, 5, 0, time.UTC)) {
+		t.Errorf("Unexpected capture time %v", metadata.TakenAt)
+	}
+	if metadata.CameraMake != "FUJIFILM" || metadata.CameraModel != "X-T4" || metadata.LensModel != "XF35mmF1.4 R" {
+		t.Errorf("Unexpected camera %q %q %q", metadata.CameraMake, metadata.CameraModel, metadata.LensModel)
+	}
+	if metadata.FocalLengthMM != 35 || metadata.Aperture != 2.8 || metadata.ISO != 400 || metadata.ExposureTime != 0.004 {
+		t.Errorf("Unexpected exposure settings %+v", metadata)
+	}
+}
@@ -0,0 +1,142 @@
+// Package imaging decodes, resizes and encodes images using pure-Go codecs
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+
+	// Register the WebP decoder for image.Decode
+	_ "golang.org/x/image/webp"
+)
+
+// DefaultJPEGQuality is used when no quality is configured
+const DefaultJPEGQuality = 82
+
+var (
+	// ErrUnsupportedFormat is returned for data that is not JPEG, PNG, GIF or WebP
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	// ErrInvalidImage is returned for data that looks like a supported format but does not decode
+	ErrInvalidImage = errors.New("invalid image data")
+)
+
+// Format describes a supported image format
+type Format struct {
+	// Name is the format name registered with the image package
+	Name      string
+	MimeType  string
+	Extension string
+}
+
+// Supported image formats
+var (
+	FormatJPEG = Format{Name: "jpeg", MimeType: "image/jpeg", Extension: ".jpg"}
+	FormatPNG  = Format{Name: "png", MimeType: "image/png", Extension: ".png"}
+	FormatGIF  = Format{Name: "gif", MimeType: "image/gif", Extension: ".gif"}
+	FormatWebP = Format{Name: "webp", MimeType: "image/webp", Extension: ".webp"}
+)
+
+// DetectFormat identifies the image format from the leading bytes of data
+func DetectFormat(data []byte) (Format, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		return FormatJPEG, nil
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return FormatPNG, nil
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return FormatGIF, nil
+	case len(data) >= 12 && bytes.Equal(data[:4], []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WEBP")):
+		return FormatWebP, nil
+	default:
+		return Format{}, ErrUnsupportedFormat
+	}
+}
+
+// Validate detects the format of data and fully decodes it, so truncated or
+// corrupt files are rejected before they are stored. It returns the decoded
+// image (the first frame of an animated GIF) and its format.
+func Validate(data []byte) (image.Image, Format, error) {
+	format, err := DetectFormat(data)
+	if err != nil {
+		return nil, Format{}, err
+	}
+
+	// All GIF frames are decoded; image.Decode would only read the first one
+	if format == FormatGIF {
+		animation, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, Format{}, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+		}
+		if len(animation.Image) == 0 {
+			return nil, Format{}, fmt.Errorf("%w: no frames", ErrInvalidImage)
+		}
+		return animation.Image[0], format, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, Format{}, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	return img, format, nil
+}
+
+// Decode decodes JPEG, PNG, GIF or WebP data and returns the image and its format name
+func Decode(data []byte) (image.Image, string, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode image: %v", err)
+	}
+	return img, format, nil
+}
+
+// ResizeToWidth scales img to the given width, keeping the aspect ratio
+func ResizeToWidth(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	if width <= 0 || bounds.Dx() == 0 {
+		return img
+	}
+
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	resized := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(resized, resized.Bounds(), img, bounds, draw.Src, nil)
+	return resized
+}
+
+// Encode encodes img as JPEG, or as PNG when it has transparency, and returns
+// the encoded bytes with their format
+func Encode(img image.Image, quality int) ([]byte, Format, error) {
+	if quality <= 0 || quality > 100 {
+		quality = DefaultJPEGQuality
+	}
+
+	var buf bytes.Buffer
+	if isOpaque(img) {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, Format{}, fmt.Errorf("failed to encode JPEG: %v", err)
+		}
+		return buf.Bytes(), FormatJPEG, nil
+	}
+
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, Format{}, fmt.Errorf("failed to encode PNG: %v", err)
+	}
+	return buf.Bytes(), FormatPNG, nil
+}
+
+// isOpaque reports whether img has no transparent pixels
+func isOpaque(img image.Image) bool {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok {
+		return opaque.Opaque()
+	}
+	return true
+}
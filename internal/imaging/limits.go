@@ -0,0 +1,119 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+)
+
+// Names of the limits reported in LimitError
+const (
+	LimitBytes  = "bytes"
+	LimitPixels = "pixels"
+	LimitFrames = "frames"
+	// LimitTotalPixels bounds the pixels of all frames together
+	LimitTotalPixels = "total_pixels"
+)
+
+// ErrLimitExceeded is wrapped by every LimitError
+var ErrLimitExceeded = errors.New("image exceeds a configured limit")
+
+// Limits bounds the size of accepted images; zero fields are not enforced
+type Limits struct {
+	// MaxBytes is the largest accepted encoded size
+	MaxBytes int64
+	// MaxPixels is the largest accepted width × height
+	MaxPixels int64
+	// MaxFrames is the largest accepted number of GIF frames
+	MaxFrames int
+	// MaxTotalPixels is the largest accepted sum of width × height over all
+	// frames, which bounds the work of decoding an animated GIF
+	MaxTotalPixels int64
+}
+
+// LimitError reports which limit an image exceeds
+type LimitError struct {
+	// Limit is LimitBytes, LimitPixels, LimitFrames or LimitTotalPixels
+	Limit  string
+	Actual int64
+	Max    int64
+}
+
+func (e *LimitError) Error() string {
+	if e.Actual <= 0 {
+		// The size of streamed uploads is not known
+		return fmt.Sprintf("image exceeds the limit of %d %s", e.Max, e.Limit)
+	}
+	return fmt.Sprintf("image has %d %s, the limit is %d", e.Actual, e.Limit, e.Max)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// CheckLimits enforces limits using only the size of data and the image
+// headers, so oversized images are rejected before they are decoded. Data that
+// cannot be parsed is left for Validate to reject.
+func CheckLimits(data []byte, limits Limits) error {
+	if limits.MaxBytes > 0 && int64(len(data)) > limits.MaxBytes {
+		return &LimitError{Limit: LimitBytes, Actual: int64(len(data)), Max: limits.MaxBytes}
+	}
+
+	format, err := DetectFormat(data)
+	if err != nil {
+		return nil
+	}
+
+	if limits.MaxPixels > 0 {
+		config, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil
+		}
+		if pixels := int64(config.Width) * int64(config.Height); pixels > limits.MaxPixels {
+			return &LimitError{Limit: LimitPixels, Actual: pixels, Max: limits.MaxPixels}
+		}
+	}
+
+	if format == FormatGIF && (limits.MaxFrames > 0 || limits.MaxTotalPixels > 0) {
+		_, blocks, err := gifBlocks(data)
+		if err != nil {
+			return nil
+		}
+		frames, totalPixels := 0, int64(0)
+		for _, block := range blocks {
+			if block.introducer == gifImageDescriptor {
+				frames++
+				totalPixels += gifFramePixels(block)
+			}
+		}
+		if limits.MaxFrames > 0 && frames > limits.MaxFrames {
+			return &LimitError{Limit: LimitFrames, Actual: int64(frames), Max: int64(limits.MaxFrames)}
+		}
+		if limits.MaxTotalPixels > 0 && totalPixels > limits.MaxTotalPixels {
+			return &LimitError{Limit: LimitTotalPixels, Actual: totalPixels, Max: limits.MaxTotalPixels}
+		}
+		return nil
+	}
+
+	// Other formats hold a single frame
+	if limits.MaxTotalPixels > 0 {
+		config, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil
+		}
+		if pixels := int64(config.Width) * int64(config.Height); pixels > limits.MaxTotalPixels {
+			return &LimitError{Limit: LimitTotalPixels, Actual: pixels, Max: limits.MaxTotalPixels}
+		}
+	}
+
+	return nil
+}
+
+// gifFramePixels returns the width × height of a GIF image block, read from
+// its image descriptor
+func gifFramePixels(block gifBlock) int64 {
+	width := int64(block.data[5]) | int64(block.data[6])<<8
+	height := int64(block.data[7]) | int64(block.data[8])<<8
+	return width * height
+}
@@ -0,0 +1,89 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+// pngHeader returns the signature and IHDR chunk of a PNG claiming the given size, without any pixel data
+func pngHeader(width, height uint32) []byte {
+	ihdr := []byte("IHDR")
+	ihdr = binary.BigEndian.AppendUint32(ihdr, width)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, height)
+	ihdr = append(ihdr, 8, 2, 0, 0, 0) // 8-bit RGB
+
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = binary.BigEndian.AppendUint32(data, uint32(len(ihdr)-4))
+	data = append(data, ihdr...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(ihdr))
+}
+
+func TestCheckLimits(t *testing.T) {
+	var small bytes.Buffer
+	_ = png.Encode(&small, image.NewGray(image.Rect(0, 0, 10, 10)))
+
+	palette := color.Palette{color.Black, color.White}
+	animation := &gif.GIF{}
+	for i := 0; i < 3; i++ {
+		animation.Image = append(animation.Image, image.NewPaletted(image.Rect(0, 0, 4, 4), palette))
+		animation.Delay = append(animation.Delay, 10)
+	}
+	var animated bytes.Buffer
+	if err := gif.EncodeAll(&animated, animation); err != nil {
+		t.Fatalf("Failed to encode GIF: %v", err)
+	}
+
+	// Blank frames compress to almost nothing but decode to 40 million pixels
+	bomb := &gif.GIF{}
+	for i := 0; i < 40; i++ {
+		bomb.Image = append(bomb.Image, image.NewPaletted(image.Rect(0, 0, 1000, 1000), palette))
+		bomb.Delay = append(bomb.Delay, 10)
+	}
+	var frameBomb bytes.Buffer
+	if err := gif.EncodeAll(&frameBomb, bomb); err != nil {
+		t.Fatalf("Failed to encode GIF: %v", err)
+	}
+	if frameBomb.Len() > 200<<10 {
+		t.Fatalf("Expected a small GIF, got %d bytes", frameBomb.Len())
+	}
+
+	tests := []struct {
+		name   string
+		data   []byte
+		limits Limits
+		limit  string
+	}{
+		{"within limits", small.Bytes(), Limits{MaxBytes: 1 << 20, MaxPixels: 100, MaxFrames: 1}, ""},
+		{"bytes", small.Bytes(), Limits{MaxBytes: 10}, LimitBytes},
+		{"pixels", small.Bytes(), Limits{MaxPixels: 99}, LimitPixels},
+		{"decompression bomb", pngHeader(100000, 100000), Limits{MaxPixels: 50_000_000}, LimitPixels},
+		{"frames", animated.Bytes(), Limits{MaxFrames: 2}, LimitFrames},
+		{"total pixels", frameBomb.Bytes(), Limits{MaxPixels: 1_000_000, MaxFrames: 300, MaxTotalPixels: 10_000_000}, LimitTotalPixels},
+		{"total pixels of one frame", small.Bytes(), Limits{MaxTotalPixels: 99}, LimitTotalPixels},
+		{"frames within total", animated.Bytes(), Limits{MaxTotalPixels: 48}, ""},
+		{"unlimited", animated.Bytes(), Limits{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckLimits(tt.data, tt.limits)
+			if tt.limit == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			var limitErr *LimitError
+			if !errors.As(err, &limitErr) || limitErr.Limit != tt.limit || !errors.Is(err, ErrLimitExceeded) {
+				t.Errorf("Expected the %s limit to be exceeded, got %v", tt.limit, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// DefaultPaletteSize is the number of colors extracted for a palette
+const DefaultPaletteSize = 6
+
+// Recommended foreground colors for text drawn on an image
+const (
+	ForegroundLight = "light"
+	ForegroundDark  = "dark"
+)
+
+// paletteSampleSize bounds the number of pixels sampled for color analysis
+const paletteSampleSize = 10000
+
+// ColorTheme describes the colors of an image
+type ColorTheme struct {
+	// Dominant is the color covering the largest part of the image
+	Dominant color.RGBA
+	// Palette lists the main colors, most common first
+	Palette []color.RGBA
+	// AverageLuminance is the mean relative luminance between 0 (black) and 1 (white)
+	AverageLuminance float64
+	// Foreground is ForegroundLight or ForegroundDark, whichever contrasts more with the image
+	Foreground string
+}
+
+// AnalyzeColors extracts a palette of up to size colors with median cut and
+// computes the average luminance of img. Transparent pixels are ignored.
+func AnalyzeColors(img image.Image, size int) ColorTheme {
+	if size <= 0 {
+		size = DefaultPaletteSize
+	}
+
+	pixels := samplePixels(img)
+	theme := ColorTheme{Foreground: ForegroundDark}
+	if len(pixels) == 0 {
+		return theme
+	}
+
+	var luminance float64
+	for _, p := range pixels {
+		luminance += relativeLuminance(p)
+	}
+	theme.AverageLuminance = luminance / float64(len(pixels))
+
+	// Use light text when white contrasts more with the image than black (WCAG contrast ratio)
+	if 1.05/(theme.AverageLuminance+0.05) >= (theme.AverageLuminance+0.05)/0.05 {
+		theme.Foreground = ForegroundLight
+	}
+
+	boxes := medianCut(pixels, size)
+	sort.SliceStable(boxes, func(i, j int) bool { return len(boxes[i]) > len(boxes[j]) })
+	for _, box := range boxes {
+		theme.Palette = append(theme.Palette, averageColor(box))
+	}
+	theme.Dominant = theme.Palette[0]
+
+	return theme
+}
+
+// HexColor formats c as #rrggbb
+func HexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// samplePixels returns about paletteSampleSize opaque pixels spread evenly over img
+func samplePixels(img image.Image) []color.RGBA {
+	bounds := img.Bounds()
+	step := int(math.Sqrt(float64(bounds.Dx()*bounds.Dy()) / paletteSampleSize))
+	if step < 1 {
+		step = 1
+	}
+
+	var pixels []color.RGBA
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < 128 {
+				continue
+			}
+			pixels = append(pixels, color.RGBA{R: c.R, G: c.G, B: c.B, A: 255})
+		}
+	}
+	return pixels
+}
+
+// medianCut splits pixels into at most size boxes, each time dividing the box
+// with the widest channel range near the median of that channel
+func medianCut(pixels []color.RGBA, size int) [][]color.RGBA {
+	boxes := [][]color.RGBA{pixels}
+	for len(boxes) < size {
+		widest, channel, widestRange := -1, 0, 0
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			if c, r := widestChannel(box); r > widestRange {
+				widest, channel, widestRange = i, c, r
+			}
+		}
+		if widest < 0 {
+			// Every remaining box holds a single color
+			break
+		}
+
+		box := boxes[widest]
+		sort.Slice(box, func(i, j int) bool { return channelValue(box[i], channel) < channelValue(box[j], channel) })
+		middle := splitIndex(box, channel)
+		boxes[widest] = box[:middle]
+		boxes = append(boxes, box[middle:])
+	}
+	return boxes
+}
+
+// splitIndex returns the index closest to the median of a box sorted by channel
+// that does not separate pixels with the same channel value
+func splitIndex(box []color.RGBA, channel int) int {
+	middle := len(box) / 2
+	for i := 0; i < len(box); i++ {
+		for _, index := range []int{middle + i, middle - i} {
+			if index > 0 && index < len(box) && channelValue(box[index-1], channel) != channelValue(box[index], channel) {
+				return index
+			}
+		}
+	}
+	return middle
+}
+
+// widestChannel returns the RGB channel (0-2) with the largest range in box and that range
+func widestChannel(box []color.RGBA) (int, int) {
+	channel, widest := 0, 0
+	for c := 0; c < 3; c++ {
+		low, high := 255, 0
+		for _, p := range box {
+			v := int(channelValue(p, c))
+			low = min(low, v)
+			high = max(high, v)
+		}
+		if high-low > widest {
+			channel, widest = c, high-low
+		}
+	}
+	return channel, widest
+}
+
+func channelValue(c color.RGBA, channel int) uint8 {
+	switch channel {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	default:
+		return c.B
+	}
+}
+
+// averageColor returns the mean color of box
+func averageColor(box []color.RGBA) color.RGBA {
+	var r, g, b int
+	for _, p := range box {
+		r += int(p.R)
+		g += int(p.G)
+		b += int(p.B)
+	}
+	n := len(box)
+	return color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: 255}
+}
+
+// relativeLuminance returns the WCAG relative luminance of an sRGB color
+func relativeLuminance(c color.RGBA) float64 {
+	return 0.2126*srgbToLinear(c.R) + 0.7152*srgbToLinear(c.G) + 0.0722*srgbToLinear(c.B)
+}
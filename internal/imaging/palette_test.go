@@ -0,0 +1,34 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestAnalyzeColors(t *testing.T) {
+	// Three quarters navy, one quarter orange
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	draw.Draw(img, img.Bounds(), &image.Uniform{color.RGBA{R: 16, G: 32, B: 96, A: 255}}, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 30, 40, 40), &image.Uniform{color.RGBA{R: 240, G: 140, B: 20, A: 255}}, image.Point{}, draw.Src)
+
+	theme := AnalyzeColors(img, DefaultPaletteSize)
+
+	if got := HexColor(theme.Dominant); got != "#102060" {
+		t.Errorf("Expected dominant color #102060, got %s", got)
+	}
+	if len(theme.Palette) != 2 {
+		t.Errorf("Expected 2 palette colors, got %d", len(theme.Palette))
+	}
+	if theme.Foreground != ForegroundLight {
+		t.Errorf("Expected a light foreground on a dark image, got %s", theme.Foreground)
+	}
+
+	white := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	draw.Draw(white, white.Bounds(), image.White, image.Point{}, draw.Src)
+	light := AnalyzeColors(white, DefaultPaletteSize)
+	if light.Foreground != ForegroundDark || light.AverageLuminance < 0.99 {
+		t.Errorf("Expected a dark foreground for white, got %s (luminance %v)", light.Foreground, light.AverageLuminance)
+	}
+}
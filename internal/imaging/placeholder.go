@@ -0,0 +1,150 @@
+package imaging
+
+import (
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+)
+
+// LQIPWidth is the width of low-quality image placeholders
+const LQIPWidth = 16
+
+// lqipQuality is the JPEG quality of low-quality image placeholders
+const lqipQuality = 50
+
+// blurHashSampleWidth is the width images are scaled to before computing a BlurHash
+const blurHashSampleWidth = 64
+
+// blurHashCharacters is the base 83 alphabet of BlurHash strings
+const blurHashCharacters = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// BlurHash encodes img as a BlurHash string (https://blurha.sh) with 4x3
+// components, or 3x4 for portrait images
+func BlurHash(img image.Image) string {
+	xComponents, yComponents := 4, 3
+	if bounds := img.Bounds(); bounds.Dy() > bounds.Dx() {
+		xComponents, yComponents = 3, 4
+	}
+	if img.Bounds().Dx() > blurHashSampleWidth {
+		img = ResizeToWidth(img, blurHashSampleWidth)
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return ""
+	}
+
+	// Convert once to linear RGB
+	pixels := make([][3]float64, 0, width*height)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			pixels = append(pixels, [3]float64{srgbToLinear(c.R), srgbToLinear(c.G), srgbToLinear(c.B)})
+		}
+	}
+
+	factors := make([][3]float64, 0, xComponents*yComponents)
+	for j := 0; j < yComponents; j++ {
+		for i := 0; i < xComponents; i++ {
+			normalisation := 2.0
+			if i == 0 && j == 0 {
+				normalisation = 1
+			}
+			var factor [3]float64
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					basis := normalisation *
+						math.Cos(math.Pi*float64(i)*float64(x)/float64(width)) *
+						math.Cos(math.Pi*float64(j)*float64(y)/float64(height))
+					p := pixels[y*width+x]
+					factor[0] += basis * p[0]
+					factor[1] += basis * p[1]
+					factor[2] += basis * p[2]
+				}
+			}
+			scale := 1 / float64(width*height)
+			factors = append(factors, [3]float64{factor[0] * scale, factor[1] * scale, factor[2] * scale})
+		}
+	}
+
+	var hash strings.Builder
+	hash.WriteString(encodeBase83((xComponents-1)+(yComponents-1)*9, 1))
+
+	maximumValue := 1.0
+	ac := factors[1:]
+	if len(ac) > 0 {
+		actualMaximum := 0.0
+		for _, factor := range ac {
+			for _, v := range factor {
+				actualMaximum = math.Max(actualMaximum, math.Abs(v))
+			}
+		}
+		quantisedMaximum := clamp(int(math.Floor(actualMaximum*166-0.5)), 0, 82)
+		maximumValue = float64(quantisedMaximum+1) / 166
+		hash.WriteString(encodeBase83(quantisedMaximum, 1))
+	} else {
+		hash.WriteString(encodeBase83(0, 1))
+	}
+
+	dc := factors[0]
+	hash.WriteString(encodeBase83(linearToSRGB(dc[0])<<16+linearToSRGB(dc[1])<<8+linearToSRGB(dc[2]), 4))
+
+	for _, factor := range ac {
+		quantise := func(v float64) int {
+			return clamp(int(math.Floor(signedPow(v/maximumValue, 0.5)*9+9.5)), 0, 18)
+		}
+		hash.WriteString(encodeBase83(quantise(factor[0])*19*19+quantise(factor[1])*19+quantise(factor[2]), 2))
+	}
+
+	return hash.String()
+}
+
+// LQIP returns a tiny JPEG (or PNG for transparent images) rendition of img as a data URI
+func LQIP(img image.Image) (string, error) {
+	data, format, err := Encode(ResizeToWidth(img, LQIPWidth), lqipQuality)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode placeholder: %v", err)
+	}
+	return "data:" + format.MimeType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// encodeBase83 encodes value as length base 83 digits
+func encodeBase83(value, length int) string {
+	digits := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		digits[i] = blurHashCharacters[value%83]
+		value /= 83
+	}
+	return string(digits)
+}
+
+// srgbToLinear converts an sRGB channel value to linear light
+func srgbToLinear(v uint8) float64 {
+	s := float64(v) / 255
+	if s <= 0.04045 {
+		return s / 12.92
+	}
+	return math.Pow((s+0.055)/1.055, 2.4)
+}
+
+// linearToSRGB converts linear light to an sRGB channel value
+func linearToSRGB(v float64) int {
+	v = math.Max(0, math.Min(1, v))
+	if v <= 0.0031308 {
+		return int(math.Round(v * 12.92 * 255))
+	}
+	return int(math.Round((1.055*math.Pow(v, 1/2.4) - 0.055) * 255))
+}
+
+// signedPow raises the magnitude of v to exp, keeping its sign
+func signedPow(v, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
+
+func clamp(v, low, high int) int {
+	return max(low, min(high, v))
+}
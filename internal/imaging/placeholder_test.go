@@ -0,0 +1,53 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+)
+
+func TestBlurHash(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 120, 80))
+	draw.Draw(img, img.Bounds(), &image.Uniform{color.RGBA{R: 255, G: 0, B: 0, A: 255}}, image.Point{}, draw.Src)
+
+	// Size flag for 4x3 components, one maximum AC digit, four DC digits and two digits per AC component
+	hash := BlurHash(img)
+	if len(hash) != 28 || hash[0] != 'L' {
+		t.Fatalf("Expected a 4x3 hash, got %s", hash)
+	}
+	if dc := hash[2:6]; dc != encodeBase83(255<<16, 4) {
+		t.Errorf("Expected the average color to be red, got %s", dc)
+	}
+
+	portrait := image.NewRGBA(image.Rect(0, 0, 40, 80))
+	if hash := BlurHash(portrait); len(hash) != 28 || hash[0] != encodeBase83(2+3*9, 1)[0] {
+		t.Errorf("Expected a 3x4 hash, got %s", hash)
+	}
+}
+
+func TestLQIP(t *testing.T) {
+	lqip, err := LQIP(image.NewRGBA(image.Rect(0, 0, 400, 300)))
+	if err != nil {
+		t.Fatalf("LQIP failed: %v", err)
+	}
+
+	encoded, ok := strings.CutPrefix(lqip, "data:image/png;base64,")
+	if !ok {
+		t.Fatalf("Expected a PNG data URI for a transparent image, got %.40s", lqip)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("Invalid base64: %v", err)
+	}
+	placeholder, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Placeholder does not decode: %v", err)
+	}
+	if bounds := placeholder.Bounds(); bounds.Dx() != LQIPWidth || bounds.Dy() != 12 {
+		t.Errorf("Expected %dx12, got %dx%d", LQIPWidth, bounds.Dx(), bounds.Dy())
+	}
+}
@@ -0,0 +1,343 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+)
+
+// sanitizedJPEGQuality is used when a JPEG has to be re-encoded to apply its orientation
+const sanitizedJPEGQuality = 92
+
+// Sanitize returns a copy of an image without EXIF, XMP, IPTC or text metadata.
+// img is the decoded image. Images carrying an EXIF orientation are turned
+// upright and re-encoded; all others are stripped losslessly. The upright image
+// and the format of the result are returned with it.
+func Sanitize(data []byte, img image.Image, format Format) ([]byte, image.Image, Format, error) {
+	orientation := 1
+	if metadata, err := ReadEXIF(data); err == nil {
+		orientation = metadata.Orientation
+	}
+
+	if orientation > 1 {
+		upright := Orient(img, orientation)
+		encoded, encodedFormat, err := encodeAs(upright, format)
+		if err != nil {
+			return nil, nil, Format{}, err
+		}
+		return encoded, upright, encodedFormat, nil
+	}
+
+	stripped, err := StripMetadata(data, format)
+	if err != nil {
+		return nil, nil, Format{}, err
+	}
+	return stripped, img, format, nil
+}
+
+// StripMetadata removes metadata from encoded image data without re-encoding
+// the pixels. ICC color profiles are kept.
+func StripMetadata(data []byte, format Format) ([]byte, error) {
+	switch format {
+	case FormatJPEG:
+		return stripJPEG(data)
+	case FormatPNG:
+		return stripPNG(data)
+	case FormatWebP:
+		return stripWebP(data)
+	case FormatGIF:
+		return stripGIF(data)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// Orient turns img upright according to an EXIF orientation value
+func Orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+	width, height := bounds.Dx(), bounds.Dy()
+
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		// Orientations 5-8 swap the axes
+		dstWidth, dstHeight = height, width
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < dstHeight; y++ {
+		for x := 0; x < dstWidth; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // Mirrored horizontally
+				sx, sy = width-1-x, y
+			case 3: // Rotated 180°
+				sx, sy = width-1-x, height-1-y
+			case 4: // Mirrored vertically
+				sx, sy = x, height-1-y
+			case 5: // Transposed
+				sx, sy = y, x
+			case 6: // Needs a 90° clockwise turn
+				sx, sy = y, height-1-x
+			case 7: // Transversed
+				sx, sy = width-1-y, height-1-x
+			case 8: // Needs a 90° counter-clockwise turn
+				sx, sy = width-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
+
+// encodeAs encodes img in format when an encoder is available, otherwise as
+// JPEG or PNG depending on transparency
+func encodeAs(img image.Image, format Format) ([]byte, Format, error) {
+	var buf bytes.Buffer
+	switch format {
+	case FormatJPEG:
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: sanitizedJPEGQuality}); err != nil {
+			return nil, Format{}, fmt.Errorf("failed to encode JPEG: %v", err)
+		}
+		return buf.Bytes(), FormatJPEG, nil
+	case FormatPNG:
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, Format{}, fmt.Errorf("failed to encode PNG: %v", err)
+		}
+		return buf.Bytes(), FormatPNG, nil
+	default:
+		return Encode(img, sanitizedJPEGQuality)
+	}
+}
+
+// stripJPEG drops APP and COM segments other than JFIF, ICC profiles and Adobe
+// color information, and any data after the end of the image (e.g. MPF pictures)
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 {
+		return nil, ErrInvalidImage
+	}
+
+	out := append([]byte(nil), data[:2]...) // SOI
+	offset := 2
+	for {
+		if offset+4 > len(data) || data[offset] != 0xff {
+			return nil, fmt.Errorf("%w: malformed JPEG segment", ErrInvalidImage)
+		}
+		marker := data[offset+1]
+		if marker == 0xff {
+			// Fill byte
+			offset++
+			continue
+		}
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+		end := offset + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, fmt.Errorf("%w: truncated JPEG segment", ErrInvalidImage)
+		}
+
+		if marker == 0xda {
+			// Start of scan: copy the image data up to and including the end marker
+			eoi := bytes.Index(data[end:], []byte{0xff, 0xd9})
+			if eoi < 0 {
+				return append(out, data[offset:]...), nil
+			}
+			return append(out, data[offset:end+eoi+2]...), nil
+		}
+
+		payload := data[offset+4 : end]
+		keep := true
+		switch {
+		case marker == 0xe0: // JFIF
+		case marker == 0xe2:
+			keep = bytes.HasPrefix(payload, []byte("ICC_PROFILE\x00"))
+		case marker == 0xee: // Adobe
+		case marker >= 0xe1 && marker <= 0xef, marker == 0xfe:
+			keep = false
+		}
+		if keep {
+			out = append(out, data[offset:end]...)
+		}
+		offset = end
+	}
+}
+
+// stripPNG drops the eXIf, text and tIME chunks
+func stripPNG(data []byte) ([]byte, error) {
+	if len(data) < 8 {
+		return nil, ErrInvalidImage
+	}
+
+	out := append([]byte(nil), data[:8]...) // Signature
+	offset := 8
+	for offset < len(data) {
+		if offset+12 > len(data) {
+			return nil, fmt.Errorf("%w: truncated PNG chunk", ErrInvalidImage)
+		}
+		length := int(binary.BigEndian.Uint32(data[offset:]))
+		name := string(data[offset+4 : offset+8])
+		end := offset + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, fmt.Errorf("%w: truncated PNG chunk", ErrInvalidImage)
+		}
+
+		switch name {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+		default:
+			out = append(out, data[offset:end]...)
+		}
+		offset = end
+		if name == "IEND" {
+			break
+		}
+	}
+	return out, nil
+}
+
+// stripWebP drops the EXIF and XMP chunks and clears their VP8X flags
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 {
+		return nil, ErrInvalidImage
+	}
+
+	out := append([]byte(nil), data[:12]...) // RIFF header, size fixed below
+	offset := 12
+	for offset+8 <= len(data) {
+		name := string(data[offset : offset+4])
+		length := int(binary.LittleEndian.Uint32(data[offset+4:]))
+		end := offset + 8 + length + length%2
+		if length < 0 || offset+8+length > len(data) {
+			return nil, fmt.Errorf("%w: truncated WebP chunk", ErrInvalidImage)
+		}
+		end = min(end, len(data))
+
+		switch name {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte(nil), data[offset:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= 0x08 | 0x04 // EXIF and XMP present flags
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[offset:end]...)
+		}
+		offset = end
+	}
+
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
+
+// stripGIF drops comment extensions and application extensions other than
+// the looping (NETSCAPE2.0) extension
+func stripGIF(data []byte) ([]byte, error) {
+	header, blocks, err := gifBlocks(data)
+	if err != nil {
+		return nil, err
+	}
+
+	out := append([]byte(nil), header...)
+	for _, block := range blocks {
+		if block.introducer == gifExtension {
+			switch block.data[1] {
+			case 0xfe: // Comment
+				continue
+			case 0xff: // Application
+				if !bytes.HasPrefix(block.data[2:], []byte("\x0bNETSCAPE2.0")) {
+					continue
+				}
+			}
+		}
+		out = append(out, block.data...)
+	}
+	return append(out, gifTrailer), nil
+}
+
+// GIF block introducers
+const (
+	gifExtension       = 0x21
+	gifImageDescriptor = 0x2c
+	gifTrailer         = 0x3b
+)
+
+// gifBlock is an extension or an image (descriptor, color table and data) of a GIF
+type gifBlock struct {
+	introducer byte
+	// data holds the complete block including the introducer
+	data []byte
+}
+
+// gifBlocks splits GIF data into the header (including the global color
+// table) and the blocks before the trailer, without decoding any pixels
+func gifBlocks(data []byte) ([]byte, []gifBlock, error) {
+	if len(data) < 13 {
+		return nil, nil, ErrInvalidImage
+	}
+
+	// Header, logical screen descriptor and global color table
+	offset := 13
+	if flags := data[10]; flags&0x80 != 0 {
+		offset += 3 << (flags&0x07 + 1)
+	}
+	if offset > len(data) {
+		return nil, nil, fmt.Errorf("%w: truncated GIF header", ErrInvalidImage)
+	}
+	header := data[:offset]
+
+	// subBlocksEnd returns the offset after the data sub-blocks starting at start
+	subBlocksEnd := func(start int) (int, error) {
+		for start < len(data) {
+			size := int(data[start])
+			start += 1 + size
+			if size == 0 {
+				return start, nil
+			}
+		}
+		return 0, fmt.Errorf("%w: truncated GIF block", ErrInvalidImage)
+	}
+
+	var blocks []gifBlock
+	for offset < len(data) {
+		start := offset
+		switch data[offset] {
+		case gifTrailer:
+			return header, blocks, nil
+		case gifExtension:
+			if offset+2 > len(data) {
+				return nil, nil, fmt.Errorf("%w: truncated GIF extension", ErrInvalidImage)
+			}
+			end, err := subBlocksEnd(offset + 2)
+			if err != nil {
+				return nil, nil, err
+			}
+			offset = end
+		case gifImageDescriptor:
+			offset += 10
+			if offset > len(data) {
+				return nil, nil, fmt.Errorf("%w: truncated GIF image", ErrInvalidImage)
+			}
+			if flags := data[offset-1]; flags&0x80 != 0 {
+				offset += 3 << (flags&0x07 + 1)
+			}
+			end, err := subBlocksEnd(offset + 1) // Skip the LZW minimum code size
+			if err != nil {
+				return nil, nil, err
+			}
+			offset = end
+		default:
+			return nil, nil, fmt.Errorf("%w: unexpected GIF block 0x%02x", ErrInvalidImage, data[offset])
+		}
+		blocks = append(blocks, gifBlock{introducer: data[start], data: data[start:offset]})
+	}
+	// Missing trailer
+	return header, blocks, nil
+}
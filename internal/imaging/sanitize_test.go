@@ -0,0 +1,99 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestSanitizeStripsMetadata(t *testing.T) {
+	tiff := buildTIFF([]tiffEntry{asciiEntry(0x010f, "FUJIFILM")}, nil, gpsEntries(47.3769, 8.5417))
+
+	for name, data := range map[string][]byte{
+		"jpeg": jpegWithEXIF(t, tiff),
+		"png":  pngWithEXIF(t, tiff),
+	} {
+		t.Run(name, func(t *testing.T) {
+			img, format, err := Validate(data)
+			if err != nil {
+				t.Fatalf("Test image does not decode: %v", err)
+			}
+
+			sanitized, upright, sanitizedFormat, err := Sanitize(data, img, format)
+			if err != nil {
+				t.Fatalf("Sanitize failed: %v", err)
+			}
+			if sanitizedFormat != format || upright != img {
+				t.Errorf("Expected the image to be stripped without re-encoding")
+			}
+			if bytes.Contains(sanitized, []byte("FUJIFILM")) {
+				t.Error("Expected the EXIF data to be removed")
+			}
+			if _, err := ReadEXIF(sanitized); err != ErrNoEXIF {
+				t.Errorf("Expected ErrNoEXIF for the sanitized image, got %v", err)
+			}
+			decoded, _, err := Validate(sanitized)
+			if err != nil {
+				t.Fatalf("Sanitized image does not decode: %v", err)
+			}
+			if decoded.Bounds() != img.Bounds() {
+				t.Errorf("Expected bounds %v, got %v", img.Bounds(), decoded.Bounds())
+			}
+		})
+	}
+}
+
+func TestSanitizeOrients(t *testing.T) {
+	// Orientation 6: the stored 8x4 image needs a quarter turn clockwise
+	data := jpegWithEXIF(t, buildTIFF([]tiffEntry{shortEntry(0x0112, 6)}, nil, nil))
+	img, format, err := Validate(data)
+	if err != nil {
+		t.Fatalf("Test image does not decode: %v", err)
+	}
+
+	sanitized, upright, sanitizedFormat, err := Sanitize(data, img, format)
+	if err != nil {
+		t.Fatalf("Sanitize failed: %v", err)
+	}
+	if sanitizedFormat != FormatJPEG {
+		t.Errorf("Expected a JPEG, got %s", sanitizedFormat.Name)
+	}
+	if size := upright.Bounds().Size(); size != image.Pt(4, 8) {
+		t.Errorf("Expected an upright 4x8 image, got %v", size)
+	}
+	if _, err := ReadEXIF(sanitized); err != ErrNoEXIF {
+		t.Errorf("Expected ErrNoEXIF for the sanitized image, got %v", err)
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// 2x1 image: red on the left, blue on the right
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, red)
+	img.Set(1, 0, blue)
+
+	tests := []struct {
+		orientation int
+		size        image.Point
+		red         image.Point
+	}{
+		{1, image.Pt(2, 1), image.Pt(0, 0)},
+		{2, image.Pt(2, 1), image.Pt(1, 0)},
+		{3, image.Pt(2, 1), image.Pt(1, 0)},
+		{6, image.Pt(1, 2), image.Pt(0, 0)},
+		{8, image.Pt(1, 2), image.Pt(0, 1)},
+	}
+	for _, tt := range tests {
+		oriented := Orient(img, tt.orientation)
+		if size := oriented.Bounds().Size(); size != tt.size {
+			t.Errorf("Orientation %d: expected size %v, got %v", tt.orientation, tt.size, size)
+			continue
+		}
+		if c := color.RGBAModel.Convert(oriented.At(tt.red.X, tt.red.Y)); c != red {
+			t.Errorf("Orientation %d: expected red at %v, got %v", tt.orientation, tt.red, c)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"time"
 )
 
 // DatabaseService defines the interface for database operations
@@ -14,15 +15,172 @@ type DatabaseService interface {
 	CreateImage(ctx context.Context, image interface{}) error
 	GetImage(ctx context.Context, imageID string) (interface{}, error)
 	ListImages(ctx context.Context) ([]interface{}, error)
+	QueryImages(ctx context.Context, query *ImageQuery) ([]interface{}, error)
 	GetImageCount(ctx context.Context) (int32, error)
+	// CountImagesByVisibility counts images in each Visibility state at a time
+	CountImagesByVisibility(ctx context.Context, at time.Time) (map[string]int32, error)
 	DeleteImage(ctx context.Context, imageID string) error
-	GetCurrentImage(ctx context.Context) (interface{}, error)
+	// GetCurrentImage returns the newest image visible at a time
+	GetCurrentImage(ctx context.Context, visibleAt time.Time) (interface{}, error)
+	// GetImageAtPosition returns the image at a zero-based position in upload
+	// order, oldest first, counting only images visible at a time
+	GetImageAtPosition(ctx context.Context, position int, visibleAt time.Time) (interface{}, error)
+	GetImageByContentHash(ctx context.Context, contentHash string) (interface{}, error)
+
+	// Idempotency key operations. CreateIdempotencyKey keeps an existing key
+	// and returns the image ID and content hash stored for it.
+	CreateIdempotencyKey(ctx context.Context, key, imageID, contentHash string) (string, string, error)
+	DeleteIdempotencyKey(ctx context.Context, key string) error
 
 	// Location operations
 	CreateLocation(ctx context.Context, imageID string, location interface{}) error
 	GetLocation(ctx context.Context, imageID string) (interface{}, error)
 	UpdateLocation(ctx context.Context, imageID string, location interface{}) error
 	DeleteLocation(ctx context.Context, imageID string) error
+
+	// Image variant (resized rendition) operations
+	CreateImageVariant(ctx context.Context, variant *ImageVariant) error
+	// CreateImageVariantIfAbsent reports false when a variant with the same name already exists
+	CreateImageVariantIfAbsent(ctx context.Context, variant *ImageVariant) (bool, error)
+	ListImageVariants(ctx context.Context, imageID string) ([]*ImageVariant, error)
+	DeleteImageVariants(ctx context.Context, imageID string) error
+	DeleteImageVariant(ctx context.Context, imageID, name string) error
+
+	// Pending operation (outbox) records for storage/database consistency
+	CreatePendingOperation(ctx context.Context, op *PendingOperation) error
+	ListPendingOperations(ctx context.Context) ([]*PendingOperation, error)
+	UpdatePendingOperation(ctx context.Context, op *PendingOperation) error
+	DeletePendingOperation(ctx context.Context, id int64) error
+
+	// Rotation settings; GetRotationSettings returns nil when none were saved
+	GetRotationSettings(ctx context.Context) (*RotationSettings, error)
+	SaveRotationSettings(ctx context.Context, settings *RotationSettings) error
+
+	// Image pin operations. GetLatestImagePin returns the newest pin that was
+	// not unpinned (it may have expired), or nil.
+	CreateImagePin(ctx context.Context, pin *ImagePin) error
+	GetLatestImagePin(ctx context.Context) (*ImagePin, error)
+	EndImagePin(ctx context.Context, id int64, unpinnedAt time.Time) error
+	ListImagePins(ctx context.Context, limit int) ([]*ImagePin, error)
+
+	// Shuffle bag operations. GetShuffleBag returns nil when the viewer has none.
+	GetShuffleBag(ctx context.Context, tokenHash string) (*ShuffleBag, error)
+	SaveShuffleBag(ctx context.Context, bag *ShuffleBag) error
+	// DeleteShuffleBagsBefore removes the bags not used since a time and returns how many
+	DeleteShuffleBagsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Sort keys accepted by ImageQuery.SortBy
+const (
+	SortByCreatedAt    = "created_at"
+	SortByTakenAt      = "taken_at"
+	SortByCamera       = "camera"
+	SortByFocalLength  = "focal_length"
+	SortByAperture     = "aperture"
+	SortByISO          = "iso"
+	SortByExposureTime = "exposure_time"
+)
+
+// ImageQuery filters and sorts images; zero values match every image
+type ImageQuery struct {
+	TakenAfter       *time.Time
+	TakenBefore      *time.Time
+	CameraMake       string
+	CameraModel      string
+	LensModel        string
+	MinISO           *int32
+	MaxISO           *int32
+	MinFocalLengthMM *float64
+	MaxFocalLengthMM *float64
+	// SortBy is one of the SortBy constants; empty sorts by creation time
+	SortBy    string
+	Ascending bool
+	// VisibleAt, when set, excludes images outside their publish window at that time
+	VisibleAt *time.Time
+	// TimeOfDay matches images tagged dawn, day, dusk or night
+	TimeOfDay string
+}
+
+// Visibility states of an image relative to its publish window
+const (
+	VisibilityVisible   = "visible"
+	VisibilityScheduled = "scheduled" // publish_at is in the future
+	VisibilityExpired   = "expired"   // expire_at has passed
+)
+
+// Rotation strategies accepted by RotationSettings.Strategy
+const (
+	RotationLatest   = "latest"
+	RotationDaily    = "daily"
+	RotationHourly   = "hourly"
+	RotationInterval = "interval"
+	// RotationWeightedRandom draws an image each period, favouring higher weights
+	RotationWeightedRandom = "weighted_random"
+	// RotationShuffle shows every image once, in a random order, before repeating
+	RotationShuffle = "shuffle"
+)
+
+// RotationSettings controls which image is served as the current image
+type RotationSettings struct {
+	// Strategy is one of the Rotation constants
+	Strategy string `json:"strategy"`
+	// IntervalMinutes is the period of the interval, weighted_random and shuffle strategies
+	IntervalMinutes int `json:"interval_minutes,omitempty"`
+	// Timezone is the IANA time zone whose midnight starts each day of the daily strategy
+	Timezone string `json:"timezone,omitempty"`
+	// Seed fixes the draws of the weighted_random and shuffle strategies
+	Seed      int64     `json:"seed,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ShuffleBag is a viewer's progress through a shuffled order of the images
+type ShuffleBag struct {
+	// TokenHash is the hex SHA-256 of the viewer's token
+	TokenHash string `json:"token_hash"`
+	// Round counts the times the viewer has gone through every image
+	Round int `json:"round"`
+	// ImageIDs is the order of the current round; Position indexes the image shown
+	ImageIDs []string `json:"image_ids"`
+	Position int      `json:"position"`
+	// Slot is the rotation period in which the image was shown
+	Slot      int64     `json:"slot"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ImagePin records an image featured as the current image
+type ImagePin struct {
+	ID       int64     `json:"id"`
+	ImageID  string    `json:"image_id"`
+	PinnedAt time.Time `json:"pinned_at"`
+	// Until is when the pin expires; nil pins until it is unpinned
+	Until *time.Time `json:"until,omitempty"`
+	// UnpinnedAt is set when the pin was ended before expiring
+	UnpinnedAt *time.Time `json:"unpinned_at,omitempty"`
+}
+
+// ImageVariant is a resized rendition of an image kept in the storage backend
+type ImageVariant struct {
+	ImageID       string    `json:"image_id"`
+	Name          string    `json:"name"`
+	Width         int       `json:"width"`
+	Height        int       `json:"height"`
+	StorageFileID string    `json:"storage_file_id"`
+	MimeType      string    `json:"mime_type"`
+	SizeBytes     int64     `json:"size_bytes"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// PendingOperation records a storage change that must be completed or
+// compensated if the matching database change does not happen
+type PendingOperation struct {
+	ID            int64     `json:"id"`
+	Operation     string    `json:"operation"`
+	ImageID       string    `json:"image_id"`
+	FileID        string    `json:"file_id"`
+	Attempts      int       `json:"attempts"`
+	LastError     string    `json:"last_error,omitempty"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 // ImageService defines the interface for image-related operations
@@ -33,6 +191,7 @@ type ImageService interface {
 	GetImageCount(ctx context.Context, req interface{}) (interface{}, error)
 	ListImages(ctx context.Context, req interface{}) (interface{}, error)
 	GetImageById(ctx context.Context, req interface{}) (interface{}, error)
+	UpdateImage(ctx context.Context, req interface{}) (interface{}, error)
 	DeleteImage(ctx context.Context, req interface{}) (interface{}, error)
 }
 
@@ -43,11 +202,20 @@ type LocationService interface {
 	GetLocationFromName(ctx context.Context, req interface{}) (interface{}, error)
 }
 
-// DriveService defines the interface for Google Drive operations
+// DriveService defines the interface for blob storage operations.
+// Google Drive is the default backend; other backends (e.g. local filesystem)
+// implement the same contract so ImageService does not depend on Drive directly.
 type DriveService interface {
 	// File operations
-	UploadFile(ctx context.Context, filename string, data []byte) (string, error)
+	UploadFile(ctx context.Context, filename, mimeType string, data []byte) (string, error)
 	DeleteFile(ctx context.Context, fileID string) error
 	GetFile(ctx context.Context, fileID string) ([]byte, error)
 	GetFileURL(ctx context.Context, fileID string) (string, error)
+	ListFiles(ctx context.Context) ([]string, error)
+}
+
+// FileMetadataService is implemented by storage backends that can keep image
+// metadata alongside the stored file, so the database can be rebuilt from storage
+type FileMetadataService interface {
+	SetFileMetadata(ctx context.Context, fileID string, metadata interface{}) error
 }
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// AdminConfig holds configuration for protecting admin endpoints
+type AdminConfig struct {
+	APIKey string
+}
+
+// GetAdminConfig returns admin configuration based on environment variables
+func GetAdminConfig() *AdminConfig {
+	return &AdminConfig{
+		APIKey: os.Getenv("ADMIN_API_KEY"),
+	}
+}
+
+// Authorized reports whether the request carries the admin API key,
+// either as "Authorization: Bearer <key>" or in the X-Admin-Key header
+func (c *AdminConfig) Authorized(r *http.Request) bool {
+	if c == nil || c.APIKey == "" {
+		return false
+	}
+
+	key := r.Header.Get("X-Admin-Key")
+	if auth := r.Header.Get("Authorization"); key == "" && strings.HasPrefix(auth, "Bearer ") {
+		key = strings.TrimPrefix(auth, "Bearer ")
+	}
+
+	return subtle.ConstantTimeCompare([]byte(key), []byte(c.APIKey)) == 1
+}
+
+// AdminAuth middleware rejects requests without a valid admin API key.
+// Admin endpoints are disabled entirely when no key is configured.
+func AdminAuth(config *AdminConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if config == nil || config.APIKey == "" {
+				http.Error(w, "Admin endpoints are disabled (ADMIN_API_KEY not set)", http.StatusServiceUnavailable)
+				return
+			}
+
+			if !config.Authorized(r) {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
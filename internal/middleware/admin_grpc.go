@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AdminMetadataKey is the gRPC metadata key that carries the admin API key;
+// "authorization: Bearer <key>" is accepted as well
+const AdminMetadataKey = "x-admin-key"
+
+// AuthorizedContext reports whether the incoming gRPC metadata of ctx carries the admin API key
+func (c *AdminConfig) AuthorizedContext(ctx context.Context) bool {
+	if c == nil || c.APIKey == "" {
+		return false
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+
+	var key string
+	if values := md.Get(AdminMetadataKey); len(values) > 0 {
+		key = values[0]
+	} else if values := md.Get("authorization"); len(values) > 0 && strings.HasPrefix(values[0], "Bearer ") {
+		key = strings.TrimPrefix(values[0], "Bearer ")
+	}
+
+	return subtle.ConstantTimeCompare([]byte(key), []byte(c.APIKey)) == 1
+}
+
+// ForwardAdminKey copies the admin API key of an HTTP request, if any, to the
+// outgoing gRPC metadata of ctx, so that a gateway can make admin calls
+func ForwardAdminKey(ctx context.Context, r *http.Request) context.Context {
+	key := r.Header.Get("X-Admin-Key")
+	if auth := r.Header.Get("Authorization"); key == "" && strings.HasPrefix(auth, "Bearer ") {
+		key = strings.TrimPrefix(auth, "Bearer ")
+	}
+	if key == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, AdminMetadataKey, key)
+}
+
+// AdminUnaryInterceptor rejects the calls for which requiresAdmin returns true
+// unless they carry the admin API key. Like AdminAuth, admin calls are
+// disabled entirely when no key is configured.
+func AdminUnaryInterceptor(config *AdminConfig, requiresAdmin func(method string, req interface{}) bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if requiresAdmin(info.FullMethod, req) {
+			if config == nil || config.APIKey == "" {
+				return nil, status.Error(codes.Unavailable, "admin calls are disabled (ADMIN_API_KEY not set)")
+			}
+			if !config.AuthorizedContext(ctx) {
+				return nil, status.Error(codes.Unauthenticated, "this call requires the admin API key")
+			}
+		}
+		return handler(ctx, req)
+	}
+}
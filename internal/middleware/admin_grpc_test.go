@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdminUnaryInterceptor(t *testing.T) {
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	requiresAdmin := func(method string, req interface{}) bool {
+		return method == "/admin"
+	}
+	withKey := func(key, value string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(key, value))
+	}
+
+	tests := []struct {
+		name   string
+		config *AdminConfig
+		method string
+		ctx    context.Context
+		code   codes.Code
+	}{
+		{"public call", &AdminConfig{APIKey: "secret"}, "/public", context.Background(), codes.OK},
+		{"no key", &AdminConfig{APIKey: "secret"}, "/admin", context.Background(), codes.Unauthenticated},
+		{"wrong key", &AdminConfig{APIKey: "secret"}, "/admin", withKey(AdminMetadataKey, "guess"), codes.Unauthenticated},
+		{"admin key", &AdminConfig{APIKey: "secret"}, "/admin", withKey(AdminMetadataKey, "secret"), codes.OK},
+		{"bearer token", &AdminConfig{APIKey: "secret"}, "/admin", withKey("authorization", "Bearer secret"), codes.OK},
+		{"disabled", &AdminConfig{}, "/admin", withKey(AdminMetadataKey, ""), codes.Unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := AdminUnaryInterceptor(tt.config, requiresAdmin)
+			_, err := interceptor(tt.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if code := status.Code(err); code != tt.code {
+				t.Errorf("Expected %v, got %v", tt.code, err)
+			}
+		})
+	}
+}
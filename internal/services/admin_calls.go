@@ -0,0 +1,40 @@
+package services
+
+import (
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+)
+
+// adminMethods are the gRPC methods only admins may call. Pins override the
+// rotation settings, which are admin-only as well.
+var adminMethods = map[string]bool{
+	pb.ImageService_PinCurrentImage_FullMethodName:   true,
+	pb.ImageService_UnpinCurrentImage_FullMethodName: true,
+	pb.ImageService_ListPins_FullMethodName:          true,
+}
+
+// hiddenImagesRequest is implemented by the requests that can include images
+// outside their publish window
+type hiddenImagesRequest interface {
+	GetIncludeHidden() bool
+}
+
+// RequiresAdmin reports whether a gRPC call needs the admin API key, for
+// middleware.AdminUnaryInterceptor. Besides the admin methods, this covers
+// requests for hidden images and changes to the publish window.
+func RequiresAdmin(method string, req interface{}) bool {
+	if adminMethods[method] {
+		return true
+	}
+	if hidden, ok := req.(hiddenImagesRequest); ok && hidden.GetIncludeHidden() {
+		return true
+	}
+	if update, ok := req.(*pb.UpdateImageRequest); ok && EditsPublishWindow(update) {
+		return true
+	}
+	return false
+}
+
+// EditsPublishWindow reports whether an update sets or clears publish_at or expire_at
+func EditsPublishWindow(req *pb.UpdateImageRequest) bool {
+	return req.PublishAt != nil || req.ExpireAt != nil || req.ClearPublishAt || req.ClearExpireAt
+}
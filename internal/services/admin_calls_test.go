@@ -0,0 +1,33 @@
+package services
+
+import (
+	"testing"
+
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestRequiresAdmin(t *testing.T) {
+	title := "Sunset"
+	tests := []struct {
+		name   string
+		method string
+		req    interface{}
+		admin  bool
+	}{
+		{"pin", pb.ImageService_PinCurrentImage_FullMethodName, &pb.PinCurrentImageRequest{}, true},
+		{"list pins", pb.ImageService_ListPins_FullMethodName, &pb.ListPinsRequest{}, true},
+		{"list", pb.ImageService_ListImages_FullMethodName, &pb.ListImagesRequest{}, false},
+		{"list hidden", pb.ImageService_ListImages_FullMethodName, &pb.ListImagesRequest{IncludeHidden: true}, true},
+		{"get hidden", pb.ImageService_GetImageById_FullMethodName, &pb.GetImageByIdRequest{IncludeHidden: true}, true},
+		{"similar hidden", pb.ImageService_GetSimilarImages_FullMethodName, &pb.GetSimilarImagesRequest{IncludeHidden: true}, true},
+		{"update title", pb.ImageService_UpdateImage_FullMethodName, &pb.UpdateImageRequest{Title: &title}, false},
+		{"publish early", pb.ImageService_UpdateImage_FullMethodName, &pb.UpdateImageRequest{PublishAt: timestamppb.Now()}, true},
+		{"clear expiry", pb.ImageService_UpdateImage_FullMethodName, &pb.UpdateImageRequest{ClearExpireAt: true}, true},
+	}
+	for _, tt := range tests {
+		if admin := RequiresAdmin(tt.method, tt.req); admin != tt.admin {
+			t.Errorf("%s: expected RequiresAdmin %t, got %t", tt.name, tt.admin, admin)
+		}
+	}
+}
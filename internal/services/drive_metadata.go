@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// driveAppPropertyLimit is the maximum combined size in bytes of an appProperties key and value
+const driveAppPropertyLimit = 124
+
+// Keys of the appProperties written to each image file
+const (
+	driveMetadataVersionKey   = "bgapi_version"
+	driveMetadataIDKey        = "bgapi_id"
+	driveMetadataTitleKey     = "bgapi_title"
+	driveMetadataCreatedAtKey = "bgapi_created_at"
+	driveMetadataLatitudeKey  = "bgapi_latitude"
+	driveMetadataLongitudeKey = "bgapi_longitude"
+	driveMetadataLocationKey  = "bgapi_location"
+	driveMetadataSHA256Key    = "bgapi_sha256"
+)
+
+// Keys of the appProperties written to each variant file
+const (
+	driveMetadataVariantOfKey = "bgapi_variant_of"
+	driveMetadataVariantKey   = "bgapi_variant"
+	driveMetadataWidthKey     = "bgapi_width"
+	driveMetadataHeightKey    = "bgapi_height"
+)
+
+// driveMetadataOriginalOfKey marks the private original of a sanitized image
+const driveMetadataOriginalOfKey = "bgapi_original_of"
+
+// driveMetadataVersion identifies the format of the metadata stored in Drive
+const driveMetadataVersion = "1"
+
+// driveMetadata is the complete image metadata stored as JSON in the Drive file
+// description. appProperties only hold a searchable, possibly truncated subset.
+type driveMetadata struct {
+	Version     string       `json:"bgapi_version"`
+	ID          string       `json:"id"`
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	ContentHash string       `json:"content_hash,omitempty"`
+	MimeType    string       `json:"mime_type,omitempty"`
+	CreatedAt   *time.Time   `json:"created_at,omitempty"`
+	Location    *pb.Location `json:"location,omitempty"`
+
+	TakenAt       *time.Time `json:"taken_at,omitempty"`
+	CameraMake    string     `json:"camera_make,omitempty"`
+	CameraModel   string     `json:"camera_model,omitempty"`
+	LensModel     string     `json:"lens_model,omitempty"`
+	FocalLengthMM float64    `json:"focal_length_mm,omitempty"`
+	Aperture      float64    `json:"aperture,omitempty"`
+	ISO           int32      `json:"iso,omitempty"`
+	ExposureTime  float64    `json:"exposure_time,omitempty"`
+
+	ColorTheme *pb.ColorTheme `json:"color_theme,omitempty"`
+	// The LQIP is left out to keep the description small; backfill-placeholders restores it
+	Blurhash string   `json:"blurhash,omitempty"`
+	FocalX   *float64 `json:"focal_x,omitempty"`
+	FocalY   *float64 `json:"focal_y,omitempty"`
+
+	OriginalFileID string `json:"original_file_id,omitempty"`
+	PerceptualHash string `json:"perceptual_hash,omitempty"`
+
+	PublishAt *time.Time   `json:"publish_at,omitempty"`
+	ExpireAt  *time.Time   `json:"expire_at,omitempty"`
+	TimeOfDay pb.TimeOfDay `json:"time_of_day,omitempty"`
+	Weight    float64      `json:"weight,omitempty"`
+}
+
+// SetFileMetadata stores image or variant metadata in the Drive file's appProperties
+// and description (implements interfaces.FileMetadataService)
+func (d *DriveUtilOAuth) SetFileMetadata(ctx context.Context, fileID string, metadata interface{}) error {
+	var appProperties map[string]string
+	var description string
+	var err error
+
+	switch value := metadata.(type) {
+	case *pb.ImageMetadata:
+		appProperties, description, err = encodeDriveMetadata(value)
+	case *interfaces.ImageVariant:
+		appProperties, description = encodeDriveVariantMetadata(value)
+	case *originalFile:
+		appProperties = map[string]string{
+			driveMetadataVersionKey:    driveMetadataVersion,
+			driveMetadataOriginalOfKey: value.ImageID,
+		}
+		description = fmt.Sprintf("Original upload of image %s; not served publicly", value.ImageID)
+	default:
+		return fmt.Errorf("invalid image type")
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = d.service.Files.Update(fileID, &drive.File{
+		AppProperties: appProperties,
+		Description:   description,
+	}).SupportsAllDrives(true).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to update file metadata: %v", err)
+	}
+
+	return nil
+}
+
+// encodeDriveMetadata converts image metadata to Drive appProperties and a JSON description
+func encodeDriveMetadata(image *pb.ImageMetadata) (map[string]string, string, error) {
+	metadata := driveMetadata{
+		Version:     driveMetadataVersion,
+		ID:          image.Id,
+		Title:       image.Title,
+		Description: image.Description,
+		ContentHash: image.ContentHash,
+		MimeType:    image.MimeType,
+		Location:    image.Location,
+
+		CameraMake:    image.CameraMake,
+		CameraModel:   image.CameraModel,
+		LensModel:     image.LensModel,
+		FocalLengthMM: image.FocalLengthMm,
+		Aperture:      image.Aperture,
+		ISO:           image.Iso,
+		ExposureTime:  image.ExposureTime,
+
+		ColorTheme: image.ColorTheme,
+		Blurhash:   image.Blurhash,
+		FocalX:     image.FocalX,
+		FocalY:     image.FocalY,
+
+		OriginalFileID: image.OriginalFileId,
+		PerceptualHash: image.PerceptualHash,
+		TimeOfDay:      image.TimeOfDay,
+		Weight:         image.Weight,
+	}
+	if image.TakenAt != nil {
+		takenAt := image.TakenAt.AsTime().UTC()
+		metadata.TakenAt = &takenAt
+	}
+	if image.PublishAt != nil {
+		publishAt := image.PublishAt.AsTime().UTC()
+		metadata.PublishAt = &publishAt
+	}
+	if image.ExpireAt != nil {
+		expireAt := image.ExpireAt.AsTime().UTC()
+		metadata.ExpireAt = &expireAt
+	}
+
+	appProperties := map[string]string{
+		driveMetadataVersionKey: driveMetadataVersion,
+		driveMetadataIDKey:      truncateAppProperty(driveMetadataIDKey, image.Id),
+		driveMetadataTitleKey:   truncateAppProperty(driveMetadataTitleKey, image.Title),
+	}
+
+	if image.ContentHash != "" {
+		appProperties[driveMetadataSHA256Key] = image.ContentHash
+	}
+
+	if image.CreatedAt != nil {
+		createdAt := image.CreatedAt.AsTime().UTC()
+		metadata.CreatedAt = &createdAt
+		appProperties[driveMetadataCreatedAtKey] = createdAt.Format(time.RFC3339)
+	}
+
+	if image.Location != nil {
+		appProperties[driveMetadataLatitudeKey] = strconv.FormatFloat(image.Location.Latitude, 'f', -1, 64)
+		appProperties[driveMetadataLongitudeKey] = strconv.FormatFloat(image.Location.Longitude, 'f', -1, 64)
+		appProperties[driveMetadataLocationKey] = truncateAppProperty(driveMetadataLocationKey, image.Location.Name)
+	}
+
+	description, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode metadata: %v", err)
+	}
+
+	return appProperties, string(description), nil
+}
+
+// decodeDriveMetadata restores image metadata written by SetFileMetadata.
+// It prefers the JSON description and falls back to appProperties; the
+// second result is false when the file carries no metadata.
+func decodeDriveMetadata(file *drive.File) (*pb.ImageMetadata, bool) {
+	var metadata driveMetadata
+	if strings.HasPrefix(file.Description, "{") &&
+		json.Unmarshal([]byte(file.Description), &metadata) == nil &&
+		metadata.Version != "" && metadata.ID != "" {
+		image := &pb.ImageMetadata{
+			Id:          metadata.ID,
+			Title:       metadata.Title,
+			Description: metadata.Description,
+			Location:    metadata.Location,
+			DriveFileId: file.Id,
+			ContentHash: metadata.ContentHash,
+			MimeType:    metadata.MimeType,
+
+			CameraMake:    metadata.CameraMake,
+			CameraModel:   metadata.CameraModel,
+			LensModel:     metadata.LensModel,
+			FocalLengthMm: metadata.FocalLengthMM,
+			Aperture:      metadata.Aperture,
+			Iso:           metadata.ISO,
+			ExposureTime:  metadata.ExposureTime,
+
+			ColorTheme: metadata.ColorTheme,
+			Blurhash:   metadata.Blurhash,
+			FocalX:     metadata.FocalX,
+			FocalY:     metadata.FocalY,
+
+			OriginalFileId: metadata.OriginalFileID,
+			PerceptualHash: metadata.PerceptualHash,
+			TimeOfDay:      metadata.TimeOfDay,
+			Weight:         metadata.Weight,
+		}
+		if metadata.TakenAt != nil {
+			image.TakenAt = timestamppb.New(*metadata.TakenAt)
+		}
+		if metadata.PublishAt != nil {
+			image.PublishAt = timestamppb.New(*metadata.PublishAt)
+		}
+		if metadata.ExpireAt != nil {
+			image.ExpireAt = timestamppb.New(*metadata.ExpireAt)
+		}
+		if image.MimeType == "" {
+			image.MimeType = file.MimeType
+		}
+		if metadata.CreatedAt != nil {
+			image.CreatedAt = timestamppb.New(*metadata.CreatedAt)
+		}
+		return image, true
+	}
+
+	imageID := file.AppProperties[driveMetadataIDKey]
+	if imageID == "" {
+		return nil, false
+	}
+
+	image := &pb.ImageMetadata{
+		Id:          imageID,
+		Title:       file.AppProperties[driveMetadataTitleKey],
+		DriveFileId: file.Id,
+		ContentHash: file.AppProperties[driveMetadataSHA256Key],
+		MimeType:    file.MimeType,
+	}
+
+	if createdAt, err := time.Parse(time.RFC3339, file.AppProperties[driveMetadataCreatedAtKey]); err == nil {
+		image.CreatedAt = timestamppb.New(createdAt)
+	}
+
+	if name, ok := file.AppProperties[driveMetadataLocationKey]; ok {
+		latitude, _ := strconv.ParseFloat(file.AppProperties[driveMetadataLatitudeKey], 64)
+		longitude, _ := strconv.ParseFloat(file.AppProperties[driveMetadataLongitudeKey], 64)
+		image.Location = &pb.Location{
+			Latitude:  latitude,
+			Longitude: longitude,
+			Name:      name,
+		}
+	}
+
+	return image, true
+}
+
+// encodeDriveVariantMetadata converts variant metadata to Drive appProperties and a description
+func encodeDriveVariantMetadata(variant *interfaces.ImageVariant) (map[string]string, string) {
+	appProperties := map[string]string{
+		driveMetadataVersionKey:   driveMetadataVersion,
+		driveMetadataVariantOfKey: variant.ImageID,
+		driveMetadataVariantKey:   variant.Name,
+		driveMetadataWidthKey:     strconv.Itoa(variant.Width),
+		driveMetadataHeightKey:    strconv.Itoa(variant.Height),
+	}
+	description := fmt.Sprintf("Variant %s of image %s", variant.Name, variant.ImageID)
+	return appProperties, description
+}
+
+// decodeDriveVariantMetadata restores variant metadata written by SetFileMetadata;
+// the second result is false when the file is not a variant
+func decodeDriveVariantMetadata(file *drive.File) (*interfaces.ImageVariant, bool) {
+	imageID := file.AppProperties[driveMetadataVariantOfKey]
+	name := file.AppProperties[driveMetadataVariantKey]
+	if imageID == "" || name == "" {
+		return nil, false
+	}
+
+	width, _ := strconv.Atoi(file.AppProperties[driveMetadataWidthKey])
+	height, _ := strconv.Atoi(file.AppProperties[driveMetadataHeightKey])
+	return &interfaces.ImageVariant{
+		ImageID:       imageID,
+		Name:          name,
+		Width:         width,
+		Height:        height,
+		StorageFileID: file.Id,
+		MimeType:      file.MimeType,
+		SizeBytes:     file.Size,
+	}, true
+}
+
+// isDriveOriginalFile reports whether a file is the private original of a sanitized image
+func isDriveOriginalFile(file *drive.File) bool {
+	return file.AppProperties[driveMetadataOriginalOfKey] != ""
+}
+
+// truncateAppProperty shortens value so the key/value pair fits Drive's size limit
+// without splitting a UTF-8 character
+func truncateAppProperty(key, value string) string {
+	limit := driveAppPropertyLimit - len(key)
+	if len(value) <= limit {
+		return value
+	}
+
+	value = value[:limit]
+	for len(value) > 0 && !utf8.ValidString(value) {
+		value = value[:len(value)-1]
+	}
+	return value
+}
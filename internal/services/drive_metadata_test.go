@@ -0,0 +1,76 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestDriveMetadataRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	image := &pb.ImageMetadata{
+		Id:          "img_1",
+		Title:       strings.Repeat("Sunset über Zürich ", 10),
+		Description: "Golden hour",
+		DriveFileId: "drive-file",
+		CreatedAt:   timestamppb.New(createdAt),
+		Location: &pb.Location{
+			Latitude:  47.3769,
+			Longitude: 8.5417,
+			Name:      "Zürich",
+			Country:   "Switzerland",
+		},
+	}
+
+	appProperties, description, err := encodeDriveMetadata(image)
+	if err != nil {
+		t.Fatalf("encodeDriveMetadata failed: %v", err)
+	}
+
+	for key, value := range appProperties {
+		if len(key)+len(value) > driveAppPropertyLimit {
+			t.Errorf("appProperty %s exceeds %d bytes", key, driveAppPropertyLimit)
+		}
+		if !utf8.ValidString(value) {
+			t.Errorf("appProperty %s is not valid UTF-8", key)
+		}
+	}
+
+	t.Run("description", func(t *testing.T) {
+		restored, ok := decodeDriveMetadata(&drive.File{Id: "drive-file", Description: description, AppProperties: appProperties})
+		if !ok {
+			t.Fatal("Expected metadata to be found")
+		}
+		if restored.Title != image.Title || restored.Description != image.Description {
+			t.Errorf("Expected full title and description, got %q / %q", restored.Title, restored.Description)
+		}
+		if restored.Location.GetCountry() != "Switzerland" {
+			t.Errorf("Expected location to be restored, got %+v", restored.Location)
+		}
+		if !restored.CreatedAt.AsTime().Equal(createdAt) {
+			t.Errorf("Expected created_at %v, got %v", createdAt, restored.CreatedAt.AsTime())
+		}
+	})
+
+	t.Run("appProperties", func(t *testing.T) {
+		restored, ok := decodeDriveMetadata(&drive.File{Id: "drive-file", AppProperties: appProperties})
+		if !ok {
+			t.Fatal("Expected metadata to be found")
+		}
+		if restored.Id != "img_1" || !strings.HasPrefix(image.Title, restored.Title) {
+			t.Errorf("Unexpected metadata restored from appProperties: %+v", restored)
+		}
+		if restored.Location.GetLatitude() != 47.3769 || restored.Location.GetName() != "Zürich" {
+			t.Errorf("Expected coordinates to be restored, got %+v", restored.Location)
+		}
+	})
+
+	if _, ok := decodeDriveMetadata(&drive.File{Id: "other", Description: "Holiday photo"}); ok {
+		t.Error("Expected files without metadata to be reported as such")
+	}
+}
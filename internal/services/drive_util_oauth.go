@@ -3,128 +3,148 @@ package services
 import (
 	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/option"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-// DriveUtilOAuth handles Google Drive operations using OAuth2
+// DriveUtilOAuth handles Google Drive operations using OAuth2 or a service account
 type DriveUtilOAuth struct {
-	service  *drive.Service
-	folderID string // ID of the specific folder to use
+	service       *drive.Service
+	folderID      string // ID of the specific folder to use
+	sharedDriveID string // ID of the shared drive containing the folder, if any
+	oauthFlow     *OAuthFlow
 }
 
-// OAuthConfig holds OAuth2 configuration
-type OAuthConfig struct {
-	Web struct {
-		ClientID     string   `json:"client_id"`
-		ClientSecret string   `json:"client_secret"`
-		RedirectURIs []string `json:"redirect_uris"`
-	} `json:"web"`
+// ServiceAccountOptions configures service-account authentication
+type ServiceAccountOptions struct {
+	// Subject is the user to impersonate with domain-wide delegation (optional)
+	Subject string
+	// SharedDriveID restricts listing to a shared drive (optional)
+	SharedDriveID string
 }
 
 // NewDriveUtilOAuth creates a new Google Drive utility client using OAuth2
 func NewDriveUtilOAuth(ctx context.Context, oauthConfigPath string, tokenPath string, folderID string) (*DriveUtilOAuth, error) {
-	configData, err := os.ReadFile(oauthConfigPath)
+	config, err := LoadOAuthConfig(oauthConfigPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read OAuth config: %v", err)
+		return nil, err
 	}
 
-	var oauthConfig OAuthConfig
-	if err := json.Unmarshal(configData, &oauthConfig); err != nil {
-		return nil, fmt.Errorf("failed to parse OAuth config: %v", err)
-	}
-
-	config := &oauth2.Config{
-		ClientID:     oauthConfig.Web.ClientID,
-		ClientSecret: oauthConfig.Web.ClientSecret,
-		RedirectURL:  oauthConfig.Web.RedirectURIs[0],
-		Scopes: []string{
-			drive.DriveFileScope,
-		},
-		Endpoint: google.Endpoint,
-	}
+	return NewDriveUtilWithOAuthFlow(ctx, NewOAuthFlow(config, NewFileTokenStore(tokenPath)), folderID)
+}
 
-	token, err := loadOrGetToken(ctx, config, tokenPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get token: %v", err)
+// NewDriveUtilWithOAuthFlow creates a Google Drive utility client that gets its
+// tokens from an OAuthFlow. It does not block when no token has been stored yet;
+// Drive calls fail with ErrNotAuthorized until the HTTP authorization flow completes.
+func NewDriveUtilWithOAuthFlow(ctx context.Context, oauthFlow *OAuthFlow, folderID string) (*DriveUtilOAuth, error) {
+	if err := oauthFlow.loadToken(ctx); err != nil {
+		if !errors.Is(err, ErrTokenNotFound) {
+			return nil, fmt.Errorf("failed to get token: %v", err)
+		}
+		log.Printf("Warning: %v", ErrNotAuthorized)
 	}
 
-	client := config.Client(ctx, token)
+	client := oauth2.NewClient(context.Background(), oauthFlow.TokenSource())
 	service, err := drive.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Drive service: %v", err)
 	}
 
 	return &DriveUtilOAuth{
-		service:  service,
-		folderID: folderID,
+		service:   service,
+		folderID:  folderID,
+		oauthFlow: oauthFlow,
 	}, nil
 }
 
-func loadOrGetToken(ctx context.Context, config *oauth2.Config, tokenPath string) (*oauth2.Token, error) {
-	if tokenData, err := os.ReadFile(tokenPath); err == nil {
-		var token oauth2.Token
-		if err := json.Unmarshal(tokenData, &token); err == nil {
-			if token.Valid() {
-				return &token, nil
-			}
-			if token, err := config.TokenSource(ctx, &token).Token(); err == nil {
-				_ = saveToken(token, tokenPath)
-				return token, nil
-			}
-		}
-	}
+// NewDriveUtilServiceAccount creates a Google Drive utility client authenticated as a
+// service account. With an empty credentialsPath, Application Default Credentials are used.
+func NewDriveUtilServiceAccount(ctx context.Context, credentialsPath string, folderID string, opts ServiceAccountOptions) (*DriveUtilOAuth, error) {
+	var client *http.Client
 
-	return startOAuthFlow(ctx, config, tokenPath)
-}
+	if credentialsPath != "" {
+		credentialsData, err := os.ReadFile(credentialsPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read service account credentials: %v", err)
+		}
 
-func startOAuthFlow(ctx context.Context, config *oauth2.Config, tokenPath string) (*oauth2.Token, error) {
-	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
+		jwtConfig, err := google.JWTConfigFromJSON(credentialsData, drive.DriveScope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse service account credentials: %v", err)
+		}
+		jwtConfig.Subject = opts.Subject
 
-	fmt.Printf("OAuth2 authentication required. Please visit: %s\n", authURL)
-	fmt.Print("Enter authorization code: ")
+		client = jwtConfig.Client(context.Background())
+	} else {
+		if opts.Subject != "" {
+			return nil, fmt.Errorf("domain-wide delegation requires a service account key file")
+		}
 
-	var authCode string
-	if _, err := fmt.Scan(&authCode); err != nil {
-		return nil, fmt.Errorf("failed to read authorization code: %v", err)
+		defaultClient, err := google.DefaultClient(context.Background(), drive.DriveScope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get default credentials: %v", err)
+		}
+		client = defaultClient
 	}
 
-	token, err := config.Exchange(ctx, authCode)
+	service, err := drive.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
-		return nil, fmt.Errorf("failed to exchange code for token: %v", err)
+		return nil, fmt.Errorf("failed to create Drive service: %v", err)
 	}
 
-	if err := saveToken(token, tokenPath); err != nil {
-		return nil, fmt.Errorf("failed to save token: %v", err)
-	}
+	return &DriveUtilOAuth{
+		service:       service,
+		folderID:      folderID,
+		sharedDriveID: opts.SharedDriveID,
+	}, nil
+}
 
-	return token, nil
+// OAuthFlow returns the OAuth2 flow that provides this client's tokens,
+// or nil when the client uses a service account
+func (d *DriveUtilOAuth) OAuthFlow() *OAuthFlow {
+	return d.oauthFlow
 }
 
-func saveToken(token *oauth2.Token, tokenPath string) error {
-	tokenData, err := json.Marshal(token)
-	if err != nil {
-		return err
+// SetSharedDriveID sets the shared drive that contains the folder
+func (d *DriveUtilOAuth) SetSharedDriveID(sharedDriveID string) {
+	d.sharedDriveID = sharedDriveID
+}
+
+// listCall builds a Files.List call scoped to the folder and shared drive
+func (d *DriveUtilOAuth) listCall(query string) *drive.FilesListCall {
+	call := d.service.Files.List().
+		Q(query).
+		SupportsAllDrives(true).
+		IncludeItemsFromAllDrives(true)
+
+	if d.sharedDriveID != "" {
+		call = call.Corpora("drive").DriveId(d.sharedDriveID)
 	}
-	return os.WriteFile(tokenPath, tokenData, 0600)
+
+	return call
 }
 
-func (d *DriveUtilOAuth) UploadFile(ctx context.Context, filename string, imageData []byte) (string, error) {
+func (d *DriveUtilOAuth) UploadFile(ctx context.Context, filename, mimeType string, imageData []byte) (string, error) {
 	file := &drive.File{
-		Name:    filename,
-		Parents: []string{d.folderID},
+		Name:     filename,
+		MimeType: mimeType,
+		Parents:  []string{d.folderID},
 	}
 
-	call := d.service.Files.Create(file).Media(bytes.NewReader(imageData)).Context(ctx)
+	call := d.service.Files.Create(file).Media(bytes.NewReader(imageData)).SupportsAllDrives(true).Context(ctx)
 	createdFile, err := call.Do()
 	if err != nil {
 		return "", fmt.Errorf("upload failed: %v", err)
@@ -134,7 +154,7 @@ func (d *DriveUtilOAuth) UploadFile(ctx context.Context, filename string, imageD
 }
 
 func (d *DriveUtilOAuth) DownloadFile(ctx context.Context, fileID string) ([]byte, error) {
-	call := d.service.Files.Get(fileID).Context(ctx)
+	call := d.service.Files.Get(fileID).SupportsAllDrives(true).Context(ctx)
 	resp, err := call.Download()
 	if err != nil {
 		return nil, fmt.Errorf("download failed: %v", err)
@@ -149,8 +169,18 @@ func (d *DriveUtilOAuth) DownloadFile(ctx context.Context, fileID string) ([]byt
 	return data, nil
 }
 
+// GetFile returns the contents of a Drive file (implements interfaces.DriveService)
+func (d *DriveUtilOAuth) GetFile(ctx context.Context, fileID string) ([]byte, error) {
+	return d.DownloadFile(ctx, fileID)
+}
+
+// GetFileURL returns a direct download URL for a Drive file
+func (d *DriveUtilOAuth) GetFileURL(ctx context.Context, fileID string) (string, error) {
+	return fmt.Sprintf("https://drive.google.com/uc?export=view&id=%s", fileID), nil
+}
+
 func (d *DriveUtilOAuth) DeleteFile(ctx context.Context, fileID string) error {
-	err := d.service.Files.Delete(fileID).Context(ctx).Do()
+	err := d.service.Files.Delete(fileID).SupportsAllDrives(true).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("delete failed: %v", err)
 	}
@@ -160,58 +190,111 @@ func (d *DriveUtilOAuth) DeleteFile(ctx context.Context, fileID string) error {
 func (d *DriveUtilOAuth) ListFilesInFolder(ctx context.Context) ([]*drive.File, error) {
 	query := fmt.Sprintf("'%s' in parents and trashed=false", d.folderID)
 
-	call := d.service.Files.List().
-		Q(query).
-		Fields("files(id,name,mimeType,createdTime,modifiedTime,size)").
-		Context(ctx)
+	call := d.listCall(query).
+		Fields("nextPageToken, files(id,name,mimeType,createdTime,modifiedTime,size)").
+		PageSize(1000)
 
-	files, err := call.Do()
+	var files []*drive.File
+	err := call.Pages(ctx, func(page *drive.FileList) error {
+		files = append(files, page.Files...)
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list files: %v", err)
 	}
 
-	return files.Files, nil
+	return files, nil
 }
 
+// ListFiles returns the IDs of all files in the configured folder (implements interfaces.DriveService)
+func (d *DriveUtilOAuth) ListFiles(ctx context.Context) ([]string, error) {
+	files, err := d.ListFilesInFolder(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fileIDs := make([]string, 0, len(files))
+	for _, file := range files {
+		fileIDs = append(fileIDs, file.Id)
+	}
+	return fileIDs, nil
+}
+
+// ListImageFilesInFolder returns metadata for every image in the folder. Metadata stored
+// by SetFileMetadata is restored; other files get placeholders derived from the file name.
+// Resized variants are not included.
 func (d *DriveUtilOAuth) ListImageFilesInFolder(ctx context.Context) ([]*pb.ImageMetadata, error) {
+	files, err := d.listImageFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var imageMetadata []*pb.ImageMetadata
+	for _, file := range files {
+		if _, isVariant := decodeDriveVariantMetadata(file); isVariant || isDriveOriginalFile(file) {
+			continue
+		}
+		metadata, _ := imageFromDriveFile(file)
+		imageMetadata = append(imageMetadata, metadata)
+	}
+
+	return imageMetadata, nil
+}
+
+// listImageFiles lists the image files in the folder including their stored metadata
+func (d *DriveUtilOAuth) listImageFiles(ctx context.Context) ([]*drive.File, error) {
 	query := fmt.Sprintf("'%s' in parents and trashed=false and (mimeType contains 'image/')", d.folderID)
 
-	call := d.service.Files.List().
-		Q(query).
-		Fields("files(id,name,mimeType,createdTime,modifiedTime,size)").
-		Context(ctx)
+	call := d.listCall(query).
+		Fields("nextPageToken, files(id,name,mimeType,createdTime,modifiedTime,size,description,appProperties)").
+		PageSize(1000)
 
-	files, err := call.Do()
+	var files []*drive.File
+	err := call.Pages(ctx, func(page *drive.FileList) error {
+		files = append(files, page.Files...)
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list image files: %v", err)
 	}
 
-	var imageMetadata []*pb.ImageMetadata
-	for _, file := range files.Files {
-		title := file.Name
-		if lastDot := strings.LastIndex(file.Name, "."); lastDot != -1 {
-			title = file.Name[:lastDot]
-		}
+	return files, nil
+}
 
-		metadata := &pb.ImageMetadata{
-			Id:          file.Id,
-			Title:       title,
-			Description: fmt.Sprintf("Image uploaded on %s", file.CreatedTime),
-			DriveFileId: file.Id,
-			Location: &pb.Location{
-				Name: "Unknown Location",
-			},
-		}
+// imageFromDriveFile builds image metadata for a Drive file; the second result
+// is false when the metadata is a placeholder because the file has none stored
+func imageFromDriveFile(file *drive.File) (*pb.ImageMetadata, bool) {
+	if metadata, ok := decodeDriveMetadata(file); ok {
+		return metadata, true
+	}
 
-		imageMetadata = append(imageMetadata, metadata)
+	title := file.Name
+	if lastDot := strings.LastIndex(file.Name, "."); lastDot != -1 {
+		title = file.Name[:lastDot]
 	}
 
-	return imageMetadata, nil
+	metadata := &pb.ImageMetadata{
+		Id:          file.Id,
+		Title:       title,
+		Description: fmt.Sprintf("Image uploaded on %s", file.CreatedTime),
+		DriveFileId: file.Id,
+		MimeType:    file.MimeType,
+		Location: &pb.Location{
+			Name: "Unknown Location",
+		},
+	}
+
+	if createdAt, err := time.Parse(time.RFC3339, file.CreatedTime); err == nil {
+		metadata.CreatedAt = timestamppb.New(createdAt)
+	}
+
+	return metadata, false
 }
 
 func (d *DriveUtilOAuth) GetFolderInfo(ctx context.Context) (*drive.File, error) {
 	call := d.service.Files.Get(d.folderID).
 		Fields("id,name,createdTime,modifiedTime").
+		SupportsAllDrives(true).
 		Context(ctx)
 
 	folder, err := call.Do()
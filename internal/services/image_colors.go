@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"image"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/imaging"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+)
+
+// colorTheme computes the dominant color, palette and recommended foreground of img
+func colorTheme(img image.Image) *pb.ColorTheme {
+	analysis := imaging.AnalyzeColors(img, imaging.DefaultPaletteSize)
+	if len(analysis.Palette) == 0 {
+		// Fully transparent images have no colors to report
+		return nil
+	}
+
+	theme := &pb.ColorTheme{
+		DominantColor:    imaging.HexColor(analysis.Dominant),
+		AverageLuminance: analysis.AverageLuminance,
+		Foreground:       analysis.Foreground,
+	}
+	for _, c := range analysis.Palette {
+		theme.Palette = append(theme.Palette, imaging.HexColor(c))
+	}
+	return theme
+}
+
+// BackfillColorThemes computes the color theme of images uploaded before it was recorded
+func (s *ImageService) BackfillColorThemes(ctx context.Context) ([]string, []string, error) {
+	imagesInterface, err := s.dbService.ListImages(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list images: %v", err)
+	}
+
+	updated := []string{}
+	var errs []string
+	for _, imgInterface := range imagesInterface {
+		image, ok := imgInterface.(*pb.ImageMetadata)
+		if !ok || image.ColorTheme != nil || image.DriveFileId == "" {
+			continue
+		}
+
+		data, err := s.storage.GetFile(ctx, image.DriveFileId)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to download %s: %v", image.Id, err))
+			continue
+		}
+
+		img, _, err := imaging.Decode(data)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to decode %s: %v", image.Id, err))
+			continue
+		}
+
+		image.ColorTheme = colorTheme(img)
+		if image.ColorTheme == nil {
+			continue
+		}
+		if err := s.dbService.CreateImage(ctx, image); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to update %s: %v", image.Id, err))
+			continue
+		}
+		s.mirrorMetadata(ctx, image)
+		updated = append(updated, image.Id)
+	}
+
+	return updated, errs, nil
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/imaging"
+)
+
+// maxCoordinatePrecision bounds LOCATION_PRECISION; 7 decimal places are already about a centimetre
+const maxCoordinatePrecision = 10
+
+// ImageConfig controls the processing applied to uploaded images
+type ImageConfig struct {
+	// VariantWidths lists the widths of the resized variants generated on upload.
+	// Variants are never wider than the original.
+	VariantWidths []int
+	// JPEGQuality is the quality used to encode variants
+	JPEGQuality int
+	// ReverseGeocodeEXIF looks up the place name of EXIF GPS coordinates
+	// when a location service is configured
+	ReverseGeocodeEXIF bool
+	// SanitizeUploads serves a metadata-stripped, upright copy of each upload
+	// and keeps the unmodified file privately
+	SanitizeUploads bool
+	// CoordinatePrecision rounds stored coordinates to this many decimal places;
+	// 0 stores them unchanged
+	CoordinatePrecision int
+	// Limits bounds the size of uploaded images; the zero value accepts any size
+	Limits imaging.Limits
+	// DuplicateThreshold is the largest perceptual hash distance at which two
+	// images count as near duplicates
+	DuplicateThreshold int
+	// CropAspects lists the aspect ratios that can be requested as crops; their
+	// crops are cached in storage and other ratios are rejected
+	CropAspects []imaging.AspectRatio
+}
+
+// DefaultImageConfig returns the default image processing configuration
+func DefaultImageConfig() ImageConfig {
+	return ImageConfig{
+		VariantWidths:      []int{320, 768, 1280, 1920},
+		JPEGQuality:        imaging.DefaultJPEGQuality,
+		ReverseGeocodeEXIF: true,
+		SanitizeUploads:    true,
+		Limits:             DefaultUploadLimits(),
+		DuplicateThreshold: 10,
+		CropAspects:        parseCropAspects(defaultCropAspects),
+	}
+}
+
+// defaultCropAspects are the screen shapes whose crops are cached by default
+const defaultCropAspects = "16:9,9:16,16:10,10:16,4:3,3:4,1:1,21:9"
+
+// LoadImageConfigFromEnv reads IMAGE_VARIANT_WIDTHS (comma-separated, "none" to
+// disable variants), IMAGE_VARIANT_QUALITY, EXIF_REVERSE_GEOCODE, IMAGE_SANITIZE,
+// LOCATION_PRECISION, IMAGE_DUPLICATE_THRESHOLD and IMAGE_CROP_ASPECTS
+// (comma-separated, "none" to cache no crops), and the upload limits (see
+// LoadUploadLimitsFromEnv), falling back to the defaults
+func LoadImageConfigFromEnv() ImageConfig {
+	config := DefaultImageConfig()
+	config.Limits = LoadUploadLimitsFromEnv()
+
+	if value := os.Getenv("IMAGE_VARIANT_WIDTHS"); value != "" {
+		config.VariantWidths = parseVariantWidths(value)
+	}
+
+	if value := os.Getenv("IMAGE_VARIANT_QUALITY"); value != "" {
+		quality, err := strconv.Atoi(value)
+		if err != nil || quality < 1 || quality > 100 {
+			log.Printf("Warning: invalid IMAGE_VARIANT_QUALITY %q, using %d", value, config.JPEGQuality)
+		} else {
+			config.JPEGQuality = quality
+		}
+	}
+
+	if value := os.Getenv("EXIF_REVERSE_GEOCODE"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Printf("Warning: invalid EXIF_REVERSE_GEOCODE %q, using %t", value, config.ReverseGeocodeEXIF)
+		} else {
+			config.ReverseGeocodeEXIF = enabled
+		}
+	}
+
+	if value := os.Getenv("IMAGE_SANITIZE"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Printf("Warning: invalid IMAGE_SANITIZE %q, using %t", value, config.SanitizeUploads)
+		} else {
+			config.SanitizeUploads = enabled
+		}
+	}
+
+	if value := os.Getenv("LOCATION_PRECISION"); value != "" {
+		precision, err := strconv.Atoi(value)
+		if err != nil || precision < 0 || precision > maxCoordinatePrecision {
+			log.Printf("Warning: invalid LOCATION_PRECISION %q, storing full precision", value)
+		} else {
+			config.CoordinatePrecision = precision
+		}
+	}
+
+	if value := os.Getenv("IMAGE_DUPLICATE_THRESHOLD"); value != "" {
+		threshold, err := strconv.Atoi(value)
+		if err != nil || threshold < 0 || threshold > imaging.HashBits {
+			log.Printf("Warning: invalid IMAGE_DUPLICATE_THRESHOLD %q, using %d", value, config.DuplicateThreshold)
+		} else {
+			config.DuplicateThreshold = threshold
+		}
+	}
+
+	if value := os.Getenv("IMAGE_CROP_ASPECTS"); value != "" {
+		config.CropAspects = parseCropAspects(value)
+	}
+
+	return config
+}
+
+// parseCropAspects parses a comma-separated list of aspect ratios, dropping duplicates
+func parseCropAspects(value string) []imaging.AspectRatio {
+	aspects := []imaging.AspectRatio{}
+	if strings.EqualFold(strings.TrimSpace(value), "none") {
+		return aspects
+	}
+
+	seen := make(map[imaging.AspectRatio]bool)
+	for _, part := range strings.Split(value, ",") {
+		aspect, err := imaging.ParseAspectRatio(strings.TrimSpace(part))
+		if err != nil {
+			log.Printf("Warning: ignoring invalid crop aspect ratio %q: %v", part, err)
+			continue
+		}
+		if !seen[aspect] {
+			seen[aspect] = true
+			aspects = append(aspects, aspect)
+		}
+	}
+	return aspects
+}
+
+// parseVariantWidths parses a comma-separated list of widths into a sorted, unique list
+func parseVariantWidths(value string) []int {
+	widths := []int{}
+	if strings.EqualFold(strings.TrimSpace(value), "none") {
+		return widths
+	}
+
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(value, ",") {
+		width, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || width <= 0 {
+			log.Printf("Warning: ignoring invalid variant width %q", part)
+			continue
+		}
+		if !seen[width] {
+			seen[width] = true
+			widths = append(widths, width)
+		}
+	}
+
+	sort.Ints(widths)
+	return widths
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"log"
+	"strings"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/imaging"
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// cropVariantPrefix starts the names of crop variants, e.g. crop_9x16_w1080
+const cropVariantPrefix = "crop_"
+
+// IsCropVariant reports whether a variant is a cached crop rather than a resized original
+func IsCropVariant(variant *interfaces.ImageVariant) bool {
+	return strings.HasPrefix(variant.Name, cropVariantPrefix)
+}
+
+// cropVariantName names the crop variant for an aspect ratio scaled to width;
+// 0 names the full crop
+func cropVariantName(aspect imaging.AspectRatio, width int) string {
+	name := fmt.Sprintf("%s%dx%d", cropVariantPrefix, aspect.Width, aspect.Height)
+	if width > 0 {
+		name += fmt.Sprintf("_w%d", width)
+	}
+	return name
+}
+
+// applyFocalPoint stores the detected focal point of img in metadata
+func applyFocalPoint(metadata *pb.ImageMetadata, img image.Image) {
+	x, y := imaging.FocalPoint(img)
+	metadata.FocalX = &x
+	metadata.FocalY = &y
+}
+
+// BackfillFocalPoints detects and stores the focal point of images uploaded
+// before focal points were recorded
+func (s *ImageService) BackfillFocalPoints(ctx context.Context) ([]string, []string, error) {
+	imagesInterface, err := s.dbService.ListImages(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list images: %v", err)
+	}
+
+	updated := []string{}
+	var errs []string
+	for _, imgInterface := range imagesInterface {
+		image, ok := imgInterface.(*pb.ImageMetadata)
+		if !ok || (image.FocalX != nil && image.FocalY != nil) || image.DriveFileId == "" {
+			continue
+		}
+
+		data, err := s.storage.GetFile(ctx, image.DriveFileId)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to download %s: %v", image.Id, err))
+			continue
+		}
+
+		img, _, err := imaging.Decode(data)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to decode %s: %v", image.Id, err))
+			continue
+		}
+
+		applyFocalPoint(image, img)
+		if err := s.dbService.CreateImage(ctx, image); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to update %s: %v", image.Id, err))
+			continue
+		}
+		s.mirrorMetadata(ctx, image)
+		updated = append(updated, image.Id)
+	}
+
+	return updated, errs, nil
+}
+
+// validateFocalPoint checks the focal point fields of an update request
+func validateFocalPoint(req *pb.UpdateImageRequest) error {
+	for _, value := range []*float64{req.FocalX, req.FocalY} {
+		if value != nil && (*value < 0 || *value > 1) {
+			return status.Error(codes.InvalidArgument, "focal_x and focal_y must be between 0 and 1")
+		}
+	}
+	return nil
+}
+
+// cropWidth rounds a requested crop width up to the narrowest configured variant
+// width, so that arbitrary widths do not each produce a crop. 0 requests the
+// full crop, which is also used when no configured width is wide enough.
+func (s *ImageService) cropWidth(width int) int {
+	if width <= 0 {
+		return 0
+	}
+	rounded := 0
+	for _, variantWidth := range s.config.VariantWidths {
+		if variantWidth >= width && (rounded == 0 || variantWidth < rounded) {
+			rounded = variantWidth
+		}
+	}
+	return rounded
+}
+
+// allowsAspect reports whether crops of an aspect ratio are served
+func (s *ImageService) allowsAspect(aspect imaging.AspectRatio) bool {
+	for _, configured := range s.config.CropAspects {
+		if configured == aspect {
+			return true
+		}
+	}
+	return false
+}
+
+// cachedCrop returns the stored crop that serves an aspect ratio at width: the
+// crop scaled to width, or the full crop when it is no wider
+func cachedCrop(variants []*interfaces.ImageVariant, aspect imaging.AspectRatio, width int) *interfaces.ImageVariant {
+	for _, variant := range variants {
+		switch variant.Name {
+		case cropVariantName(aspect, width):
+			return variant
+		case cropVariantName(aspect, 0):
+			if width == 0 || variant.Width <= width {
+				return variant
+			}
+		}
+	}
+	return nil
+}
+
+// loadCropData returns the crop of an image selected by opts. Only the
+// configured aspect ratios are served, so that every crop is generated once and
+// then cached in storage under its real width; other ratios are rejected.
+func (s *ImageService) loadCropData(ctx context.Context, image *pb.ImageMetadata, opts ImageDataOptions) (*ImageData, error) {
+	if !s.allowsAspect(opts.Aspect) {
+		allowed := make([]string, len(s.config.CropAspects))
+		for i, aspect := range s.config.CropAspects {
+			allowed[i] = aspect.String()
+		}
+		return nil, status.Errorf(codes.InvalidArgument, "aspect %s is not supported; use one of [%s]", opts.Aspect, strings.Join(allowed, ", "))
+	}
+
+	width := s.cropWidth(opts.Width)
+	cache := true
+
+	variants, err := s.dbService.ListImageVariants(ctx, image.Id)
+	if err != nil {
+		log.Printf("Warning: failed to list variants of image %s: %v", image.Id, err)
+	}
+	if variant := cachedCrop(variants, opts.Aspect, width); variant != nil {
+		data, err := s.storage.GetFile(ctx, variant.StorageFileID)
+		if err == nil {
+			return &ImageData{Data: data, Metadata: image, FileID: variant.StorageFileID, MimeType: variant.MimeType}, nil
+		}
+		// Serve a fresh crop below
+		log.Printf("Warning: failed to download crop %s of image %s: %v", variant.Name, image.Id, err)
+		cache = false
+	}
+
+	original, err := s.storage.GetFile(ctx, image.DriveFileId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %v", err)
+	}
+	img, _, err := imaging.Decode(original)
+	if err != nil {
+		return nil, err
+	}
+
+	// Images uploaded before focal points were detected are cropped around one
+	// detected now; backfill-focal-points stores it
+	focalX, focalY := image.GetFocalX(), image.GetFocalY()
+	if image.FocalX == nil || image.FocalY == nil {
+		focalX, focalY = imaging.FocalPoint(img)
+	}
+
+	cropped := imaging.CropToAspect(img, opts.Aspect, focalX, focalY)
+	outputWidth := 0
+	if width > 0 && width < cropped.Bounds().Dx() {
+		cropped = imaging.ResizeToWidth(cropped, width)
+		outputWidth = width
+	}
+
+	encoded, format, err := imaging.Encode(cropped, s.config.JPEGQuality)
+	if err != nil {
+		return nil, err
+	}
+	result := &ImageData{Data: encoded, Metadata: image, MimeType: format.MimeType}
+	if !cache {
+		return result, nil
+	}
+
+	variant := &interfaces.ImageVariant{
+		ImageID:   image.Id,
+		Name:      cropVariantName(opts.Aspect, outputWidth),
+		Width:     cropped.Bounds().Dx(),
+		Height:    cropped.Bounds().Dy(),
+		MimeType:  format.MimeType,
+		SizeBytes: int64(len(encoded)),
+	}
+
+	// The crop is served even when it cannot be cached
+	filename := fmt.Sprintf("%s_%s%s", image.Id, variant.Name, format.Extension)
+	var pendingUpload *interfaces.PendingOperation
+	variant.StorageFileID, pendingUpload, err = s.uploadFile(ctx, image.Id, filename, format.MimeType, encoded)
+	if err != nil {
+		log.Printf("Warning: failed to upload crop %s of image %s: %v", variant.Name, image.Id, err)
+		return result, nil
+	}
+
+	// A concurrent request may have cached the same crop first; keep its file
+	stored, err := s.dbService.CreateImageVariantIfAbsent(ctx, variant)
+	if err != nil || !stored {
+		if err != nil {
+			log.Printf("Warning: failed to record crop %s of image %s: %v", variant.Name, image.Id, err)
+		}
+		s.compensateUpload(pendingUpload, image.Id, variant.StorageFileID)
+		return result, nil
+	}
+	completePendingOperation(ctx, s.dbService, pendingUpload)
+	s.mirrorMetadataFor(ctx, variant.StorageFileID, variant)
+
+	result.FileID = variant.StorageFileID
+	return result, nil
+}
+
+// invalidateCrops removes the cached crops of an image, e.g. after its focal point changed
+func (s *ImageService) invalidateCrops(ctx context.Context, imageID string) {
+	variants, err := s.dbService.ListImageVariants(ctx, imageID)
+	if err != nil {
+		log.Printf("Warning: failed to list variants of image %s: %v", imageID, err)
+		return
+	}
+
+	for _, variant := range variants {
+		if !IsCropVariant(variant) {
+			continue
+		}
+		if err := s.dbService.DeleteImageVariant(ctx, imageID, variant.Name); err != nil {
+			log.Printf("Warning: failed to delete crop %s of image %s: %v", variant.Name, imageID, err)
+			continue
+		}
+		if err := s.storage.DeleteFile(ctx, variant.StorageFileID); err != nil {
+			log.Printf("Warning: failed to delete crop file %s of image %s: %v", variant.StorageFileID, imageID, err)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/imaging"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Result sizes of GetSimilarImages
+const (
+	defaultSimilarImagesLimit = 20
+	maxSimilarImagesLimit     = 100
+)
+
+// DuplicateReport lists groups of near-duplicate images across the catalog
+type DuplicateReport struct {
+	Threshold int                 `json:"threshold"`
+	Images    int                 `json:"images"`
+	Clusters  []*DuplicateCluster `json:"clusters"`
+	// Images without a perceptual hash; run backfill-phashes to include them
+	Unhashed []string `json:"unhashed,omitempty"`
+}
+
+// DuplicateCluster is a group of images linked by near-duplicate pairs
+type DuplicateCluster struct {
+	// Oldest first, so the first image is usually the one to keep
+	Images []*DuplicateImage `json:"images"`
+	// Largest distance of the pairs linking the cluster
+	MaxDistance int `json:"max_distance"`
+}
+
+// DuplicateImage identifies an image in a DuplicateCluster
+type DuplicateImage struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// hashedImage is a stored image with its decoded perceptual hash
+type hashedImage struct {
+	metadata *pb.ImageMetadata
+	hash     uint64
+}
+
+// listHashedImages returns the stored images that have a perceptual hash, and
+// the IDs of those that do not
+func (s *ImageService) listHashedImages(ctx context.Context) ([]hashedImage, []string, error) {
+	imagesInterface, err := s.dbService.ListImages(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list images: %v", err)
+	}
+
+	var hashed []hashedImage
+	var unhashed []string
+	for _, imgInterface := range imagesInterface {
+		image, ok := imgInterface.(*pb.ImageMetadata)
+		if !ok {
+			continue
+		}
+		hash, err := imaging.ParseHash(image.PerceptualHash)
+		if err != nil {
+			unhashed = append(unhashed, image.Id)
+			continue
+		}
+		hashed = append(hashed, hashedImage{metadata: image, hash: hash})
+	}
+	return hashed, unhashed, nil
+}
+
+// findSimilarImages returns the images within maxDistance of hash, closest
+// first, leaving out images not visible at visibleAt when it is set. Catalogs
+// are small enough to compare every hash.
+func (s *ImageService) findSimilarImages(ctx context.Context, hash uint64, excludeID string, maxDistance, limit int, visibleAt *time.Time) ([]*pb.SimilarImage, error) {
+	images, _, err := s.listHashedImages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	similar := []*pb.SimilarImage{}
+	for _, candidate := range images {
+		if candidate.metadata.Id == excludeID {
+			continue
+		}
+		if visibleAt != nil && !imageVisible(candidate.metadata, *visibleAt) {
+			continue
+		}
+		if distance := imaging.HammingDistance(hash, candidate.hash); distance <= maxDistance {
+			similar = append(similar, &pb.SimilarImage{Metadata: candidate.metadata, Distance: int32(distance)})
+		}
+	}
+
+	sort.SliceStable(similar, func(i, j int) bool {
+		if similar[i].Distance != similar[j].Distance {
+			return similar[i].Distance < similar[j].Distance
+		}
+		return similar[i].Metadata.CreatedAt.AsTime().Before(similar[j].Metadata.CreatedAt.AsTime())
+	})
+	if limit > 0 && len(similar) > limit {
+		similar = similar[:limit]
+	}
+	return similar, nil
+}
+
+// validateHashDistance checks a client-supplied perceptual hash distance
+func validateHashDistance(name string, distance *int32) error {
+	if distance != nil && (*distance < 0 || *distance > imaging.HashBits) {
+		return status.Errorf(codes.InvalidArgument, "%s must be between 0 and %d", name, imaging.HashBits)
+	}
+	return nil
+}
+
+// validateDuplicatePolicy checks the near-duplicate options of an upload
+func validateDuplicatePolicy(req *pb.UploadImageRequest) error {
+	if _, ok := pb.DuplicatePolicy_name[int32(req.DuplicatePolicy)]; !ok {
+		return status.Errorf(codes.InvalidArgument, "unsupported duplicate_policy %d", req.DuplicatePolicy)
+	}
+	return validateHashDistance("duplicate_threshold", req.DuplicateThreshold)
+}
+
+// findNearDuplicates returns the stored images within the upload's duplicate
+// threshold of hash, or nil when the upload does not check for duplicates
+func (s *ImageService) findNearDuplicates(ctx context.Context, req *pb.UploadImageRequest, hash uint64) ([]*pb.SimilarImage, error) {
+	if req.DuplicatePolicy == pb.DuplicatePolicy_DUPLICATE_POLICY_ALLOW {
+		return nil, nil
+	}
+
+	threshold := s.config.DuplicateThreshold
+	if req.DuplicateThreshold != nil {
+		threshold = int(*req.DuplicateThreshold)
+	}
+	return s.findSimilarImages(ctx, hash, "", threshold, maxSimilarImagesLimit, nil)
+}
+
+// nearDuplicateError reports an upload rejected by DUPLICATE_POLICY_REJECT
+func nearDuplicateError(closest *pb.SimilarImage) error {
+	return status.Errorf(codes.AlreadyExists, "image is a near duplicate of %s (distance %d)", closest.Metadata.Id, closest.Distance)
+}
+
+// GetSimilarImages returns the images whose perceptual hash is close to the given image's
+func (s *ImageService) GetSimilarImages(ctx context.Context, req *pb.GetSimilarImagesRequest) (*pb.GetSimilarImagesResponse, error) {
+	if err := validateHashDistance("max_distance", req.MaxDistance); err != nil {
+		return nil, err
+	}
+	limit := int(req.Limit)
+	if limit < 0 || limit > maxSimilarImagesLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxSimilarImagesLimit)
+	}
+	if limit == 0 {
+		limit = defaultSimilarImagesLimit
+	}
+
+	imageInterface, err := s.dbService.GetImage(ctx, req.ImageId)
+	if err != nil {
+		return &pb.GetSimilarImagesResponse{
+			Success: false,
+			Message: "Image not found",
+		}, nil
+	}
+
+	image, ok := imageInterface.(*pb.ImageMetadata)
+	if !ok {
+		return &pb.GetSimilarImagesResponse{
+			Success: false,
+			Message: "Invalid image data type",
+		}, nil
+	}
+	var visibleAt *time.Time
+	if !req.IncludeHidden {
+		now := s.now()
+		if !imageVisible(image, now) {
+			return &pb.GetSimilarImagesResponse{
+				Success: false,
+				Message: "Image not found",
+			}, nil
+		}
+		visibleAt = &now
+	}
+
+	hash, err := imaging.ParseHash(image.PerceptualHash)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, "image has no perceptual hash; run the backfill-phashes admin command")
+	}
+
+	maxDistance := s.config.DuplicateThreshold
+	if req.MaxDistance != nil {
+		maxDistance = int(*req.MaxDistance)
+	}
+
+	similar, err := s.findSimilarImages(ctx, hash, image.Id, maxDistance, limit, visibleAt)
+	if err != nil {
+		return &pb.GetSimilarImagesResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to find similar images: %v", err),
+		}, nil
+	}
+
+	return &pb.GetSimilarImagesResponse{
+		Success: true,
+		Message: fmt.Sprintf("Found %d similar images", len(similar)),
+		Images:  similar,
+	}, nil
+}
+
+// FindDuplicates groups the catalog into clusters of images linked by pairs
+// within threshold of each other
+func (s *ImageService) FindDuplicates(ctx context.Context, threshold int) (*DuplicateReport, error) {
+	images, unhashed, err := s.listHashedImages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Union-find over every pair within the threshold
+	parent := make([]int, len(images))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	maxDistance := make(map[int]int)
+	linked := make([]bool, len(images))
+	for i := range images {
+		for j := i + 1; j < len(images); j++ {
+			distance := imaging.HammingDistance(images[i].hash, images[j].hash)
+			if distance > threshold {
+				continue
+			}
+			linked[i], linked[j] = true, true
+			rootI, rootJ := find(i), find(j)
+			largest := max(distance, maxDistance[rootI], maxDistance[rootJ])
+			if rootI != rootJ {
+				parent[rootJ] = rootI
+				delete(maxDistance, rootJ)
+			}
+			maxDistance[rootI] = largest
+		}
+	}
+
+	clusters := make(map[int]*DuplicateCluster)
+	report := &DuplicateReport{
+		Threshold: threshold,
+		Images:    len(images) + len(unhashed),
+		Clusters:  []*DuplicateCluster{},
+		Unhashed:  unhashed,
+	}
+	for i, candidate := range images {
+		if !linked[i] {
+			continue
+		}
+		root := find(i)
+		cluster, ok := clusters[root]
+		if !ok {
+			cluster = &DuplicateCluster{MaxDistance: maxDistance[root]}
+			clusters[root] = cluster
+			report.Clusters = append(report.Clusters, cluster)
+		}
+		cluster.Images = append(cluster.Images, &DuplicateImage{
+			ID:        candidate.metadata.Id,
+			Title:     candidate.metadata.Title,
+			CreatedAt: candidate.metadata.CreatedAt.AsTime(),
+		})
+	}
+
+	for _, cluster := range report.Clusters {
+		sort.SliceStable(cluster.Images, func(i, j int) bool {
+			return cluster.Images[i].CreatedAt.Before(cluster.Images[j].CreatedAt)
+		})
+	}
+	sort.SliceStable(report.Clusters, func(i, j int) bool {
+		return len(report.Clusters[i].Images) > len(report.Clusters[j].Images)
+	})
+
+	return report, nil
+}
+
+// BackfillPerceptualHashes computes the perceptual hash of images uploaded before it was recorded
+func (s *ImageService) BackfillPerceptualHashes(ctx context.Context) ([]string, []string, error) {
+	imagesInterface, err := s.dbService.ListImages(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list images: %v", err)
+	}
+
+	updated := []string{}
+	var errs []string
+	for _, imgInterface := range imagesInterface {
+		image, ok := imgInterface.(*pb.ImageMetadata)
+		if !ok || image.PerceptualHash != "" || image.DriveFileId == "" {
+			continue
+		}
+
+		data, err := s.storage.GetFile(ctx, image.DriveFileId)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to download %s: %v", image.Id, err))
+			continue
+		}
+
+		img, _, err := imaging.Decode(data)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to decode %s: %v", image.Id, err))
+			continue
+		}
+
+		image.PerceptualHash = imaging.FormatHash(imaging.DHash(img))
+		if err := s.dbService.CreateImage(ctx, image); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to update %s: %v", image.Id, err))
+			continue
+		}
+		s.mirrorMetadata(ctx, image)
+		updated = append(updated, image.Id)
+	}
+
+	return updated, errs, nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/imaging"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNearDuplicates(t *testing.T) {
+	ctx := context.Background()
+	storage, dbService := newTestBackends(t)
+	service := NewImageService(storage, dbService)
+
+	upload := func(title string, data []byte, policy pb.DuplicatePolicy) (*pb.UploadImageResponse, error) {
+		return service.UploadImage(ctx, &pb.UploadImageRequest{Title: title, ImageData: data, DuplicatePolicy: policy})
+	}
+
+	original, err := upload("original", testPNG(t, 64, 64), pb.DuplicatePolicy_DUPLICATE_POLICY_ALLOW)
+	if err != nil || !original.Success {
+		t.Fatalf("Upload failed: %v %v", err, original)
+	}
+	if len(original.Metadata.PerceptualHash) != 16 {
+		t.Fatalf("Expected a perceptual hash, got %q", original.Metadata.PerceptualHash)
+	}
+
+	// A smaller copy uploads with a warning...
+	smaller, err := upload("smaller", testPNG(t, 32, 32), pb.DuplicatePolicy_DUPLICATE_POLICY_WARN)
+	if err != nil || !smaller.Success {
+		t.Fatalf("Upload failed: %v %v", err, smaller)
+	}
+	if len(smaller.SimilarImages) != 1 || smaller.SimilarImages[0].Metadata.Id != original.ImageId {
+		t.Fatalf("Expected the original as a near duplicate, got %v", smaller.SimilarImages)
+	}
+
+	// ...or is rejected
+	if _, err := upload("rejected", testPNG(t, 48, 48), pb.DuplicatePolicy_DUPLICATE_POLICY_REJECT); status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("Expected AlreadyExists, got %v", err)
+	}
+
+	// A different image is accepted
+	mirrored := imaging.Orient(mustDecode(t, testPNG(t, 64, 64)), 2)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, mirrored); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+	different, err := upload("different", buf.Bytes(), pb.DuplicatePolicy_DUPLICATE_POLICY_REJECT)
+	if err != nil || !different.Success {
+		t.Fatalf("Upload failed: %v %v", err, different)
+	}
+
+	if _, err := service.UploadImage(ctx, &pb.UploadImageRequest{ImageData: testPNG(t, 8, 8), DuplicateThreshold: proto.Int32(65)}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an out of range threshold, got %v", err)
+	}
+
+	similar, err := service.GetSimilarImages(ctx, &pb.GetSimilarImagesRequest{ImageId: original.ImageId})
+	if err != nil || !similar.Success {
+		t.Fatalf("GetSimilarImages failed: %v %v", err, similar)
+	}
+	if len(similar.Images) != 1 || similar.Images[0].Metadata.Id != smaller.ImageId {
+		t.Errorf("Expected only the smaller copy, got %v", similar.Images)
+	}
+
+	report, err := service.FindDuplicates(ctx, 10)
+	if err != nil {
+		t.Fatalf("FindDuplicates failed: %v", err)
+	}
+	if len(report.Clusters) != 1 || len(report.Clusters[0].Images) != 2 || report.Clusters[0].Images[0].ID != original.ImageId {
+		t.Errorf("Expected one cluster of the original and its copy, got %+v", report.Clusters)
+	}
+}
+
+// mustDecode decodes an encoded test image
+func mustDecode(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	img, _, err := imaging.Decode(data)
+	if err != nil {
+		t.Fatalf("Failed to decode test image: %v", err)
+	}
+	return img
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/imaging"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Names of the location fields reported in UploadImageResponse.exif_fields
+const (
+	exifFieldLatitude  = "latitude"
+	exifFieldLongitude = "longitude"
+	exifFieldName      = "name"
+	exifFieldCity      = "city"
+	exifFieldCountry   = "country"
+	exifFieldAddress   = "address"
+)
+
+// SetLocationService enables reverse geocoding of EXIF GPS coordinates on upload
+func (s *ImageService) SetLocationService(locationService *LocationService) {
+	s.locationService = locationService
+}
+
+// readEXIF returns the EXIF data of an image, or nil when it has none
+func readEXIF(data []byte) *imaging.EXIF {
+	metadata, err := imaging.ReadEXIF(data)
+	if err != nil {
+		if !errors.Is(err, imaging.ErrNoEXIF) {
+			log.Printf("Warning: %v", err)
+		}
+		return nil
+	}
+	return metadata
+}
+
+// applyEXIFCapture copies the capture time and camera settings to image
+func applyEXIFCapture(image *pb.ImageMetadata, metadata *imaging.EXIF) {
+	if metadata == nil {
+		return
+	}
+
+	if metadata.TakenAt != nil {
+		image.TakenAt = timestamppb.New(*metadata.TakenAt)
+	}
+	image.CameraMake = metadata.CameraMake
+	image.CameraModel = metadata.CameraModel
+	image.LensModel = metadata.LensModel
+	image.FocalLengthMm = metadata.FocalLengthMM
+	image.Aperture = metadata.Aperture
+	image.Iso = int32(metadata.ISO)
+	image.ExposureTime = metadata.ExposureTime
+}
+
+// BackfillCaptureMetadata reads the capture time and camera settings of images
+// uploaded before they were recorded. Images whose files carry no EXIF data are skipped.
+func (s *ImageService) BackfillCaptureMetadata(ctx context.Context) ([]string, []string, error) {
+	imagesInterface, err := s.dbService.ListImages(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list images: %v", err)
+	}
+
+	updated := []string{}
+	var errs []string
+	for _, imgInterface := range imagesInterface {
+		image, ok := imgInterface.(*pb.ImageMetadata)
+		if !ok || image.TakenAt != nil || image.CameraModel != "" || image.DriveFileId == "" {
+			continue
+		}
+
+		// The public copy of a sanitized image has no EXIF data left; the original does
+		fileID := image.DriveFileId
+		if image.OriginalFileId != "" {
+			fileID = image.OriginalFileId
+		}
+		data, err := s.storage.GetFile(ctx, fileID)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to download %s: %v", image.Id, err))
+			continue
+		}
+
+		metadata := readEXIF(data)
+		if metadata == nil || (metadata.TakenAt == nil && metadata.CameraModel == "") {
+			continue
+		}
+
+		applyEXIFCapture(image, metadata)
+		if err := s.dbService.CreateImage(ctx, image); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to update %s: %v", image.Id, err))
+			continue
+		}
+		s.mirrorMetadata(ctx, image)
+		updated = append(updated, image.Id)
+	}
+
+	return updated, errs, nil
+}
+
+// applyEXIFLocation fills the coordinates of location from the EXIF GPS data of
+// the image when none were supplied, and optionally the place fields that are
+// still empty. It returns the resulting location and the names of the filled fields.
+func (s *ImageService) applyEXIFLocation(ctx context.Context, location *pb.Location, metadata *imaging.EXIF) (*pb.Location, []string) {
+	if location.GetLatitude() != 0 || location.GetLongitude() != 0 {
+		return location, nil
+	}
+	if metadata == nil || !metadata.HasLocation {
+		return location, nil
+	}
+
+	// Leave the caller's request untouched
+	filled := &pb.Location{}
+	if location != nil {
+		filled = proto.Clone(location).(*pb.Location)
+	}
+	filled.Latitude = metadata.Latitude
+	filled.Longitude = metadata.Longitude
+	fields := []string{exifFieldLatitude, exifFieldLongitude}
+
+	if s.locationService == nil || !s.config.ReverseGeocodeEXIF {
+		return filled, fields
+	}
+
+	resp, err := s.locationService.GetLocationFromCoords(ctx, &pb.GetLocationFromCoordsRequest{
+		Latitude:  filled.Latitude,
+		Longitude: filled.Longitude,
+	})
+	if err != nil || !resp.Success || resp.Location == nil {
+		log.Printf("Warning: failed to reverse geocode EXIF location: %v %s", err, resp.GetMessage())
+		return filled, fields
+	}
+
+	fillField := func(target *string, value, name string) {
+		if *target == "" && value != "" {
+			*target = value
+			fields = append(fields, name)
+		}
+	}
+	fillField(&filled.Name, resp.Location.Name, exifFieldName)
+	fillField(&filled.City, resp.Location.City, exifFieldCity)
+	fillField(&filled.Country, resp.Location.Country, exifFieldCountry)
+	fillField(&filled.Address, resp.Location.Address, exifFieldAddress)
+
+	return filled, fields
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Result sizes of ListPins
+const (
+	defaultPinsLimit = 50
+	maxPinsLimit     = 500
+)
+
+// pinActive reports whether pin still overrides the current image at now
+func pinActive(pin *interfaces.ImagePin, now time.Time) bool {
+	return pin.UnpinnedAt == nil && (pin.Until == nil || now.Before(*pin.Until))
+}
+
+// pinToProto converts a stored pin to its API representation
+func pinToProto(pin *interfaces.ImagePin, now time.Time) *pb.ImagePin {
+	result := &pb.ImagePin{
+		Id:       pin.ID,
+		ImageId:  pin.ImageID,
+		PinnedAt: timestamppb.New(pin.PinnedAt),
+		Active:   pinActive(pin, now),
+	}
+	if pin.Until != nil {
+		result.Until = timestamppb.New(*pin.Until)
+	}
+	if pin.UnpinnedAt != nil {
+		result.UnpinnedAt = timestamppb.New(*pin.UnpinnedAt)
+	}
+	return result
+}
+
+// activePin returns the pin in effect at now, or nil
+func (s *ImageService) activePin(ctx context.Context, now time.Time) (*interfaces.ImagePin, error) {
+	pin, err := s.dbService.GetLatestImagePin(ctx)
+	if err != nil || pin == nil || !pinActive(pin, now) {
+		return nil, err
+	}
+	return pin, nil
+}
+
+// pinnedImage returns the image pinned at now and its pin, or nil when nothing
+// is pinned. A pinned image that was deleted or is outside its publish window
+// does not override the rotation.
+func (s *ImageService) pinnedImage(ctx context.Context, now time.Time) (*pb.ImageMetadata, *interfaces.ImagePin) {
+	pin, err := s.activePin(ctx, now)
+	if err != nil {
+		log.Printf("Warning: failed to read the image pin, using the rotation: %v", err)
+		return nil, nil
+	}
+	if pin == nil {
+		return nil, nil
+	}
+
+	imageInterface, err := s.dbService.GetImage(ctx, pin.ImageID)
+	if err != nil {
+		return nil, nil
+	}
+	image, ok := imageInterface.(*pb.ImageMetadata)
+	if !ok || !imageVisible(image, now) {
+		return nil, nil
+	}
+	return image, pin
+}
+
+// PinCurrentImage features an image as the current image until req.Until, or
+// until it is unpinned, replacing any active pin
+func (s *ImageService) PinCurrentImage(ctx context.Context, req *pb.PinCurrentImageRequest) (*pb.PinCurrentImageResponse, error) {
+	if req.ImageId == "" {
+		return nil, status.Error(codes.InvalidArgument, "image_id is required")
+	}
+	now := s.now()
+	if req.Until != nil && !req.Until.AsTime().After(now) {
+		return nil, status.Error(codes.InvalidArgument, "until must be in the future")
+	}
+
+	if _, err := s.dbService.GetImage(ctx, req.ImageId); err != nil {
+		return &pb.PinCurrentImageResponse{
+			Success: false,
+			Message: "Image not found",
+		}, nil
+	}
+
+	previous, err := s.activePin(ctx, now)
+	if err != nil {
+		return &pb.PinCurrentImageResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to read the active pin: %v", err),
+		}, nil
+	}
+	if previous != nil {
+		if err := s.dbService.EndImagePin(ctx, previous.ID, now); err != nil {
+			return &pb.PinCurrentImageResponse{
+				Success: false,
+				Message: fmt.Sprintf("Failed to replace the active pin: %v", err),
+			}, nil
+		}
+	}
+
+	pin := &interfaces.ImagePin{ImageID: req.ImageId, PinnedAt: now}
+	if req.Until != nil {
+		until := req.Until.AsTime()
+		pin.Until = &until
+	}
+	if err := s.dbService.CreateImagePin(ctx, pin); err != nil {
+		return &pb.PinCurrentImageResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to pin image: %v", err),
+		}, nil
+	}
+
+	return &pb.PinCurrentImageResponse{
+		Success: true,
+		Message: "Image pinned successfully",
+		Pin:     pinToProto(pin, now),
+	}, nil
+}
+
+// UnpinCurrentImage ends the active pin so the rotation resumes
+func (s *ImageService) UnpinCurrentImage(ctx context.Context, req *pb.UnpinCurrentImageRequest) (*pb.UnpinCurrentImageResponse, error) {
+	now := s.now()
+	pin, err := s.activePin(ctx, now)
+	if err != nil {
+		return &pb.UnpinCurrentImageResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to read the active pin: %v", err),
+		}, nil
+	}
+	if pin == nil {
+		return &pb.UnpinCurrentImageResponse{
+			Success: false,
+			Message: "No image is pinned",
+		}, nil
+	}
+
+	if err := s.dbService.EndImagePin(ctx, pin.ID, now); err != nil {
+		return &pb.UnpinCurrentImageResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to unpin image: %v", err),
+		}, nil
+	}
+	pin.UnpinnedAt = &now
+
+	return &pb.UnpinCurrentImageResponse{
+		Success: true,
+		Message: "Image unpinned successfully",
+		Pin:     pinToProto(pin, now),
+	}, nil
+}
+
+// ListPins returns the pin history, newest first
+func (s *ImageService) ListPins(ctx context.Context, req *pb.ListPinsRequest) (*pb.ListPinsResponse, error) {
+	limit := int(req.Limit)
+	if limit < 0 || limit > maxPinsLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxPinsLimit)
+	}
+	if limit == 0 {
+		limit = defaultPinsLimit
+	}
+
+	pins, err := s.dbService.ListImagePins(ctx, limit)
+	if err != nil {
+		return &pb.ListPinsResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to list pins: %v", err),
+		}, nil
+	}
+
+	now := s.now()
+	resp := &pb.ListPinsResponse{
+		Success: true,
+		Message: "Pins retrieved successfully",
+		Pins:    []*pb.ImagePin{},
+	}
+	for _, pin := range pins {
+		resp.Pins = append(resp.Pins, pinToProto(pin, now))
+	}
+	return resp, nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestImagePins(t *testing.T) {
+	ctx := context.Background()
+	storage, dbService := newTestBackends(t)
+	service := NewImageService(storage, dbService)
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	var ids []string
+	for _, size := range []int{8, 9} {
+		resp, err := service.UploadImage(ctx, &pb.UploadImageRequest{ImageData: testPNG(t, size, size)})
+		if err != nil || !resp.Success {
+			t.Fatalf("Upload failed: %v %v", err, resp)
+		}
+		ids = append(ids, resp.ImageId)
+	}
+
+	current := func() *pb.GetCurrentImageResponse {
+		t.Helper()
+		resp, err := service.GetCurrentImage(ctx, &pb.GetCurrentImageRequest{})
+		if err != nil || !resp.Success {
+			t.Fatalf("GetCurrentImage failed: %v %v", err, resp)
+		}
+		return resp
+	}
+
+	if _, err := service.PinCurrentImage(ctx, &pb.PinCurrentImageRequest{ImageId: ids[0], Until: timestamppb.New(now.Add(-time.Hour))}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an expiry in the past, got %v", err)
+	}
+	if resp, err := service.PinCurrentImage(ctx, &pb.PinCurrentImageRequest{ImageId: "missing"}); err != nil || resp.Success {
+		t.Errorf("Expected pinning a missing image to fail, got %v %v", err, resp)
+	}
+
+	// A pin overrides the newest upload until it expires
+	until := now.Add(2 * time.Hour)
+	pinned, err := service.PinCurrentImage(ctx, &pb.PinCurrentImageRequest{ImageId: ids[0], Until: timestamppb.New(until)})
+	if err != nil || !pinned.Success || !pinned.Pin.Active {
+		t.Fatalf("PinCurrentImage failed: %v %v", err, pinned)
+	}
+	if resp := current(); resp.Metadata.Id != ids[0] || resp.Pin == nil || !resp.NextRotationAt.AsTime().Equal(until) {
+		t.Errorf("Expected the pinned image until %v, got %s %v %v", until, resp.Metadata.Id, resp.Pin, resp.NextRotationAt)
+	}
+
+	now = until
+	if resp := current(); resp.Metadata.Id != ids[1] || resp.Pin != nil {
+		t.Errorf("Expected the newest image after the pin expired, got %s %v", resp.Metadata.Id, resp.Pin)
+	}
+	if resp, err := service.UnpinCurrentImage(ctx, &pb.UnpinCurrentImageRequest{}); err != nil || resp.Success {
+		t.Errorf("Expected unpinning without an active pin to fail, got %v %v", err, resp)
+	}
+
+	// Pins without an expiry last until they are unpinned
+	if resp, err := service.PinCurrentImage(ctx, &pb.PinCurrentImageRequest{ImageId: ids[0]}); err != nil || !resp.Success {
+		t.Fatalf("PinCurrentImage failed: %v %v", err, resp)
+	}
+	now = now.Add(24 * time.Hour)
+	if resp := current(); resp.Metadata.Id != ids[0] || resp.NextRotationAt != nil {
+		t.Errorf("Expected the pinned image without an expiry, got %s %v", resp.Metadata.Id, resp.NextRotationAt)
+	}
+	unpinned, err := service.UnpinCurrentImage(ctx, &pb.UnpinCurrentImageRequest{})
+	if err != nil || !unpinned.Success || unpinned.Pin.Active || unpinned.Pin.UnpinnedAt == nil {
+		t.Fatalf("UnpinCurrentImage failed: %v %v", err, unpinned)
+	}
+	if resp := current(); resp.Metadata.Id != ids[1] {
+		t.Errorf("Expected the newest image after unpinning, got %s", resp.Metadata.Id)
+	}
+
+	history, err := service.ListPins(ctx, &pb.ListPinsRequest{})
+	if err != nil || !history.Success || len(history.Pins) != 2 {
+		t.Fatalf("Expected two pins in the history, got %v %v", err, history)
+	}
+	if history.Pins[0].UnpinnedAt == nil || history.Pins[1].Until == nil || history.Pins[1].Active {
+		t.Errorf("Expected the unpinned pin first and the expired one second, got %v", history.Pins)
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"log"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/imaging"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+)
+
+// applyPlaceholders stores the BlurHash and low-quality placeholder of img in metadata
+func applyPlaceholders(metadata *pb.ImageMetadata, img image.Image) {
+	metadata.Blurhash = imaging.BlurHash(img)
+
+	lqip, err := imaging.LQIP(img)
+	if err != nil {
+		log.Printf("Warning: failed to create placeholder for image %s: %v", metadata.Id, err)
+		return
+	}
+	metadata.Lqip = lqip
+}
+
+// BackfillPlaceholders computes the BlurHash and low-quality placeholder of
+// images uploaded before they were recorded
+func (s *ImageService) BackfillPlaceholders(ctx context.Context) ([]string, []string, error) {
+	imagesInterface, err := s.dbService.ListImages(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list images: %v", err)
+	}
+
+	updated := []string{}
+	var errs []string
+	for _, imgInterface := range imagesInterface {
+		image, ok := imgInterface.(*pb.ImageMetadata)
+		if !ok || (image.Blurhash != "" && image.Lqip != "") || image.DriveFileId == "" {
+			continue
+		}
+
+		data, err := s.storage.GetFile(ctx, image.DriveFileId)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to download %s: %v", image.Id, err))
+			continue
+		}
+
+		img, _, err := imaging.Decode(data)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to decode %s: %v", image.Id, err))
+			continue
+		}
+
+		applyPlaceholders(image, img)
+		if err := s.dbService.CreateImage(ctx, image); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to update %s: %v", image.Id, err))
+			continue
+		}
+		s.mirrorMetadata(ctx, image)
+		updated = append(updated, image.Id)
+	}
+
+	return updated, errs, nil
+}
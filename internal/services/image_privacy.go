@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/imaging"
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/protobuf/proto"
+)
+
+// originalFile marks the private, unsanitized upload of an image in storage
+type originalFile struct {
+	ImageID string
+}
+
+// originalFilename names the stored original of an image
+func originalFilename(imageID string, format imaging.Format) string {
+	return fmt.Sprintf("%s_original%s", imageID, format.Extension)
+}
+
+// uploadFile stores a file of a new image and records a pending upload so the
+// file is removed if the image metadata is never saved
+func (s *ImageService) uploadFile(ctx context.Context, imageID, filename, mimeType string, data []byte) (string, *interfaces.PendingOperation, error) {
+	fileID, err := s.storage.UploadFile(ctx, filename, mimeType, data)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to upload to storage: %v", err)
+	}
+
+	pendingUpload := newPendingOperation(OperationUpload, imageID, fileID)
+	if err := s.dbService.CreatePendingOperation(ctx, pendingUpload); err != nil {
+		s.compensateUpload(nil, imageID, fileID)
+		return "", nil, fmt.Errorf("failed to save metadata: %v", err)
+	}
+
+	return fileID, pendingUpload, nil
+}
+
+// roundCoordinates returns location with its coordinates rounded to the
+// configured precision, leaving the caller's value untouched
+func (s *ImageService) roundCoordinates(location *pb.Location) *pb.Location {
+	if location == nil || s.config.CoordinatePrecision <= 0 {
+		return location
+	}
+
+	scale := math.Pow10(s.config.CoordinatePrecision)
+	rounded := proto.Clone(location).(*pb.Location)
+	rounded.Latitude = math.Round(rounded.Latitude*scale) / scale
+	rounded.Longitude = math.Round(rounded.Longitude*scale) / scale
+	return rounded
+}
+
+// BackfillSanitizedCopies stores sanitized public copies of images uploaded
+// before sanitization was enabled. The existing file becomes the private original.
+func (s *ImageService) BackfillSanitizedCopies(ctx context.Context) ([]string, []string, error) {
+	imagesInterface, err := s.dbService.ListImages(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list images: %v", err)
+	}
+
+	updated := []string{}
+	var errs []string
+	for _, imgInterface := range imagesInterface {
+		image, ok := imgInterface.(*pb.ImageMetadata)
+		if !ok || image.OriginalFileId != "" || image.DriveFileId == "" {
+			continue
+		}
+
+		data, err := s.storage.GetFile(ctx, image.DriveFileId)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to download %s: %v", image.Id, err))
+			continue
+		}
+
+		img, format, err := imaging.Validate(data)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to decode %s: %v", image.Id, err))
+			continue
+		}
+		sanitized, upright, sanitizedFormat, err := imaging.Sanitize(data, img, format)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to sanitize %s: %v", image.Id, err))
+			continue
+		}
+
+		fileID, err := s.storage.UploadFile(ctx, image.Id+sanitizedFormat.Extension, sanitizedFormat.MimeType, sanitized)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to upload %s: %v", image.Id, err))
+			continue
+		}
+
+		updatedImage := proto.Clone(image).(*pb.ImageMetadata)
+		updatedImage.OriginalFileId = image.DriveFileId
+		updatedImage.DriveFileId = fileID
+		updatedImage.MimeType = sanitizedFormat.MimeType
+		updatedImage.Location = s.roundCoordinates(image.Location)
+		rotated := upright != img
+		if rotated {
+			// Everything derived from the pixels has to follow the new orientation
+			updatedImage.ColorTheme = colorTheme(upright)
+			applyPlaceholders(updatedImage, upright)
+			applyFocalPoint(updatedImage, upright)
+			updatedImage.PerceptualHash = imaging.FormatHash(imaging.DHash(upright))
+		}
+
+		if err := s.dbService.CreateImage(ctx, updatedImage); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to update %s: %v", image.Id, err))
+			s.compensateUpload(nil, image.Id, fileID)
+			continue
+		}
+		s.mirrorMetadata(ctx, updatedImage)
+		s.mirrorMetadataFor(ctx, updatedImage.OriginalFileId, &originalFile{ImageID: image.Id})
+
+		if rotated {
+			s.invalidateCrops(ctx, image.Id)
+			if _, err := s.GenerateVariants(ctx, image.Id); err != nil {
+				errs = append(errs, fmt.Sprintf("failed to regenerate variants of %s: %v", image.Id, err))
+			}
+		}
+		updated = append(updated, image.Id)
+	}
+
+	return updated, errs, nil
+}
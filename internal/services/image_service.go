@@ -2,31 +2,100 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"image"
+	"log"
+	"strings"
 	"time"
 
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/imaging"
 	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
 	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ErrImageNotFound is returned when the requested image does not exist
+var ErrImageNotFound = errors.New("image not found")
+
+var (
+	// ErrIdempotencyKeyInUse is returned while the first upload with a key is still running
+	ErrIdempotencyKeyInUse = status.Error(codes.Aborted, "an upload with this idempotency key is in progress")
+	// ErrIdempotencyKeyMismatch is returned when a key is reused for different image bytes
+	ErrIdempotencyKeyMismatch = status.Error(codes.FailedPrecondition, "idempotency key was already used for a different image")
+	// ErrUnsupportedImageType is returned for uploads that are not JPEG, PNG, GIF or WebP
+	ErrUnsupportedImageType = status.Error(codes.InvalidArgument, "unsupported image type: upload a JPEG, PNG, GIF or WebP image")
 )
 
 // ImageService implements the gRPC ImageService
 type ImageService struct {
 	pb.UnimplementedImageServiceServer
-	driveUtil *DriveUtilOAuth
+	storage   interfaces.DriveService
 	dbService interfaces.DatabaseService
+	config    ImageConfig
+	// locationService reverse geocodes EXIF coordinates; nil disables the lookup
+	locationService *LocationService
+	// now returns the time used to schedule rotations
+	now func() time.Time
 }
 
-// NewImageService creates a new ImageService instance
-func NewImageService(driveUtil *DriveUtilOAuth, dbService interfaces.DatabaseService) *ImageService {
+// NewImageService creates a new ImageService instance with the default image configuration
+func NewImageService(storage interfaces.DriveService, dbService interfaces.DatabaseService) *ImageService {
+	return NewImageServiceWithConfig(storage, dbService, DefaultImageConfig())
+}
+
+// NewImageServiceWithConfig creates a new ImageService instance with the given image configuration
+func NewImageServiceWithConfig(storage interfaces.DriveService, dbService interfaces.DatabaseService, config ImageConfig) *ImageService {
 	return &ImageService{
-		driveUtil: driveUtil,
+		storage:   storage,
 		dbService: dbService,
+		config:    config,
+		now:       time.Now,
 	}
 }
 
-// GetCurrentImage returns the most recently created image
+// GetCurrentImage returns the pinned image, or else the image selected by the
+// rotation settings; by default the most recently created image. In the time
+// of day mode, images tagged with the viewer's part of the day come first.
 func (s *ImageService) GetCurrentImage(ctx context.Context, req *pb.GetCurrentImageRequest) (*pb.GetCurrentImageResponse, error) {
-	imageInterface, err := s.dbService.GetCurrentImage(ctx)
+	now := s.now()
+	var viewer *viewerDay
+	switch req.GetMode() {
+	case pb.CurrentImageMode_CURRENT_IMAGE_MODE_ROTATION:
+	case pb.CurrentImageMode_CURRENT_IMAGE_MODE_TIME_OF_DAY:
+		var err error
+		if viewer, err = newViewerDay(req, now); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported mode %d", req.GetMode())
+	}
+	draw, err := newDrawOptions(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if image, pin := s.pinnedImage(ctx, now); image != nil {
+		resp := &pb.GetCurrentImageResponse{
+			Success:    true,
+			Message:    "Current image retrieved successfully",
+			Metadata:   image,
+			ColorTheme: image.ColorTheme,
+			Pin:        pinToProto(pin, now),
+		}
+		// The rotation resumes when the pin expires
+		if pin.Until != nil {
+			resp.NextRotationAt = timestamppb.New(*pin.Until)
+		}
+		viewer.apply(resp)
+		return resp, nil
+	}
+
+	imageInterface, nextRotation, err := s.selectedImage(ctx, viewer, now, draw)
 	if err != nil {
 		return &pb.GetCurrentImageResponse{
 			Success: false,
@@ -42,14 +111,22 @@ func (s *ImageService) GetCurrentImage(ctx context.Context, req *pb.GetCurrentIm
 		}, nil
 	}
 
-	return &pb.GetCurrentImageResponse{
-		Success:  true,
-		Message:  "Current image retrieved successfully",
-		Metadata: image,
-	}, nil
+	resp := &pb.GetCurrentImageResponse{
+		Success:    true,
+		Message:    "Current image retrieved successfully",
+		Metadata:   image,
+		ColorTheme: image.ColorTheme,
+	}
+	if nextRotation != nil {
+		resp.NextRotationAt = timestamppb.New(*nextRotation)
+	}
+	viewer.apply(resp)
+	return resp, nil
 }
 
-// UploadImage uploads an image to Google Drive and stores metadata
+// UploadImage uploads an image to the storage backend and stores metadata.
+// Uploads of bytes that are already stored, or retries with the same
+// idempotency key, return the original image instead of creating a new one.
 func (s *ImageService) UploadImage(ctx context.Context, req *pb.UploadImageRequest) (*pb.UploadImageResponse, error) {
 	// Generate a unique ID if not provided
 	imageID := req.Id
@@ -57,64 +134,254 @@ func (s *ImageService) UploadImage(ctx context.Context, req *pb.UploadImageReque
 		imageID = fmt.Sprintf("img_%d", time.Now().UnixNano())
 	}
 
+	if err := validateDuplicatePolicy(req); err != nil {
+		return nil, err
+	}
+	if err := validatePublishWindow(req.PublishAt, req.ExpireAt); err != nil {
+		return nil, err
+	}
+	if err := validateTimeOfDay("time_of_day", req.TimeOfDay); err != nil {
+		return nil, err
+	}
+	if err := validateWeight(req.Weight); err != nil {
+		return nil, err
+	}
+
+	// Reject oversized images from their headers, before decoding them
+	var limitErr *imaging.LimitError
+	if err := imaging.CheckLimits(req.ImageData, s.config.Limits); errors.As(err, &limitErr) {
+		return nil, uploadLimitError(limitErr)
+	}
+
+	// Only store data that decodes as a supported image format
+	img, format, err := imaging.Validate(req.ImageData)
+	if errors.Is(err, imaging.ErrUnsupportedFormat) {
+		return nil, ErrUnsupportedImageType
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	contentHash := hashContent(req.ImageData)
+
+	// Return the original image when the same bytes were uploaded before
+	if existing := s.findImageByContentHash(ctx, contentHash); existing != nil {
+		if req.IdempotencyKey != "" {
+			_, storedHash, err := s.dbService.CreateIdempotencyKey(ctx, req.IdempotencyKey, existing.Id, contentHash)
+			if err != nil {
+				return &pb.UploadImageResponse{
+					Success: false,
+					Message: fmt.Sprintf("Failed to save idempotency key: %v", err),
+				}, nil
+			}
+			if storedHash != contentHash {
+				return nil, ErrIdempotencyKeyMismatch
+			}
+		}
+		return replayedUploadResponse(existing), nil
+	}
+
+	if req.IdempotencyKey == "" {
+		return s.uploadNewImage(ctx, req, imageID, contentHash, img, format)
+	}
+
+	// Reserve the key before uploading so concurrent retries cannot both upload
+	storedImageID, storedHash, err := s.dbService.CreateIdempotencyKey(ctx, req.IdempotencyKey, imageID, contentHash)
+	if err != nil {
+		return &pb.UploadImageResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to save idempotency key: %v", err),
+		}, nil
+	}
+	if storedHash != contentHash {
+		return nil, ErrIdempotencyKeyMismatch
+	}
+	if storedImageID != imageID {
+		if imageInterface, err := s.dbService.GetImage(ctx, storedImageID); err == nil {
+			if image, ok := imageInterface.(*pb.ImageMetadata); ok {
+				return replayedUploadResponse(image), nil
+			}
+		}
+		return nil, ErrIdempotencyKeyInUse
+	}
+
+	resp, err := s.uploadNewImage(ctx, req, imageID, contentHash, img, format)
+	if err != nil || !resp.Success {
+		// Release the key so the client can retry the failed upload
+		if deleteErr := s.dbService.DeleteIdempotencyKey(ctx, req.IdempotencyKey); deleteErr != nil {
+			log.Printf("Warning: failed to release idempotency key for image %s: %v", imageID, deleteErr)
+		}
+	}
+	return resp, err
+}
+
+// uploadNewImage stores the image file and its metadata
+func (s *ImageService) uploadNewImage(ctx context.Context, req *pb.UploadImageRequest, imageID, contentHash string, img image.Image, format imaging.Format) (*pb.UploadImageResponse, error) {
+	// Fill in the location from the photo's GPS data when the client did not send one
+	exifData := readEXIF(req.ImageData)
+	location, exifFields := s.applyEXIFLocation(ctx, req.Location, exifData)
+	location = s.roundCoordinates(location)
+
+	// Serve a sanitized copy and keep the unmodified upload privately
+	data, publicImg, publicFormat := req.ImageData, img, format
+	if s.config.SanitizeUploads {
+		var err error
+		data, publicImg, publicFormat, err = imaging.Sanitize(req.ImageData, img, format)
+		if err != nil {
+			return &pb.UploadImageResponse{
+				Success: false,
+				Message: fmt.Sprintf("Failed to sanitize image: %v", err),
+			}, nil
+		}
+	}
+
+	// Compare what will be served with the stored images
+	hash := imaging.DHash(publicImg)
+	similar, err := s.findNearDuplicates(ctx, req, hash)
+	if err != nil {
+		return &pb.UploadImageResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to check for near duplicates: %v", err),
+		}, nil
+	}
+	if len(similar) > 0 && req.DuplicatePolicy == pb.DuplicatePolicy_DUPLICATE_POLICY_REJECT {
+		return nil, nearDuplicateError(similar[0])
+	}
+
+	var originalFileID string
+	var pendingOriginal *interfaces.PendingOperation
+	if s.config.SanitizeUploads {
+		originalFileID, pendingOriginal, err = s.uploadFile(ctx, imageID, originalFilename(imageID, format), format.MimeType, req.ImageData)
+		if err != nil {
+			return &pb.UploadImageResponse{
+				Success: false,
+				Message: fmt.Sprintf("Failed to store image: %v", err),
+			}, nil
+		}
+		s.mirrorMetadataFor(ctx, originalFileID, &originalFile{ImageID: imageID})
+	}
+	img, format = publicImg, publicFormat
+
 	// Generate filename
-	filename := fmt.Sprintf("%s_%s.jpg", imageID, req.Title)
-	if filename == "_" {
-		filename = fmt.Sprintf("%s.jpg", imageID)
+	filename := fmt.Sprintf("%s_%s%s", imageID, req.Title, format.Extension)
+	if req.Title == "" {
+		filename = imageID + format.Extension
 	}
 
-	// Upload to Google Drive
-	driveFileID, err := s.driveUtil.UploadFile(ctx, filename, req.ImageData)
+	// Upload to storage backend
+	driveFileID, pendingUpload, err := s.uploadFile(ctx, imageID, filename, format.MimeType, data)
 	if err != nil {
+		if pendingOriginal != nil {
+			s.compensateUpload(pendingOriginal, imageID, originalFileID)
+		}
 		return &pb.UploadImageResponse{
 			Success: false,
-			Message: fmt.Sprintf("Failed to upload to Google Drive: %v", err),
+			Message: fmt.Sprintf("Failed to store image: %v", err),
 		}, nil
 	}
 
 	// Create metadata
 	metadata := &pb.ImageMetadata{
-		Id:          imageID,
-		Title:       req.Title,
-		Description: req.Description,
-		Location:    req.Location,
-		DriveFileId: driveFileID,
+		Id:             imageID,
+		Title:          req.Title,
+		Description:    req.Description,
+		Location:       location,
+		DriveFileId:    driveFileID,
+		OriginalFileId: originalFileID,
+		ContentHash:    contentHash,
+		MimeType:       format.MimeType,
+		CreatedAt:      timestamppb.Now(),
+		PerceptualHash: imaging.FormatHash(hash),
+		PublishAt:      req.PublishAt,
+		ExpireAt:       req.ExpireAt,
+		TimeOfDay:      req.TimeOfDay,
+		Weight:         1,
+	}
+	if req.Weight != nil {
+		metadata.Weight = *req.Weight
 	}
+	applyEXIFCapture(metadata, exifData)
+	metadata.ColorTheme = colorTheme(img)
+	applyPlaceholders(metadata, img)
+	applyFocalPoint(metadata, img)
 
 	// Store metadata in database
 	err = s.dbService.CreateImage(ctx, metadata)
 	if err != nil {
+		s.compensateUpload(pendingUpload, imageID, driveFileID)
+		if pendingOriginal != nil {
+			s.compensateUpload(pendingOriginal, imageID, originalFileID)
+		}
 		return &pb.UploadImageResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to save metadata: %v", err),
 		}, nil
 	}
+	completePendingOperation(ctx, s.dbService, pendingUpload)
+	if pendingOriginal != nil {
+		completePendingOperation(ctx, s.dbService, pendingOriginal)
+	}
+
+	// Keep a copy of the metadata with the file so the database can be rebuilt from storage
+	s.mirrorMetadata(ctx, metadata)
+
+	// Variants are an optimization; the original is served when they are missing
+	if _, err := s.createVariants(ctx, metadata, img); err != nil {
+		log.Printf("Warning: failed to create variants for image %s: %v", imageID, err)
+	}
 
 	return &pb.UploadImageResponse{
-		Success:  true,
-		Message:  "Image uploaded successfully",
-		ImageId:  imageID,
-		Metadata: metadata,
+		Success:       true,
+		Message:       "Image uploaded successfully",
+		ImageId:       imageID,
+		Metadata:      metadata,
+		ExifFields:    exifFields,
+		SimilarImages: similar,
 	}, nil
 }
 
-// GetImageCount returns the total number of images
-func (s *ImageService) GetImageCount(ctx context.Context, req *pb.GetImageCountRequest) (*pb.GetImageCountResponse, error) {
-	count, err := s.dbService.GetImageCount(ctx)
+// findImageByContentHash returns the stored image with the given content hash, or nil
+func (s *ImageService) findImageByContentHash(ctx context.Context, contentHash string) *pb.ImageMetadata {
+	imageInterface, err := s.dbService.GetImageByContentHash(ctx, contentHash)
 	if err != nil {
-		return &pb.GetImageCountResponse{
-			Count: 0,
-		}, err
+		return nil
 	}
 
-	return &pb.GetImageCountResponse{
-		Count: count,
-	}, nil
+	image, _ := imageInterface.(*pb.ImageMetadata)
+	return image
 }
 
-// ListImages returns all images from the database
+// replayedUploadResponse returns the response of the upload that created image
+func replayedUploadResponse(image *pb.ImageMetadata) *pb.UploadImageResponse {
+	return &pb.UploadImageResponse{
+		Success:  true,
+		Message:  "Image uploaded successfully",
+		ImageId:  image.Id,
+		Metadata: image,
+		Replayed: true,
+	}
+}
+
+// hashContent returns the hex-encoded SHA-256 hash of data
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ListImages returns the images matching the request filters, newest first
+// unless another order is requested. Images outside their publish window are
+// left out unless include_hidden is set.
 func (s *ImageService) ListImages(ctx context.Context, req *pb.ListImagesRequest) (*pb.ListImagesResponse, error) {
-	imagesInterface, err := s.dbService.ListImages(ctx)
+	query, err := imageQueryFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if !req.IncludeHidden {
+		now := s.now()
+		query.VisibleAt = &now
+	}
+
+	imagesInterface, err := s.dbService.QueryImages(ctx, query)
 	if err != nil {
 		return &pb.ListImagesResponse{
 			Success: false,
@@ -137,7 +404,8 @@ func (s *ImageService) ListImages(ctx context.Context, req *pb.ListImagesRequest
 	}, nil
 }
 
-// GetImageById retrieves a specific image by ID
+// GetImageById retrieves a specific image by ID. An image outside its publish
+// window is reported as not found unless include_hidden is set.
 func (s *ImageService) GetImageById(ctx context.Context, req *pb.GetImageByIdRequest) (*pb.GetImageByIdResponse, error) {
 	imageInterface, err := s.dbService.GetImage(ctx, req.ImageId)
 	if err != nil {
@@ -154,6 +422,12 @@ func (s *ImageService) GetImageById(ctx context.Context, req *pb.GetImageByIdReq
 			Message: "Invalid image data type",
 		}, nil
 	}
+	if !req.IncludeHidden && !imageVisible(image, s.now()) {
+		return &pb.GetImageByIdResponse{
+			Success: false,
+			Message: "Image not found",
+		}, nil
+	}
 
 	return &pb.GetImageByIdResponse{
 		Success:  true,
@@ -162,9 +436,95 @@ func (s *ImageService) GetImageById(ctx context.Context, req *pb.GetImageByIdReq
 	}, nil
 }
 
-// DeleteImage removes an image from both database and Google Drive
+// UpdateImage changes the title, description, location, focal point, publish
+// window, time of day tag or weight of an image
+func (s *ImageService) UpdateImage(ctx context.Context, req *pb.UpdateImageRequest) (*pb.UpdateImageResponse, error) {
+	if err := validateFocalPoint(req); err != nil {
+		return nil, err
+	}
+	if req.TimeOfDay != nil {
+		if err := validateTimeOfDay("time_of_day", *req.TimeOfDay); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateWeight(req.Weight); err != nil {
+		return nil, err
+	}
+
+	imageInterface, err := s.dbService.GetImage(ctx, req.ImageId)
+	if err != nil {
+		return &pb.UpdateImageResponse{
+			Success: false,
+			Message: "Image not found",
+		}, nil
+	}
+
+	image, ok := imageInterface.(*pb.ImageMetadata)
+	if !ok {
+		return &pb.UpdateImageResponse{
+			Success: false,
+			Message: "Invalid image data type",
+		}, nil
+	}
+
+	if req.Title != nil {
+		image.Title = *req.Title
+	}
+	if req.Description != nil {
+		image.Description = *req.Description
+	}
+	if req.Location != nil {
+		image.Location = s.roundCoordinates(req.Location)
+	}
+	if err := applyPublishWindow(image, req); err != nil {
+		return nil, err
+	}
+	if req.TimeOfDay != nil {
+		image.TimeOfDay = *req.TimeOfDay
+	}
+	if req.Weight != nil {
+		image.Weight = *req.Weight
+	}
+	focalPointChanged := false
+	if req.FocalX != nil || req.FocalY != nil {
+		// A coordinate that is not given keeps its value, or the centre when none was detected
+		x, y := 0.5, 0.5
+		if image.FocalX != nil && image.FocalY != nil {
+			x, y = *image.FocalX, *image.FocalY
+		}
+		if req.FocalX != nil {
+			x = *req.FocalX
+		}
+		if req.FocalY != nil {
+			y = *req.FocalY
+		}
+		focalPointChanged = image.FocalX == nil || image.FocalY == nil || x != *image.FocalX || y != *image.FocalY
+		image.FocalX, image.FocalY = &x, &y
+	}
+
+	// CreateImage updates the existing row
+	if err := s.dbService.CreateImage(ctx, image); err != nil {
+		return &pb.UpdateImageResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to save metadata: %v", err),
+		}, nil
+	}
+
+	s.mirrorMetadata(ctx, image)
+	if focalPointChanged {
+		s.invalidateCrops(ctx, image.Id)
+	}
+
+	return &pb.UpdateImageResponse{
+		Success:  true,
+		Message:  "Image updated successfully",
+		Metadata: image,
+	}, nil
+}
+
+// DeleteImage removes an image from both database and the storage backend
 func (s *ImageService) DeleteImage(ctx context.Context, req *pb.DeleteImageRequest) (*pb.DeleteImageResponse, error) {
-	// Get image metadata first to get the Drive file ID
+	// Get image metadata first to get the storage file ID
 	imageInterface, err := s.dbService.GetImage(ctx, req.ImageId)
 	if err != nil {
 		return &pb.DeleteImageResponse{
@@ -181,28 +541,239 @@ func (s *ImageService) DeleteImage(ctx context.Context, req *pb.DeleteImageReque
 		}, nil
 	}
 
-	// Delete from Google Drive
+	// The stored files and all variants are removed from storage
+	fileIDs := []string{}
 	if image.DriveFileId != "" {
-		err := s.driveUtil.DeleteFile(ctx, image.DriveFileId)
-		if err != nil {
+		fileIDs = append(fileIDs, image.DriveFileId)
+	}
+	if image.OriginalFileId != "" {
+		fileIDs = append(fileIDs, image.OriginalFileId)
+	}
+	variants, err := s.dbService.ListImageVariants(ctx, image.Id)
+	if err != nil {
+		return &pb.DeleteImageResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to list image variants: %v", err),
+		}, nil
+	}
+	for _, variant := range variants {
+		fileIDs = append(fileIDs, variant.StorageFileID)
+	}
+
+	// Record the file deletions first so they are retried if they fail after the row is gone
+	var pendingDeletes []*interfaces.PendingOperation
+	for _, fileID := range fileIDs {
+		pendingDelete := newPendingOperation(OperationDeleteFile, image.Id, fileID)
+		if err := s.dbService.CreatePendingOperation(ctx, pendingDelete); err != nil {
+			for _, op := range pendingDeletes {
+				completePendingOperation(ctx, s.dbService, op)
+			}
 			return &pb.DeleteImageResponse{
 				Success: false,
-				Message: fmt.Sprintf("Failed to delete from Google Drive: %v", err),
+				Message: fmt.Sprintf("Failed to delete from database: %v", err),
 			}, nil
 		}
+		pendingDeletes = append(pendingDeletes, pendingDelete)
 	}
 
 	// Delete from database
 	err = s.dbService.DeleteImage(ctx, req.ImageId)
 	if err != nil {
+		for _, op := range pendingDeletes {
+			completePendingOperation(ctx, s.dbService, op)
+		}
 		return &pb.DeleteImageResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to delete from database: %v", err),
 		}, nil
 	}
+	if err := s.dbService.DeleteImageVariants(ctx, req.ImageId); err != nil {
+		log.Printf("Warning: failed to delete variant records of image %s: %v", req.ImageId, err)
+	}
+
+	// Delete from storage backend
+	var deleteErr error
+	for _, op := range pendingDeletes {
+		if err := s.storage.DeleteFile(ctx, op.FileID); err != nil {
+			recordPendingOperationFailure(ctx, s.dbService, op, err)
+			deleteErr = err
+			continue
+		}
+		completePendingOperation(ctx, s.dbService, op)
+	}
+	if deleteErr != nil {
+		return &pb.DeleteImageResponse{
+			Success: true,
+			Message: fmt.Sprintf("Image deleted; removing stored files failed and will be retried: %v", deleteErr),
+		}, nil
+	}
 
 	return &pb.DeleteImageResponse{
 		Success: true,
 		Message: "Image deleted successfully",
 	}, nil
 }
+
+// GetImageData returns the stored bytes of an image rendition along with its metadata
+func (s *ImageService) GetImageData(ctx context.Context, imageID string, opts ImageDataOptions) (*ImageData, error) {
+	imageInterface, err := s.dbService.GetImage(ctx, imageID)
+	if err != nil {
+		return nil, ErrImageNotFound
+	}
+
+	image, ok := imageInterface.(*pb.ImageMetadata)
+	if !ok {
+		return nil, fmt.Errorf("invalid image data type")
+	}
+	if !opts.IncludeHidden && !imageVisible(image, s.now()) {
+		return nil, ErrImageNotFound
+	}
+
+	return s.loadImageData(ctx, image, opts)
+}
+
+// GetCurrentImageData returns the stored bytes of the current image rendition along with its metadata
+func (s *ImageService) GetCurrentImageData(ctx context.Context, req *pb.GetCurrentImageRequest, opts ImageDataOptions) (*ImageData, error) {
+	resp, err := s.GetCurrentImage(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success || resp.Metadata == nil {
+		return nil, ErrImageNotFound
+	}
+
+	return s.loadImageData(ctx, resp.Metadata, opts)
+}
+
+// compensateUpload deletes a file whose metadata could not be saved. It uses a
+// fresh context because the request context may be what caused the failure.
+// When the delete fails, the pending operation is left for the retrier.
+func (s *ImageService) compensateUpload(pendingUpload *interfaces.PendingOperation, imageID, fileID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := s.storage.DeleteFile(ctx, fileID); err != nil {
+		if pendingUpload == nil {
+			log.Printf("Error: failed to delete orphaned file %s for image %s: %v", fileID, imageID, err)
+			return
+		}
+		recordPendingOperationFailure(ctx, s.dbService, pendingUpload, err)
+		return
+	}
+
+	if pendingUpload != nil {
+		completePendingOperation(ctx, s.dbService, pendingUpload)
+	}
+}
+
+// BackfillContentHashes computes the content hash and MIME type of images stored before
+// they were recorded, so re-uploads of their bytes are detected as duplicates
+func (s *ImageService) BackfillContentHashes(ctx context.Context) ([]string, []string, error) {
+	imagesInterface, err := s.dbService.ListImages(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list images: %v", err)
+	}
+
+	updated := []string{}
+	var errs []string
+	for _, imgInterface := range imagesInterface {
+		image, ok := imgInterface.(*pb.ImageMetadata)
+		if !ok || (image.ContentHash != "" && image.MimeType != "") || image.DriveFileId == "" {
+			continue
+		}
+
+		data, err := s.storage.GetFile(ctx, image.DriveFileId)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to download %s: %v", image.Id, err))
+			continue
+		}
+
+		if format, err := imaging.DetectFormat(data); err == nil {
+			image.MimeType = format.MimeType
+		}
+		if image.ContentHash == "" && image.OriginalFileId != "" {
+			// Hashes identify the uploaded bytes, which are kept as the original
+			data, err = s.storage.GetFile(ctx, image.OriginalFileId)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("failed to download the original of %s: %v", image.Id, err))
+				continue
+			}
+		}
+		if image.ContentHash == "" {
+			image.ContentHash = hashContent(data)
+		}
+		if err := s.dbService.CreateImage(ctx, image); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to update %s: %v", image.Id, err))
+			continue
+		}
+		s.mirrorMetadata(ctx, image)
+		updated = append(updated, image.Id)
+	}
+
+	return updated, errs, nil
+}
+
+// mirrorMetadata copies image metadata to the storage backend when it supports it.
+// The database stays the source of truth, so failures are only logged.
+func (s *ImageService) mirrorMetadata(ctx context.Context, image *pb.ImageMetadata) {
+	s.mirrorMetadataFor(ctx, image.DriveFileId, image)
+}
+
+// mirrorMetadataFor stores image or variant metadata with a stored file
+func (s *ImageService) mirrorMetadataFor(ctx context.Context, fileID string, metadata interface{}) {
+	metadataService, ok := s.storage.(interfaces.FileMetadataService)
+	if !ok || fileID == "" {
+		return
+	}
+
+	if err := metadataService.SetFileMetadata(ctx, fileID, metadata); err != nil {
+		log.Printf("Warning: failed to store metadata for file %s in storage: %v", fileID, err)
+	}
+}
+
+// imageQueryFromRequest converts ListImages filters to a database query
+func imageQueryFromRequest(req *pb.ListImagesRequest) (*interfaces.ImageQuery, error) {
+	query := &interfaces.ImageQuery{
+		CameraMake:       req.GetCameraMake(),
+		CameraModel:      req.GetCameraModel(),
+		LensModel:        req.GetLensModel(),
+		MinISO:           req.MinIso,
+		MaxISO:           req.MaxIso,
+		MinFocalLengthMM: req.MinFocalLengthMm,
+		MaxFocalLengthMM: req.MaxFocalLengthMm,
+		SortBy:           req.GetSortBy(),
+	}
+
+	if err := validateTimeOfDay("time_of_day", req.GetTimeOfDay()); err != nil {
+		return nil, err
+	}
+	if phase, ok := timeOfDayPhases[req.GetTimeOfDay()]; ok {
+		query.TimeOfDay = string(phase)
+	}
+
+	if req.GetTakenAfter() != nil {
+		takenAfter := req.TakenAfter.AsTime()
+		query.TakenAfter = &takenAfter
+	}
+	if req.GetTakenBefore() != nil {
+		takenBefore := req.TakenBefore.AsTime()
+		query.TakenBefore = &takenBefore
+	}
+
+	switch query.SortBy {
+	case "", interfaces.SortByCreatedAt, interfaces.SortByTakenAt, interfaces.SortByCamera,
+		interfaces.SortByFocalLength, interfaces.SortByAperture, interfaces.SortByISO, interfaces.SortByExposureTime:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported sort_by %q", query.SortBy)
+	}
+
+	switch strings.ToLower(req.GetSortOrder()) {
+	case "", "desc":
+	case "asc":
+		query.Ascending = true
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "sort_order must be asc or desc, got %q", req.GetSortOrder())
+	}
+
+	return query, nil
+}
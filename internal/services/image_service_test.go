@@ -0,0 +1,303 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/imaging"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestUploadImageIdempotency(t *testing.T) {
+	ctx := context.Background()
+	storage, dbService := newTestBackends(t)
+	service := NewImageService(storage, dbService)
+
+	upload := func(key string, data []byte) (*pb.UploadImageResponse, error) {
+		return service.UploadImage(ctx, &pb.UploadImageRequest{
+			Title:          "Sunset",
+			Location:       &pb.Location{Name: "Beach"},
+			ImageData:      data,
+			IdempotencyKey: key,
+		})
+	}
+
+	first, err := upload("key-1", testPNG(t, 4, 4))
+	if err != nil || !first.Success {
+		t.Fatalf("First upload failed: %v %v", err, first)
+	}
+	if first.Replayed || first.Metadata.ContentHash == "" {
+		t.Errorf("Expected a new image with a content hash, got %+v", first.Metadata)
+	}
+
+	for name, key := range map[string]string{"same key": "key-1", "same bytes": "", "new key": "key-2"} {
+		t.Run(name, func(t *testing.T) {
+			resp, err := upload(key, testPNG(t, 4, 4))
+			if err != nil || !resp.Success {
+				t.Fatalf("Upload failed: %v %v", err, resp)
+			}
+			if !resp.Replayed || resp.ImageId != first.ImageId {
+				t.Errorf("Expected replay of %s, got %s (replayed=%v)", first.ImageId, resp.ImageId, resp.Replayed)
+			}
+		})
+	}
+
+	if _, err := upload("key-1", testPNG(t, 8, 4)); !errors.Is(err, ErrIdempotencyKeyMismatch) {
+		t.Errorf("Expected ErrIdempotencyKeyMismatch, got %v", err)
+	}
+
+	// The public copy and the private original
+	files, _ := storage.ListFiles(ctx)
+	if len(files) != 2 {
+		t.Errorf("Expected two stored files, found %d", len(files))
+	}
+}
+
+func TestUploadImageValidation(t *testing.T) {
+	ctx := context.Background()
+	storage, dbService := newTestBackends(t)
+	service := NewImageService(storage, dbService)
+
+	valid := testPNG(t, 4, 4)
+	tests := map[string]struct {
+		data        []byte
+		unsupported bool
+	}{
+		"unsupported": {[]byte("%PDF-1.7"), true},
+		"empty":       {nil, true},
+		"truncated":   {valid[:len(valid)/2], false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := service.UploadImage(ctx, &pb.UploadImageRequest{Title: name, ImageData: tt.data})
+			if status.Code(err) != codes.InvalidArgument {
+				t.Fatalf("Expected InvalidArgument, got %v", err)
+			}
+			if errors.Is(err, ErrUnsupportedImageType) != tt.unsupported {
+				t.Errorf("Expected unsupported=%v, got %v", tt.unsupported, err)
+			}
+		})
+	}
+
+	resp, err := service.UploadImage(ctx, &pb.UploadImageRequest{Title: "valid", ImageData: valid})
+	if err != nil || !resp.Success {
+		t.Fatalf("Upload failed: %v %v", err, resp)
+	}
+	if resp.Metadata.MimeType != "image/png" {
+		t.Errorf("Expected image/png, got %q", resp.Metadata.MimeType)
+	}
+
+	files, _ := storage.ListFiles(ctx)
+	if len(files) != 2 || !strings.HasSuffix(files[0], "_original.png") || !strings.HasSuffix(files[1], "valid.png") {
+		t.Errorf("Expected a public and an original .png file, found %v", files)
+	}
+}
+
+func TestUploadImageLimits(t *testing.T) {
+	ctx := context.Background()
+	storage, dbService := newTestBackends(t)
+	config := DefaultImageConfig()
+	config.Limits = imaging.Limits{MaxBytes: 4096, MaxPixels: 64}
+	service := NewImageServiceWithConfig(storage, dbService, config)
+
+	tests := map[string]struct {
+		data  []byte
+		code  codes.Code
+		limit string
+	}{
+		"bytes":  {make([]byte, 4097), codes.ResourceExhausted, imaging.LimitBytes},
+		"pixels": {testPNG(t, 16, 16), codes.FailedPrecondition, imaging.LimitPixels},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := service.UploadImage(ctx, &pb.UploadImageRequest{Title: name, ImageData: tt.data})
+			if status.Code(err) != tt.code {
+				t.Fatalf("Expected %v, got %v", tt.code, err)
+			}
+
+			// The violated limit must survive the conversion to a gRPC status
+			limitErr := UploadLimitFromError(status.ErrorProto(status.Convert(err).Proto()))
+			if limitErr == nil || limitErr.Limit != tt.limit {
+				t.Fatalf("Expected a %s limit error, got %v", tt.limit, limitErr)
+			}
+		})
+	}
+
+	if files, _ := storage.ListFiles(ctx); len(files) != 0 {
+		t.Errorf("Expected rejected uploads to store nothing, found %v", files)
+	}
+	if resp, err := service.UploadImage(ctx, &pb.UploadImageRequest{Title: "small", ImageData: testPNG(t, 8, 8)}); err != nil || !resp.Success {
+		t.Fatalf("Upload within limits failed: %v %v", err, resp)
+	}
+}
+
+func TestUploadImageSanitization(t *testing.T) {
+	ctx := context.Background()
+	storage, dbService := newTestBackends(t)
+	config := DefaultImageConfig()
+	config.VariantWidths = nil
+	config.CoordinatePrecision = 2
+	service := NewImageServiceWithConfig(storage, dbService, config)
+
+	// Insert a text chunk after the signature and IHDR chunk
+	data := testPNG(t, 4, 4)
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len("Comment\x00home address")))
+	chunk = append(chunk, "tEXtComment\x00home address"...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+	withText := append(append(append([]byte(nil), data[:33]...), chunk...), data[33:]...)
+
+	resp, err := service.UploadImage(ctx, &pb.UploadImageRequest{
+		Title:     "Home",
+		Location:  &pb.Location{Name: "Home", Latitude: 47.376912, Longitude: 8.541694},
+		ImageData: withText,
+	})
+	if err != nil || !resp.Success {
+		t.Fatalf("Upload failed: %v %v", err, resp)
+	}
+
+	public, err := service.GetImageData(ctx, resp.ImageId, ImageDataOptions{})
+	if err != nil {
+		t.Fatalf("GetImageData failed: %v", err)
+	}
+	if bytes.Contains(public.Data, []byte("home address")) {
+		t.Error("Expected the served image to be stripped of its metadata")
+	}
+
+	original, err := storage.GetFile(ctx, resp.Metadata.OriginalFileId)
+	if err != nil || !bytes.Equal(original, withText) {
+		t.Errorf("Expected the unmodified upload to be kept as the original (%v)", err)
+	}
+
+	if location := resp.Metadata.Location; location.Latitude != 47.38 || location.Longitude != 8.54 {
+		t.Errorf("Expected coordinates rounded to 47.38,8.54, got %v,%v", location.Latitude, location.Longitude)
+	}
+
+	if _, err := service.DeleteImage(ctx, &pb.DeleteImageRequest{ImageId: resp.ImageId}); err != nil {
+		t.Fatalf("DeleteImage failed: %v", err)
+	}
+	if files, _ := storage.ListFiles(ctx); len(files) != 0 {
+		t.Errorf("Expected the public copy and the original to be deleted, found %v", files)
+	}
+}
+
+// jpegWithCameraModel returns a small JPEG whose EXIF data names the camera model
+func jpegWithCameraModel(t *testing.T, model string) []byte {
+	t.Helper()
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, image.NewGray(image.Rect(0, 0, 8, 4)), nil); err != nil {
+		t.Fatalf("Failed to encode JPEG: %v", err)
+	}
+
+	// A little-endian TIFF block with a single Model entry in IFD0, its value
+	// following the IFD
+	value := append([]byte(model), 0)
+	tiff := []byte("II*\x00")
+	tiff = binary.LittleEndian.AppendUint32(tiff, 8)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x0110)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 2)
+	tiff = binary.LittleEndian.AppendUint32(tiff, uint32(len(value)))
+	tiff = binary.LittleEndian.AppendUint32(tiff, 26)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 0)
+	tiff = append(tiff, value...)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	data := []byte{0xff, 0xd8, 0xff, 0xe1}
+	data = binary.BigEndian.AppendUint16(data, uint16(len(payload)+2))
+	data = append(data, payload...)
+	return append(data, encoded.Bytes()[2:]...)
+}
+
+func TestBackfillCaptureMetadataSanitized(t *testing.T) {
+	ctx := context.Background()
+	storage, dbService := newTestBackends(t)
+	config := DefaultImageConfig()
+	config.VariantWidths = nil
+	service := NewImageServiceWithConfig(storage, dbService, config)
+
+	resp, err := service.UploadImage(ctx, &pb.UploadImageRequest{ImageData: jpegWithCameraModel(t, "Test Camera")})
+	if err != nil || !resp.Success {
+		t.Fatalf("Upload failed: %v %v", err, resp)
+	}
+	if resp.Metadata.OriginalFileId == "" || resp.Metadata.CameraModel != "Test Camera" {
+		t.Fatalf("Expected a sanitized upload with its camera model, got %v", resp.Metadata)
+	}
+
+	// Forget the capture metadata, as for an image uploaded before it was recorded
+	image := resp.Metadata
+	image.TakenAt = nil
+	image.CameraModel = ""
+	if err := dbService.CreateImage(ctx, image); err != nil {
+		t.Fatalf("CreateImage failed: %v", err)
+	}
+
+	updated, errs, err := service.BackfillCaptureMetadata(ctx)
+	if err != nil || len(errs) != 0 {
+		t.Fatalf("BackfillCaptureMetadata failed: %v %v", err, errs)
+	}
+	if len(updated) != 1 || updated[0] != resp.ImageId {
+		t.Errorf("Expected %s to be updated, got %v", resp.ImageId, updated)
+	}
+	if got, _ := service.GetImageById(ctx, &pb.GetImageByIdRequest{ImageId: resp.ImageId}); got.Metadata.CameraModel != "Test Camera" {
+		t.Errorf("Expected the camera model to be read from the original, got %q", got.Metadata.CameraModel)
+	}
+}
+
+func TestListImagesFilters(t *testing.T) {
+	ctx := context.Background()
+	storage, dbService := newTestBackends(t)
+	service := NewImageService(storage, dbService)
+
+	images := []*pb.ImageMetadata{
+		{Id: "fuji", Title: "Fuji", DriveFileId: "f1", TakenAt: timestamppb.New(time.Date(2023, 7, 14, 18, 0, 0, 0, time.UTC)), CameraMake: "FUJIFILM", CameraModel: "X-T4", Iso: 400},
+		{Id: "canon", Title: "Canon", DriveFileId: "f2", TakenAt: timestamppb.New(time.Date(2021, 3, 1, 9, 0, 0, 0, time.UTC)), CameraMake: "Canon", CameraModel: "EOS R5", Iso: 100},
+		{Id: "scan", Title: "Scan", DriveFileId: "f3"},
+	}
+	for _, image := range images {
+		if err := dbService.CreateImage(ctx, image); err != nil {
+			t.Fatalf("CreateImage failed: %v", err)
+		}
+	}
+
+	tests := map[string]struct {
+		req      *pb.ListImagesRequest
+		expected []string
+	}{
+		"taken_at ascending":  {&pb.ListImagesRequest{SortBy: "taken_at", SortOrder: "asc"}, []string{"canon", "fuji", "scan"}},
+		"taken_at descending": {&pb.ListImagesRequest{SortBy: "taken_at"}, []string{"fuji", "canon", "scan"}},
+		"camera make":         {&pb.ListImagesRequest{CameraMake: "fujifilm"}, []string{"fuji"}},
+		"taken after":         {&pb.ListImagesRequest{TakenAfter: timestamppb.New(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))}, []string{"fuji"}},
+		"min iso":             {&pb.ListImagesRequest{MinIso: proto.Int32(200)}, []string{"fuji"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := service.ListImages(ctx, tt.req)
+			if err != nil || !resp.Success {
+				t.Fatalf("ListImages failed: %v %v", err, resp)
+			}
+			var ids []string
+			for _, image := range resp.Images {
+				ids = append(ids, image.Id)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+
+	if _, err := service.ListImages(ctx, &pb.ListImagesRequest{SortBy: "title"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unknown sort key, got %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"log"
+	"sort"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/imaging"
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+)
+
+// ImageDataOptions selects which rendition of an image to return
+type ImageDataOptions struct {
+	// Width requests the narrowest variant at least this wide; 0 returns the original.
+	// With Aspect, the crop is scaled to the narrowest configured variant width at
+	// least this wide, and never made wider than the full crop.
+	Width int
+	// Aspect requests a crop around the focal point; the zero value keeps the original shape
+	Aspect imaging.AspectRatio
+	// IncludeHidden serves an image outside its publish window
+	IncludeHidden bool
+}
+
+// ImageData holds the bytes of an image rendition
+type ImageData struct {
+	Data     []byte
+	Metadata *pb.ImageMetadata
+	// FileID is the storage file ID of the rendition; empty when it could not be cached
+	FileID string
+	// MimeType is empty when the type has to be detected from the data
+	MimeType string
+}
+
+// GenerateVariants creates the configured variants for an existing image,
+// replacing variants of the same width
+func (s *ImageService) GenerateVariants(ctx context.Context, imageID string) ([]*interfaces.ImageVariant, error) {
+	imageInterface, err := s.dbService.GetImage(ctx, imageID)
+	if err != nil {
+		return nil, ErrImageNotFound
+	}
+
+	image, ok := imageInterface.(*pb.ImageMetadata)
+	if !ok {
+		return nil, fmt.Errorf("invalid image data type")
+	}
+
+	data, err := s.storage.GetFile(ctx, image.DriveFileId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %v", err)
+	}
+
+	existing, err := s.dbService.ListImageVariants(ctx, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := imaging.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := s.createVariants(ctx, image, img)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove the files of replaced variants
+	for _, old := range existing {
+		for _, variant := range variants {
+			if old.Name == variant.Name && old.StorageFileID != variant.StorageFileID {
+				if err := s.storage.DeleteFile(ctx, old.StorageFileID); err != nil {
+					log.Printf("Warning: failed to delete replaced variant %s of image %s: %v", old.Name, imageID, err)
+				}
+			}
+		}
+	}
+
+	return variants, nil
+}
+
+// createVariants resizes the image to each configured width smaller than the
+// original, stores the results and records them in the database
+func (s *ImageService) createVariants(ctx context.Context, metadata *pb.ImageMetadata, img image.Image) ([]*interfaces.ImageVariant, error) {
+	if len(s.config.VariantWidths) == 0 {
+		return nil, nil
+	}
+
+	// Resize from the widest variant down, reusing each result as the next source
+	widths := append([]int(nil), s.config.VariantWidths...)
+	sort.Sort(sort.Reverse(sort.IntSlice(widths)))
+
+	var variants []*interfaces.ImageVariant
+	source := img
+	for _, width := range widths {
+		if width >= img.Bounds().Dx() {
+			continue
+		}
+
+		resized := imaging.ResizeToWidth(source, width)
+		source = resized
+
+		encoded, format, err := imaging.Encode(resized, s.config.JPEGQuality)
+		if err != nil {
+			return variants, err
+		}
+
+		variant := &interfaces.ImageVariant{
+			ImageID:   metadata.Id,
+			Name:      fmt.Sprintf("w%d", width),
+			Width:     resized.Bounds().Dx(),
+			Height:    resized.Bounds().Dy(),
+			MimeType:  format.MimeType,
+			SizeBytes: int64(len(encoded)),
+		}
+
+		filename := fmt.Sprintf("%s_%s%s", metadata.Id, variant.Name, format.Extension)
+		var pendingUpload *interfaces.PendingOperation
+		variant.StorageFileID, pendingUpload, err = s.uploadFile(ctx, metadata.Id, filename, format.MimeType, encoded)
+		if err != nil {
+			return variants, fmt.Errorf("failed to upload variant %s: %v", variant.Name, err)
+		}
+
+		if err := s.dbService.CreateImageVariant(ctx, variant); err != nil {
+			s.compensateUpload(pendingUpload, metadata.Id, variant.StorageFileID)
+			return variants, err
+		}
+		completePendingOperation(ctx, s.dbService, pendingUpload)
+
+		s.mirrorMetadataFor(ctx, variant.StorageFileID, variant)
+		variants = append(variants, variant)
+	}
+
+	return variants, nil
+}
+
+// loadImageData downloads the rendition of an image selected by opts
+func (s *ImageService) loadImageData(ctx context.Context, image *pb.ImageMetadata, opts ImageDataOptions) (*ImageData, error) {
+	if image.DriveFileId == "" {
+		return nil, fmt.Errorf("image %s has no stored file", image.Id)
+	}
+	if !opts.Aspect.IsZero() {
+		return s.loadCropData(ctx, image, opts)
+	}
+
+	result := &ImageData{
+		Metadata: image,
+		FileID:   image.DriveFileId,
+		MimeType: image.MimeType,
+	}
+
+	if opts.Width > 0 {
+		variants, err := s.dbService.ListImageVariants(ctx, image.Id)
+		if err != nil {
+			log.Printf("Warning: failed to list variants of image %s: %v", image.Id, err)
+		}
+		if variant := selectVariant(variants, opts.Width); variant != nil {
+			result.FileID = variant.StorageFileID
+			result.MimeType = variant.MimeType
+		}
+	}
+
+	data, err := s.storage.GetFile(ctx, result.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %v", err)
+	}
+	result.Data = data
+
+	return result, nil
+}
+
+// selectVariant returns the narrowest variant at least width pixels wide, or
+// nil when the original is needed
+func selectVariant(variants []*interfaces.ImageVariant, width int) *interfaces.ImageVariant {
+	var selected *interfaces.ImageVariant
+	for _, variant := range variants {
+		if IsCropVariant(variant) {
+			continue
+		}
+		if variant.Width >= width && (selected == nil || variant.Width < selected.Width) {
+			selected = variant
+		}
+	}
+	return selected
+}
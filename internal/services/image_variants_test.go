@@ -0,0 +1,169 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/imaging"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testPNG returns an opaque PNG of the given size
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestImageVariants(t *testing.T) {
+	ctx := context.Background()
+	storage, dbService := newTestBackends(t)
+	service := NewImageServiceWithConfig(storage, dbService, ImageConfig{VariantWidths: []int{100, 200, 800}, JPEGQuality: 80})
+
+	resp, err := service.UploadImage(ctx, &pb.UploadImageRequest{
+		Title:     "Gradient",
+		Location:  &pb.Location{Name: "Studio"},
+		ImageData: testPNG(t, 400, 200),
+	})
+	if err != nil || !resp.Success {
+		t.Fatalf("Upload failed: %v %v", err, resp)
+	}
+
+	variants, err := dbService.ListImageVariants(ctx, resp.ImageId)
+	if err != nil {
+		t.Fatalf("ListImageVariants failed: %v", err)
+	}
+	if len(variants) != 2 || variants[0].Width != 100 || variants[0].Height != 50 || variants[1].Width != 200 {
+		t.Fatalf("Expected 100 and 200 pixel variants, got %+v", variants)
+	}
+	if operations, _ := dbService.ListPendingOperations(ctx); len(operations) != 0 {
+		t.Errorf("Expected the variant uploads to complete their pending operations, found %d", len(operations))
+	}
+
+	tests := []struct {
+		width    int
+		expected string
+	}{
+		{0, resp.Metadata.DriveFileId},
+		{80, variants[0].StorageFileID},
+		{150, variants[1].StorageFileID},
+		{300, resp.Metadata.DriveFileId},
+	}
+	for _, tt := range tests {
+		data, err := service.GetImageData(ctx, resp.ImageId, ImageDataOptions{Width: tt.width})
+		if err != nil {
+			t.Fatalf("GetImageData(w=%d) failed: %v", tt.width, err)
+		}
+		if data.FileID != tt.expected {
+			t.Errorf("GetImageData(w=%d) returned file %s, expected %s", tt.width, data.FileID, tt.expected)
+		}
+	}
+
+	if _, err := service.DeleteImage(ctx, &pb.DeleteImageRequest{ImageId: resp.ImageId}); err != nil {
+		t.Fatalf("DeleteImage failed: %v", err)
+	}
+	files, _ := storage.ListFiles(ctx)
+	if len(files) != 0 {
+		t.Errorf("Expected the original and variants to be deleted, found %v", files)
+	}
+}
+
+func TestImageCrops(t *testing.T) {
+	ctx := context.Background()
+	storage, dbService := newTestBackends(t)
+	service := NewImageServiceWithConfig(storage, dbService, ImageConfig{
+		VariantWidths: []int{100},
+		JPEGQuality:   80,
+		CropAspects:   []imaging.AspectRatio{{Width: 9, Height: 16}},
+	})
+
+	resp, err := service.UploadImage(ctx, &pb.UploadImageRequest{
+		Title:     "Gradient",
+		Location:  &pb.Location{Name: "Studio"},
+		ImageData: testPNG(t, 400, 200),
+	})
+	if err != nil || !resp.Success {
+		t.Fatalf("Upload failed: %v %v", err, resp)
+	}
+	if resp.Metadata.FocalX == nil || resp.Metadata.FocalY == nil {
+		t.Fatal("Expected a focal point to be detected on upload")
+	}
+
+	opts := ImageDataOptions{Width: 90, Aspect: imaging.AspectRatio{Width: 9, Height: 16}}
+	crop, err := service.GetImageData(ctx, resp.ImageId, opts)
+	if err != nil {
+		t.Fatalf("GetImageData failed: %v", err)
+	}
+	img, _, err := imaging.Decode(crop.Data)
+	if err != nil {
+		t.Fatalf("Crop does not decode: %v", err)
+	}
+	// The width is rounded up to the configured variant width
+	if size := img.Bounds().Size(); size.X != 100 || size.Y != 178 {
+		t.Errorf("Expected a 100x178 crop, got %v", size)
+	}
+
+	cached, err := service.GetImageData(ctx, resp.ImageId, opts)
+	if err != nil || cached.FileID == "" || cached.FileID != crop.FileID {
+		t.Errorf("Expected the crop to be served from storage, got %q and %q (%v)", crop.FileID, cached.FileID, err)
+	}
+
+	// Widths beyond the full 112 pixel crop share one cached file
+	full, err := service.GetImageData(ctx, resp.ImageId, ImageDataOptions{Aspect: opts.Aspect})
+	if err != nil || full.FileID == "" {
+		t.Fatalf("Expected the full crop to be cached, got %v %v", full, err)
+	}
+	wide, err := service.GetImageData(ctx, resp.ImageId, ImageDataOptions{Width: 5000, Aspect: opts.Aspect})
+	if err != nil || wide.FileID != full.FileID {
+		t.Errorf("Expected the full crop for a wide request, got %q and %q (%v)", full.FileID, wide.FileID, err)
+	}
+
+	// Ratios that are not configured are rejected rather than cropped on every request
+	if _, err := service.GetImageData(ctx, resp.ImageId, ImageDataOptions{Aspect: imaging.AspectRatio{Width: 1, Height: 1}}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unconfigured aspect ratio, got %v", err)
+	}
+	if variants, _ := dbService.ListImageVariants(ctx, resp.ImageId); len(variants) != 3 {
+		t.Errorf("Expected the width variant and two crops, got %d variants", len(variants))
+	}
+
+	// The width variant is not replaced by the narrower crop
+	resized, err := service.GetImageData(ctx, resp.ImageId, ImageDataOptions{Width: 90})
+	if err != nil || resized.FileID == crop.FileID {
+		t.Errorf("Expected the resized variant, got %q (%v)", resized.FileID, err)
+	}
+
+	focalX := 0.1
+	if _, err := service.UpdateImage(ctx, &pb.UpdateImageRequest{ImageId: resp.ImageId, FocalX: &focalX}); err != nil {
+		t.Fatalf("UpdateImage failed: %v", err)
+	}
+	variants, _ := dbService.ListImageVariants(ctx, resp.ImageId)
+	for _, variant := range variants {
+		if IsCropVariant(variant) {
+			t.Errorf("Expected cached crops to be removed after the focal point changed, found %s", variant.Name)
+		}
+	}
+	if _, err := storage.GetFile(ctx, crop.FileID); err == nil {
+		t.Error("Expected the cached crop file to be deleted")
+	}
+
+	invalid := 1.5
+	_, err = service.UpdateImage(ctx, &pb.UpdateImageRequest{ImageId: resp.ImageId, FocalY: &invalid})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an out-of-range focal point, got %v", err)
+	}
+}
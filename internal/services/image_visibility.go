@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// imageVisibility returns the interfaces.Visibility state of an image at now.
+// An image is published from publish_at (inclusive) until expire_at (exclusive).
+func imageVisibility(image *pb.ImageMetadata, now time.Time) string {
+	if image.PublishAt != nil && image.PublishAt.AsTime().After(now) {
+		return interfaces.VisibilityScheduled
+	}
+	if image.ExpireAt != nil && !image.ExpireAt.AsTime().After(now) {
+		return interfaces.VisibilityExpired
+	}
+	return interfaces.VisibilityVisible
+}
+
+// imageVisible reports whether an image is inside its publish window at now
+func imageVisible(image *pb.ImageMetadata, now time.Time) bool {
+	return imageVisibility(image, now) == interfaces.VisibilityVisible
+}
+
+// validatePublishWindow checks that an image expires after it is published
+func validatePublishWindow(publishAt, expireAt *timestamppb.Timestamp) error {
+	if publishAt != nil && expireAt != nil && !expireAt.AsTime().After(publishAt.AsTime()) {
+		return status.Error(codes.InvalidArgument, "expire_at must be after publish_at")
+	}
+	return nil
+}
+
+// applyPublishWindow applies the publish window changes of an update to image
+func applyPublishWindow(image *pb.ImageMetadata, req *pb.UpdateImageRequest) error {
+	if req.PublishAt != nil && req.ClearPublishAt {
+		return status.Error(codes.InvalidArgument, "publish_at and clear_publish_at cannot both be set")
+	}
+	if req.ExpireAt != nil && req.ClearExpireAt {
+		return status.Error(codes.InvalidArgument, "expire_at and clear_expire_at cannot both be set")
+	}
+
+	publishAt, expireAt := image.PublishAt, image.ExpireAt
+	if req.PublishAt != nil {
+		publishAt = req.PublishAt
+	}
+	if req.ClearPublishAt {
+		publishAt = nil
+	}
+	if req.ExpireAt != nil {
+		expireAt = req.ExpireAt
+	}
+	if req.ClearExpireAt {
+		expireAt = nil
+	}
+	if err := validatePublishWindow(publishAt, expireAt); err != nil {
+		return err
+	}
+
+	image.PublishAt, image.ExpireAt = publishAt, expireAt
+	return nil
+}
+
+// GetImageCount returns the number of images in the requested visibility
+// state, along with the count of each state
+func (s *ImageService) GetImageCount(ctx context.Context, req *pb.GetImageCountRequest) (*pb.GetImageCountResponse, error) {
+	visibility := strings.ToLower(req.GetVisibility())
+	switch visibility {
+	case "", "all", interfaces.VisibilityVisible, interfaces.VisibilityScheduled, interfaces.VisibilityExpired:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "visibility must be all, visible, scheduled or expired, got %q", req.GetVisibility())
+	}
+
+	counts, err := s.dbService.CountImagesByVisibility(ctx, s.now())
+	if err != nil {
+		return &pb.GetImageCountResponse{
+			Count: 0,
+		}, err
+	}
+
+	resp := &pb.GetImageCountResponse{
+		Visible:   counts[interfaces.VisibilityVisible],
+		Scheduled: counts[interfaces.VisibilityScheduled],
+		Expired:   counts[interfaces.VisibilityExpired],
+	}
+	switch visibility {
+	case interfaces.VisibilityVisible:
+		resp.Count = resp.Visible
+	case interfaces.VisibilityScheduled:
+		resp.Count = resp.Scheduled
+	case interfaces.VisibilityExpired:
+		resp.Count = resp.Expired
+	default:
+		resp.Count = resp.Visible + resp.Scheduled + resp.Expired
+	}
+	return resp, nil
+}
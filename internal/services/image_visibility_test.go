@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestPublishWindow(t *testing.T) {
+	ctx := context.Background()
+	storage, dbService := newTestBackends(t)
+	service := NewImageService(storage, dbService)
+
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	if _, err := service.UploadImage(ctx, &pb.UploadImageRequest{
+		ImageData: testPNG(t, 8, 8),
+		PublishAt: timestamppb.New(now),
+		ExpireAt:  timestamppb.New(now.Add(-time.Hour)),
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an expiry before publishing, got %v", err)
+	}
+
+	// always is always visible, seasonal is queued for tomorrow and retiring expires in an hour
+	upload := func(size int, publishAt, expireAt *timestamppb.Timestamp) string {
+		t.Helper()
+		resp, err := service.UploadImage(ctx, &pb.UploadImageRequest{ImageData: testPNG(t, size, size), PublishAt: publishAt, ExpireAt: expireAt})
+		if err != nil || !resp.Success {
+			t.Fatalf("Upload failed: %v %v", err, resp)
+		}
+		return resp.ImageId
+	}
+	always := upload(8, nil, nil)
+	retiring := upload(9, timestamppb.New(now.Add(-time.Hour)), timestamppb.New(now.Add(time.Hour)))
+	seasonal := upload(10, timestamppb.New(now.Add(24*time.Hour)), nil)
+
+	listed := func(includeHidden bool) []string {
+		t.Helper()
+		resp, err := service.ListImages(ctx, &pb.ListImagesRequest{IncludeHidden: includeHidden})
+		if err != nil || !resp.Success {
+			t.Fatalf("ListImages failed: %v %v", err, resp)
+		}
+		var ids []string
+		for _, image := range resp.Images {
+			ids = append(ids, image.Id)
+		}
+		return ids
+	}
+	current := func() string {
+		t.Helper()
+		resp, err := service.GetCurrentImage(ctx, &pb.GetCurrentImageRequest{})
+		if err != nil || !resp.Success {
+			t.Fatalf("GetCurrentImage failed: %v %v", err, resp)
+		}
+		return resp.Metadata.Id
+	}
+	count := func(visibility string) *pb.GetImageCountResponse {
+		t.Helper()
+		resp, err := service.GetImageCount(ctx, &pb.GetImageCountRequest{Visibility: visibility})
+		if err != nil {
+			t.Fatalf("GetImageCount failed: %v", err)
+		}
+		return resp
+	}
+
+	if ids := listed(false); len(ids) != 2 || ids[0] != retiring || ids[1] != always {
+		t.Errorf("Expected the published images, got %v", ids)
+	}
+	if ids := listed(true); len(ids) != 3 {
+		t.Errorf("Expected every image with include_hidden, got %v", ids)
+	}
+	if resp, _ := service.GetImageById(ctx, &pb.GetImageByIdRequest{ImageId: seasonal}); resp.Success {
+		t.Errorf("Expected a scheduled image to be hidden, got %v", resp.Metadata)
+	}
+	if resp, _ := service.GetImageById(ctx, &pb.GetImageByIdRequest{ImageId: seasonal, IncludeHidden: true}); !resp.Success || resp.Metadata.PublishAt == nil {
+		t.Errorf("Expected the scheduled image with include_hidden, got %v", resp)
+	}
+	if id := current(); id != retiring {
+		t.Errorf("Expected the newest published image to be current, got %s", id)
+	}
+	if resp := count(""); resp.Count != 3 || resp.Visible != 2 || resp.Scheduled != 1 || resp.Expired != 0 {
+		t.Errorf("Unexpected counts: %v", resp)
+	}
+	if _, err := service.GetImageCount(ctx, &pb.GetImageCountRequest{Visibility: "hidden"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unknown visibility, got %v", err)
+	}
+
+	// The window end is exclusive
+	now = now.Add(time.Hour)
+	if id := current(); id != always {
+		t.Errorf("Expected the expired image to retire, got %s", id)
+	}
+	if resp := count("expired"); resp.Count != 1 {
+		t.Errorf("Expected one expired image, got %v", resp)
+	}
+	if _, err := service.GetImageData(ctx, retiring, ImageDataOptions{}); !errors.Is(err, ErrImageNotFound) {
+		t.Errorf("Expected the data of an expired image to be hidden, got %v", err)
+	}
+	if _, err := service.GetImageData(ctx, retiring, ImageDataOptions{IncludeHidden: true}); err != nil {
+		t.Errorf("Expected the data of an expired image with IncludeHidden, got %v", err)
+	}
+
+	now = now.Add(24 * time.Hour)
+	if id := current(); id != seasonal {
+		t.Errorf("Expected the seasonal image once published, got %s", id)
+	}
+	if resp := count("visible"); resp.Count != 2 {
+		t.Errorf("Expected two visible images, got %v", resp)
+	}
+
+	// Updates can move or clear the window
+	if _, err := service.UpdateImage(ctx, &pb.UpdateImageRequest{ImageId: retiring, ExpireAt: timestamppb.New(now.Add(-48 * time.Hour))}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an expiry before publishing, got %v", err)
+	}
+	updated, err := service.UpdateImage(ctx, &pb.UpdateImageRequest{ImageId: retiring, ClearExpireAt: true})
+	if err != nil || !updated.Success || updated.Metadata.ExpireAt != nil {
+		t.Fatalf("UpdateImage failed: %v %v", err, updated)
+	}
+	if ids := listed(false); len(ids) != 3 {
+		t.Errorf("Expected the image to be listed again without an expiry, got %v", ids)
+	}
+}
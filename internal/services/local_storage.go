@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LocalStorage stores image files on the local filesystem.
+// It is intended for local development and CI where Google credentials are unavailable.
+type LocalStorage struct {
+	basePath string
+}
+
+// NewLocalStorage creates a new filesystem-backed storage rooted at basePath
+func NewLocalStorage(basePath string) (*LocalStorage, error) {
+	if basePath == "" {
+		return nil, fmt.Errorf("local storage path is required")
+	}
+
+	absPath, err := filepath.Abs(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve storage path: %v", err)
+	}
+
+	if err := os.MkdirAll(absPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %v", err)
+	}
+
+	return &LocalStorage{basePath: absPath}, nil
+}
+
+// UploadFile writes data to a new file and returns its file ID. The MIME type is
+// not stored; the filename extension identifies the format.
+func (l *LocalStorage) UploadFile(ctx context.Context, filename, mimeType string, data []byte) (string, error) {
+	fileID := fmt.Sprintf("%d_%s", time.Now().UnixNano(), sanitizeFilename(filename))
+
+	if err := os.WriteFile(filepath.Join(l.basePath, fileID), data, 0644); err != nil {
+		return "", fmt.Errorf("upload failed: %v", err)
+	}
+
+	return fileID, nil
+}
+
+// GetFile reads the contents of a stored file
+func (l *LocalStorage) GetFile(ctx context.Context, fileID string) ([]byte, error) {
+	path, err := l.filePath(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %v", err)
+	}
+
+	return data, nil
+}
+
+// GetFileURL returns a file:// URL for a stored file
+func (l *LocalStorage) GetFileURL(ctx context.Context, fileID string) (string, error) {
+	path, err := l.filePath(fileID)
+	if err != nil {
+		return "", err
+	}
+
+	return "file://" + filepath.ToSlash(path), nil
+}
+
+// DeleteFile removes a stored file
+func (l *LocalStorage) DeleteFile(ctx context.Context, fileID string) error {
+	path, err := l.filePath(fileID)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("delete failed: %v", err)
+	}
+
+	return nil
+}
+
+// ListFiles returns the IDs of all stored files
+func (l *LocalStorage) ListFiles(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(l.basePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %v", err)
+	}
+
+	var fileIDs []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			fileIDs = append(fileIDs, entry.Name())
+		}
+	}
+	return fileIDs, nil
+}
+
+// filePath resolves a file ID to a path inside the storage directory
+func (l *LocalStorage) filePath(fileID string) (string, error) {
+	if fileID == "" || fileID != filepath.Base(fileID) || strings.HasPrefix(fileID, ".") {
+		return "", fmt.Errorf("invalid file ID: %q", fileID)
+	}
+
+	return filepath.Join(l.basePath, fileID), nil
+}
+
+// sanitizeFilename replaces characters that are unsafe in file names
+func sanitizeFilename(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, filepath.Base(name))
+
+	name = strings.TrimLeft(name, ".")
+	if name == "" {
+		name = "file"
+	}
+	return name
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+
+	storage, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create local storage: %v", err)
+	}
+
+	t.Run("upload_get_delete", func(t *testing.T) {
+		fileID, err := storage.UploadFile(ctx, "img_1_My Photo.jpg", "image/jpeg", []byte("image-bytes"))
+		if err != nil {
+			t.Fatalf("UploadFile failed: %v", err)
+		}
+
+		data, err := storage.GetFile(ctx, fileID)
+		if err != nil {
+			t.Fatalf("GetFile failed: %v", err)
+		}
+		if string(data) != "image-bytes" {
+			t.Errorf("Expected stored data %q, got %q", "image-bytes", string(data))
+		}
+
+		if err := storage.DeleteFile(ctx, fileID); err != nil {
+			t.Fatalf("DeleteFile failed: %v", err)
+		}
+
+		if _, err := storage.GetFile(ctx, fileID); err == nil {
+			t.Error("Expected error reading deleted file")
+		}
+	})
+
+	t.Run("rejects_path_traversal", func(t *testing.T) {
+		for _, fileID := range []string{"../secret", "a/b", "..", ""} {
+			if _, err := storage.GetFile(ctx, fileID); err == nil {
+				t.Errorf("Expected error for file ID %q", fileID)
+			}
+		}
+	})
+
+	t.Run("sanitizes_filenames", func(t *testing.T) {
+		fileID, err := storage.UploadFile(ctx, "../../etc/passwd", "", []byte("x"))
+		if err != nil {
+			t.Fatalf("UploadFile failed: %v", err)
+		}
+		if _, err := storage.filePath(fileID); err != nil {
+			t.Errorf("Expected sanitized file ID, got %q: %v", fileID, err)
+		}
+	})
+}
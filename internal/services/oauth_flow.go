@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/drive/v3"
+)
+
+// oauthStateTTL is how long an authorization request stays valid
+const oauthStateTTL = 10 * time.Minute
+
+// ErrNotAuthorized is returned when Drive is used before an OAuth token exists
+var ErrNotAuthorized = errors.New("google drive is not authorized; visit /api/v1/admin/oauth/authorize")
+
+// OAuthConfig holds OAuth2 configuration
+type OAuthConfig struct {
+	Web struct {
+		ClientID     string   `json:"client_id"`
+		ClientSecret string   `json:"client_secret"`
+		RedirectURIs []string `json:"redirect_uris"`
+	} `json:"web"`
+}
+
+// OAuthStatus describes the state of the stored OAuth2 token
+type OAuthStatus struct {
+	Authorized       bool       `json:"authorized"`
+	Valid            bool       `json:"valid"`
+	HasRefreshToken  bool       `json:"has_refresh_token"`
+	Expiry           *time.Time `json:"expiry,omitempty"`
+	ExpiresInSeconds int64      `json:"expires_in_seconds"`
+}
+
+// OAuthFlow runs the browser-based OAuth2 authorization flow and
+// provides tokens from the configured token store
+type OAuthFlow struct {
+	config *oauth2.Config
+	store  TokenStore
+
+	mu        sync.Mutex
+	source    oauth2.TokenSource
+	lastToken *oauth2.Token
+}
+
+// LoadOAuthConfig reads OAuth2 client credentials from a JSON file.
+// OAUTH_REDIRECT_URL overrides the first redirect URI in the file.
+func LoadOAuthConfig(oauthConfigPath string) (*oauth2.Config, error) {
+	configData, err := os.ReadFile(oauthConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OAuth config: %v", err)
+	}
+
+	var oauthConfig OAuthConfig
+	if err := json.Unmarshal(configData, &oauthConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse OAuth config: %v", err)
+	}
+
+	redirectURL := os.Getenv("OAUTH_REDIRECT_URL")
+	if redirectURL == "" && len(oauthConfig.Web.RedirectURIs) > 0 {
+		redirectURL = oauthConfig.Web.RedirectURIs[0]
+	}
+	if redirectURL == "" {
+		return nil, fmt.Errorf("OAuth config has no redirect URI and OAUTH_REDIRECT_URL is not set")
+	}
+
+	return &oauth2.Config{
+		ClientID:     oauthConfig.Web.ClientID,
+		ClientSecret: oauthConfig.Web.ClientSecret,
+		RedirectURL:  redirectURL,
+		// The full Drive scope is needed to see files added to the folder by
+		// hand, which drive.file hides. Tokens granted before the change must be
+		// re-authorized.
+		Scopes: []string{
+			drive.DriveScope,
+		},
+		Endpoint: google.Endpoint,
+	}, nil
+}
+
+// NewOAuthFlow creates a new OAuthFlow that persists tokens in store
+func NewOAuthFlow(config *oauth2.Config, store TokenStore) *OAuthFlow {
+	return &OAuthFlow{
+		config: config,
+		store:  store,
+	}
+}
+
+// AuthCodeURL returns the Google consent URL for a new authorization request
+// with a signed state value that expires after oauthStateTTL
+func (f *OAuthFlow) AuthCodeURL() (string, error) {
+	state, err := f.newState(time.Now().Add(oauthStateTTL))
+	if err != nil {
+		return "", err
+	}
+
+	// Force the consent screen so Google always returns a refresh token
+	return f.config.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce), nil
+}
+
+// newState returns a random nonce and its expiry, signed with the OAuth client
+// secret. Any instance can verify it, so the callback may reach a different
+// instance than the one that started the flow.
+func (f *OAuthFlow) newState(expiresAt time.Time) (string, error) {
+	payload := make([]byte, 16, 24)
+	if _, err := rand.Read(payload); err != nil {
+		return "", fmt.Errorf("failed to generate state: %v", err)
+	}
+	payload = binary.BigEndian.AppendUint64(payload, uint64(expiresAt.Unix()))
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(f.signState(payload)), nil
+}
+
+// verifyState checks the signature and expiry of a state made by newState
+func (f *OAuthFlow) verifyState(state string, now time.Time) error {
+	encodedPayload, encodedSignature, _ := strings.Cut(state, ".")
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil || len(payload) != 24 {
+		return fmt.Errorf("invalid or expired OAuth state")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, f.signState(payload)) {
+		return fmt.Errorf("invalid or expired OAuth state")
+	}
+
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(payload[16:])), 0)
+	if now.After(expiresAt) {
+		return fmt.Errorf("invalid or expired OAuth state")
+	}
+	return nil
+}
+
+// signState returns the HMAC of a state payload
+func (f *OAuthFlow) signState(payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte("oauth-state\x00"+f.config.ClientSecret))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// Exchange validates the callback state, exchanges the authorization code
+// and writes the resulting token to the token store
+func (f *OAuthFlow) Exchange(ctx context.Context, state, code string) (*oauth2.Token, error) {
+	if err := f.verifyState(state, time.Now()); err != nil {
+		return nil, err
+	}
+	if code == "" {
+		return nil, fmt.Errorf("authorization code is required")
+	}
+
+	token, err := f.config.Exchange(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code for token: %v", err)
+	}
+
+	if err := f.store.Save(ctx, token); err != nil {
+		return nil, fmt.Errorf("failed to save token: %v", err)
+	}
+
+	f.mu.Lock()
+	f.source = newPersistingTokenSource(f.config.TokenSource(context.Background(), token), f.store, token)
+	f.lastToken = token
+	f.mu.Unlock()
+
+	return token, nil
+}
+
+// TokenSource returns a token source that reads the token lazily, so Drive
+// clients created before authorization start working once the flow completes
+func (f *OAuthFlow) TokenSource() oauth2.TokenSource {
+	return &flowTokenSource{flow: f}
+}
+
+// Status reports whether a token exists and when it expires
+func (f *OAuthFlow) Status(ctx context.Context) (*OAuthStatus, error) {
+	f.mu.Lock()
+	token := f.lastToken
+	f.mu.Unlock()
+
+	if token == nil {
+		stored, err := f.store.Load(ctx)
+		if err != nil && !errors.Is(err, ErrTokenNotFound) {
+			return nil, err
+		}
+		token = stored
+	}
+
+	status := &OAuthStatus{}
+	if token == nil {
+		return status, nil
+	}
+
+	status.Authorized = true
+	status.Valid = token.Valid()
+	status.HasRefreshToken = token.RefreshToken != ""
+	if !token.Expiry.IsZero() {
+		expiry := token.Expiry
+		status.Expiry = &expiry
+		status.ExpiresInSeconds = int64(time.Until(expiry).Seconds())
+	}
+
+	return status, nil
+}
+
+// loadToken loads the stored token, refreshing it if it has expired
+func (f *OAuthFlow) loadToken(ctx context.Context) error {
+	token, err := f.store.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !token.Valid() {
+		refreshed, err := f.config.TokenSource(ctx, token).Token()
+		if err != nil {
+			return fmt.Errorf("failed to refresh token: %v", err)
+		}
+		if err := f.store.Save(ctx, refreshed); err != nil {
+			return fmt.Errorf("failed to save token: %v", err)
+		}
+		token = refreshed
+	}
+
+	f.mu.Lock()
+	f.source = newPersistingTokenSource(f.config.TokenSource(context.Background(), token), f.store, token)
+	f.lastToken = token
+	f.mu.Unlock()
+
+	return nil
+}
+
+// flowTokenSource resolves tokens through the OAuthFlow on every request
+type flowTokenSource struct {
+	flow *OAuthFlow
+}
+
+// Token implements oauth2.TokenSource
+func (s *flowTokenSource) Token() (*oauth2.Token, error) {
+	s.flow.mu.Lock()
+	source := s.flow.source
+	s.flow.mu.Unlock()
+
+	if source == nil {
+		if err := s.flow.loadToken(context.Background()); err != nil {
+			if errors.Is(err, ErrTokenNotFound) {
+				return nil, ErrNotAuthorized
+			}
+			return nil, err
+		}
+		s.flow.mu.Lock()
+		source = s.flow.source
+		s.flow.mu.Unlock()
+	}
+
+	token, err := source.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	s.flow.mu.Lock()
+	s.flow.lastToken = token
+	s.flow.mu.Unlock()
+
+	return token, nil
+}
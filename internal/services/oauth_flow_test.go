@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/drive/v3"
+)
+
+func TestLoadOAuthConfigScopes(t *testing.T) {
+	t.Setenv("OAUTH_REDIRECT_URL", "")
+	path := filepath.Join(t.TempDir(), "oauth_credentials.json")
+	config := `{"web": {"client_id": "id", "client_secret": "secret", "redirect_uris": ["http://localhost/api/v1/oauth/callback"]}}`
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatalf("Failed to write OAuth config: %v", err)
+	}
+
+	oauthConfig, err := LoadOAuthConfig(path)
+	if err != nil {
+		t.Fatalf("LoadOAuthConfig failed: %v", err)
+	}
+	// drive.file would hide the files added to the folder by hand from sync
+	if len(oauthConfig.Scopes) != 1 || oauthConfig.Scopes[0] != drive.DriveScope {
+		t.Errorf("Expected the full Drive scope, got %v", oauthConfig.Scopes)
+	}
+}
+
+func TestOAuthState(t *testing.T) {
+	now := time.Now()
+	config := &oauth2.Config{ClientID: "id", ClientSecret: "secret"}
+	flow := NewOAuthFlow(config, NewMemoryTokenStore())
+
+	state, err := flow.newState(now.Add(oauthStateTTL))
+	if err != nil {
+		t.Fatalf("newState failed: %v", err)
+	}
+
+	// The callback may reach another instance that shares only the client secret
+	other := NewOAuthFlow(&oauth2.Config{ClientID: "id", ClientSecret: "secret"}, NewMemoryTokenStore())
+	if err := other.verifyState(state, now); err != nil {
+		t.Errorf("Expected another instance to accept the state: %v", err)
+	}
+
+	expired, _ := flow.newState(now.Add(-time.Second))
+	payload, _, _ := strings.Cut(state, ".")
+	_, signature, _ := strings.Cut(expired, ".")
+	foreign := NewOAuthFlow(&oauth2.Config{ClientID: "id", ClientSecret: "other"}, NewMemoryTokenStore())
+	for name, check := range map[string]func() error{
+		"expired":        func() error { return flow.verifyState(expired, now) },
+		"after ttl":      func() error { return flow.verifyState(state, now.Add(oauthStateTTL+time.Second)) },
+		"swapped":        func() error { return flow.verifyState(payload+"."+signature, now) },
+		"other secret":   func() error { return foreign.verifyState(state, now) },
+		"empty":          func() error { return flow.verifyState("", now) },
+		"unsigned nonce": func() error { return flow.verifyState(payload, now) },
+	} {
+		if err := check(); err == nil {
+			t.Errorf("%s: expected the state to be rejected", name)
+		}
+	}
+
+	if _, err := flow.Exchange(context.Background(), expired, "code"); err == nil || !strings.Contains(err.Error(), "state") {
+		t.Errorf("Expected Exchange to reject an expired state, got %v", err)
+	}
+}
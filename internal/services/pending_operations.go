@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+)
+
+// Pending operation types
+const (
+	// OperationUpload guards an uploaded file until its database row exists.
+	// If the row never appears, the file is deleted.
+	OperationUpload = "upload"
+	// OperationDeleteFile deletes a file whose database row is already gone
+	OperationDeleteFile = "delete_file"
+)
+
+const (
+	// pendingOperationGracePeriod keeps the retrier away from operations that are still in progress
+	pendingOperationGracePeriod = 2 * time.Minute
+	// maxPendingOperationAttempts stops retrying; the operation stays recorded for an operator
+	maxPendingOperationAttempts = 10
+	// maxPendingOperationBackoff caps the delay between retries
+	maxPendingOperationBackoff = time.Hour
+)
+
+// OperationRetrier completes or compensates pending storage operations left
+// behind by failed uploads and deletes
+type OperationRetrier struct {
+	storage   interfaces.DriveService
+	dbService interfaces.DatabaseService
+}
+
+// NewOperationRetrier creates a new OperationRetrier instance
+func NewOperationRetrier(storage interfaces.DriveService, dbService interfaces.DatabaseService) *OperationRetrier {
+	return &OperationRetrier{
+		storage:   storage,
+		dbService: dbService,
+	}
+}
+
+// GetRetryIntervalFromEnv returns the retrier interval from PENDING_OPERATION_RETRY_INTERVAL (default 1m)
+func GetRetryIntervalFromEnv() time.Duration {
+	if value := os.Getenv("PENDING_OPERATION_RETRY_INTERVAL"); value != "" {
+		if interval, err := time.ParseDuration(value); err == nil && interval > 0 {
+			return interval
+		}
+		log.Printf("Warning: invalid PENDING_OPERATION_RETRY_INTERVAL %q, using 1m", value)
+	}
+	return time.Minute
+}
+
+// Start runs the retrier every interval until ctx is cancelled
+func (r *OperationRetrier) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := r.RunOnce(ctx); err != nil {
+					log.Printf("Warning: pending operation retry failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// RunOnce processes all due pending operations and returns how many completed
+func (r *OperationRetrier) RunOnce(ctx context.Context) (int, error) {
+	operations, err := r.dbService.ListPendingOperations(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	completed := 0
+	now := time.Now()
+	for _, op := range operations {
+		if op.Attempts >= maxPendingOperationAttempts || op.NextAttemptAt.After(now) {
+			continue
+		}
+
+		if err := r.process(ctx, op); err != nil {
+			recordPendingOperationFailure(ctx, r.dbService, op, err)
+			continue
+		}
+
+		if err := r.dbService.DeletePendingOperation(ctx, op.ID); err != nil {
+			log.Printf("Warning: failed to remove pending operation %d: %v", op.ID, err)
+			continue
+		}
+		completed++
+	}
+
+	return completed, nil
+}
+
+// process completes a single pending operation
+func (r *OperationRetrier) process(ctx context.Context, op *interfaces.PendingOperation) error {
+	imageInterface, err := r.dbService.GetImage(ctx, op.ImageID)
+	image, _ := imageInterface.(*pb.ImageMetadata)
+	imageExists := err == nil && image != nil
+
+	switch op.Operation {
+	case OperationUpload:
+		// The upload finished if the image row or one of its variants references the file
+		if imageExists && (image.DriveFileId == op.FileID || image.OriginalFileId == op.FileID) {
+			return nil
+		}
+		if imageExists {
+			variants, err := r.dbService.ListImageVariants(ctx, op.ImageID)
+			if err != nil {
+				return fmt.Errorf("failed to list variants: %v", err)
+			}
+			for _, variant := range variants {
+				if variant.StorageFileID == op.FileID {
+					return nil
+				}
+			}
+		}
+		return r.storage.DeleteFile(ctx, op.FileID)
+	case OperationDeleteFile:
+		// The database delete did not happen, so the files are still in use
+		if imageExists {
+			return nil
+		}
+		return r.storage.DeleteFile(ctx, op.FileID)
+	default:
+		return fmt.Errorf("unknown pending operation: %s", op.Operation)
+	}
+}
+
+// newPendingOperation creates an operation record that the retrier picks up
+// after the grace period unless it is completed and removed first
+func newPendingOperation(operation, imageID, fileID string) *interfaces.PendingOperation {
+	return &interfaces.PendingOperation{
+		Operation:     operation,
+		ImageID:       imageID,
+		FileID:        fileID,
+		NextAttemptAt: time.Now().Add(pendingOperationGracePeriod),
+	}
+}
+
+// completePendingOperation removes an operation whose work finished inline
+func completePendingOperation(ctx context.Context, dbService interfaces.DatabaseService, op *interfaces.PendingOperation) {
+	if err := dbService.DeletePendingOperation(ctx, op.ID); err != nil {
+		// Leaving the record behind is harmless: the retrier re-checks it before acting
+		log.Printf("Warning: failed to remove pending operation %d: %v", op.ID, err)
+	}
+}
+
+// recordPendingOperationFailure stores a failed attempt and schedules the next one with exponential backoff
+func recordPendingOperationFailure(ctx context.Context, dbService interfaces.DatabaseService, op *interfaces.PendingOperation, cause error) {
+	op.Attempts++
+	op.LastError = cause.Error()
+
+	backoff := pendingOperationGracePeriod << uint(op.Attempts-1)
+	if backoff <= 0 || backoff > maxPendingOperationBackoff {
+		backoff = maxPendingOperationBackoff
+	}
+	op.NextAttemptAt = time.Now().Add(backoff)
+
+	if op.Attempts >= maxPendingOperationAttempts {
+		log.Printf("Error: pending %s of file %s for image %s failed %d times, giving up: %v",
+			op.Operation, op.FileID, op.ImageID, op.Attempts, cause)
+	}
+
+	if err := dbService.UpdatePendingOperation(ctx, op); err != nil {
+		log.Printf("Warning: failed to update pending operation %d: %v", op.ID, err)
+	}
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/database"
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+)
+
+// failingCreateDatabase fails every CreateImage call
+type failingCreateDatabase struct {
+	interfaces.DatabaseService
+}
+
+func (d *failingCreateDatabase) CreateImage(ctx context.Context, image interface{}) error {
+	return errors.New("database unavailable")
+}
+
+func newTestBackends(t *testing.T) (*LocalStorage, interfaces.DatabaseService) {
+	t.Helper()
+
+	storage, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create local storage: %v", err)
+	}
+
+	dbService, err := database.NewSQLiteDatabase(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	t.Cleanup(func() { dbService.Close() })
+
+	return storage, dbService
+}
+
+func TestUploadCompensatesFailedInsert(t *testing.T) {
+	ctx := context.Background()
+	storage, dbService := newTestBackends(t)
+
+	service := NewImageService(storage, &failingCreateDatabase{DatabaseService: dbService})
+	resp, err := service.UploadImage(ctx, &pb.UploadImageRequest{Title: "orphan", ImageData: testPNG(t, 4, 4)})
+	if err != nil {
+		t.Fatalf("UploadImage returned error: %v", err)
+	}
+	if resp.Success {
+		t.Fatal("Expected upload to fail")
+	}
+
+	files, _ := storage.ListFiles(ctx)
+	if len(files) != 0 {
+		t.Errorf("Expected the uploaded file to be deleted, found %v", files)
+	}
+
+	operations, _ := dbService.ListPendingOperations(ctx)
+	if len(operations) != 0 {
+		t.Errorf("Expected no pending operations after compensation, found %d", len(operations))
+	}
+}
+
+func TestOperationRetrier(t *testing.T) {
+	ctx := context.Background()
+	storage, dbService := newTestBackends(t)
+
+	orphanID, _ := storage.UploadFile(ctx, "orphan.jpg", "image/jpeg", []byte("orphan"))
+	keptID, _ := storage.UploadFile(ctx, "kept.jpg", "image/jpeg", []byte("kept"))
+	if err := dbService.CreateImage(ctx, &pb.ImageMetadata{Id: "kept", Title: "kept", DriveFileId: keptID, Location: &pb.Location{Name: "Here"}}); err != nil {
+		t.Fatalf("CreateImage failed: %v", err)
+	}
+
+	// Variant files are referenced from the image_variants table
+	variantID, _ := storage.UploadFile(ctx, "kept_w640.jpg", "image/jpeg", []byte("variant"))
+	if err := dbService.CreateImageVariant(ctx, &interfaces.ImageVariant{ImageID: "kept", Name: "w640", Width: 640, Height: 360, MimeType: "image/jpeg", StorageFileID: variantID}); err != nil {
+		t.Fatalf("CreateImageVariant failed: %v", err)
+	}
+	orphanVariantID, _ := storage.UploadFile(ctx, "kept_w320.jpg", "image/jpeg", []byte("orphan variant"))
+
+	// Simulate a crash between the upload and the database insert, and a
+	// stale record for an upload that did complete
+	for _, op := range []*interfaces.PendingOperation{
+		{Operation: OperationUpload, ImageID: "orphan", FileID: orphanID, NextAttemptAt: time.Now().Add(-time.Minute)},
+		{Operation: OperationUpload, ImageID: "kept", FileID: keptID, NextAttemptAt: time.Now().Add(-time.Minute)},
+		{Operation: OperationUpload, ImageID: "kept", FileID: variantID, NextAttemptAt: time.Now().Add(-time.Minute)},
+		{Operation: OperationUpload, ImageID: "kept", FileID: orphanVariantID, NextAttemptAt: time.Now().Add(-time.Minute)},
+	} {
+		if err := dbService.CreatePendingOperation(ctx, op); err != nil {
+			t.Fatalf("CreatePendingOperation failed: %v", err)
+		}
+	}
+
+	completed, err := NewOperationRetrier(storage, dbService).RunOnce(ctx)
+	if err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	if completed != 4 {
+		t.Errorf("Expected 4 completed operations, got %d", completed)
+	}
+
+	if _, err := storage.GetFile(ctx, orphanID); err == nil {
+		t.Error("Expected orphaned file to be deleted")
+	}
+	if _, err := storage.GetFile(ctx, keptID); err != nil {
+		t.Errorf("Expected referenced file to be kept: %v", err)
+	}
+	if _, err := storage.GetFile(ctx, variantID); err != nil {
+		t.Errorf("Expected recorded variant file to be kept: %v", err)
+	}
+	if _, err := storage.GetFile(ctx, orphanVariantID); err == nil {
+		t.Error("Expected unrecorded variant file to be deleted")
+	}
+}
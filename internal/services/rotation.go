@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	// Daily rotation needs time zones, which minimal container images do not ship
+	_ "time/tzdata"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxRotationIntervalMinutes bounds the interval strategy to 30 days
+const maxRotationIntervalMinutes = 30 * 24 * 60
+
+// RotationStatus reports the rotation settings and the image they currently select
+type RotationStatus struct {
+	Settings       *interfaces.RotationSettings `json:"settings"`
+	CurrentImageID string                       `json:"current_image_id,omitempty"`
+	NextRotationAt *time.Time                   `json:"next_rotation_at,omitempty"`
+}
+
+// defaultRotationSettings always serves the newest upload
+func defaultRotationSettings() *interfaces.RotationSettings {
+	return &interfaces.RotationSettings{Strategy: interfaces.RotationLatest, Timezone: "UTC"}
+}
+
+// rotationSettings returns the stored rotation settings or the defaults
+func (s *ImageService) rotationSettings(ctx context.Context) (*interfaces.RotationSettings, error) {
+	settings, err := s.dbService.GetRotationSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		return defaultRotationSettings(), nil
+	}
+	return settings, nil
+}
+
+// rotatedImage returns the image the rotation settings select at now, and when
+// the selection next changes (nil for the latest strategy)
+func (s *ImageService) rotatedImage(ctx context.Context, now time.Time, draw drawOptions) (interface{}, *time.Time, error) {
+	settings, err := s.rotationSettings(ctx)
+	if err != nil {
+		// Keep serving a background when the settings cannot be read
+		log.Printf("Warning: failed to read rotation settings, serving the newest image: %v", err)
+		settings = defaultRotationSettings()
+	}
+
+	if settings.Strategy == interfaces.RotationLatest {
+		image, err := s.dbService.GetCurrentImage(ctx, now)
+		return image, nil, err
+	}
+
+	if settings.Strategy == interfaces.RotationWeightedRandom || settings.Strategy == interfaces.RotationShuffle {
+		images, err := s.visibleImages(ctx, &interfaces.ImageQuery{VisibleAt: &now, Ascending: true})
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(images) == 0 {
+			return nil, nil, fmt.Errorf("no images found")
+		}
+		image, next := s.pickImage(ctx, settings, images, now, draw, "")
+		return image, next, nil
+	}
+
+	// Only images inside their publish window take part in the rotation
+	counts, err := s.dbService.CountImagesByVisibility(ctx, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	count := counts[interfaces.VisibilityVisible]
+	if count == 0 {
+		return nil, nil, fmt.Errorf("no images found")
+	}
+
+	// Cycle through the catalog in upload order, one image per period
+	slot, next := rotationSlot(settings, now)
+	image, err := s.dbService.GetImageAtPosition(ctx, int(slot%int64(count)), now)
+	if err != nil {
+		return nil, nil, err
+	}
+	return image, &next, nil
+}
+
+// rotationSlot returns the number of the rotation period containing now and
+// when the next period starts
+func rotationSlot(settings *interfaces.RotationSettings, now time.Time) (int64, time.Time) {
+	var period time.Duration
+	switch settings.Strategy {
+	case interfaces.RotationDaily:
+		location, err := time.LoadLocation(settings.Timezone)
+		if err != nil {
+			location = time.UTC
+		}
+		local := now.In(location)
+		year, month, day := local.Date()
+
+		// Count calendar days so daylight saving changes do not shift the cycle
+		dayNumber := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / int64(24*time.Hour/time.Second)
+		return dayNumber, time.Date(year, month, day+1, 0, 0, 0, 0, location)
+	case interfaces.RotationHourly:
+		period = time.Hour
+	default:
+		period = time.Duration(settings.IntervalMinutes) * time.Minute
+	}
+	if period <= 0 {
+		period = time.Hour
+	}
+
+	slot := now.UnixNano() / int64(period)
+	return slot, time.Unix(0, (slot+1)*int64(period))
+}
+
+// normalizeRotationSettings validates rotation settings from an admin and fills in defaults
+func normalizeRotationSettings(settings *interfaces.RotationSettings) error {
+	settings.Strategy = strings.ToLower(strings.TrimSpace(settings.Strategy))
+	switch settings.Strategy {
+	case interfaces.RotationLatest, interfaces.RotationDaily, interfaces.RotationHourly:
+		settings.IntervalMinutes = 0
+	case interfaces.RotationInterval, interfaces.RotationWeightedRandom, interfaces.RotationShuffle:
+		if settings.IntervalMinutes < 1 || settings.IntervalMinutes > maxRotationIntervalMinutes {
+			return status.Errorf(codes.InvalidArgument, "interval_minutes must be between 1 and %d", maxRotationIntervalMinutes)
+		}
+	default:
+		return status.Errorf(codes.InvalidArgument, "strategy must be latest, daily, hourly, interval, weighted_random or shuffle, got %q", settings.Strategy)
+	}
+
+	if settings.Timezone == "" {
+		settings.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(settings.Timezone); err != nil {
+		return status.Errorf(codes.InvalidArgument, "unknown timezone %q", settings.Timezone)
+	}
+	return nil
+}
+
+// GetRotation returns the rotation settings and the image they currently select
+func (s *ImageService) GetRotation(ctx context.Context) (*RotationStatus, error) {
+	settings, err := s.rotationSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rotationStatus := &RotationStatus{Settings: settings}
+	imageInterface, nextRotation, err := s.rotatedImage(ctx, s.now(), drawOptions{})
+	if err == nil {
+		if image, ok := imageInterface.(*pb.ImageMetadata); ok {
+			rotationStatus.CurrentImageID = image.Id
+		}
+		rotationStatus.NextRotationAt = nextRotation
+	}
+	return rotationStatus, nil
+}
+
+// UpdateRotation replaces the rotation settings; invalid settings return InvalidArgument
+func (s *ImageService) UpdateRotation(ctx context.Context, settings *interfaces.RotationSettings) (*RotationStatus, error) {
+	if err := normalizeRotationSettings(settings); err != nil {
+		return nil, err
+	}
+	settings.UpdatedAt = s.now().UTC()
+
+	if err := s.dbService.SaveRotationSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	return s.GetRotation(ctx)
+}
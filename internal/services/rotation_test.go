@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRotation(t *testing.T) {
+	ctx := context.Background()
+	storage, dbService := newTestBackends(t)
+	service := NewImageService(storage, dbService)
+
+	var ids []string
+	for _, size := range []int{8, 9, 10} {
+		resp, err := service.UploadImage(ctx, &pb.UploadImageRequest{ImageData: testPNG(t, size, size)})
+		if err != nil || !resp.Success {
+			t.Fatalf("Upload failed: %v %v", err, resp)
+		}
+		ids = append(ids, resp.ImageId)
+	}
+
+	current := func() *pb.GetCurrentImageResponse {
+		t.Helper()
+		resp, err := service.GetCurrentImage(ctx, &pb.GetCurrentImageRequest{})
+		if err != nil || !resp.Success {
+			t.Fatalf("GetCurrentImage failed: %v %v", err, resp)
+		}
+		return resp
+	}
+
+	// Without settings the newest upload is current
+	if resp := current(); resp.Metadata.Id != ids[2] || resp.NextRotationAt != nil {
+		t.Fatalf("Expected the newest image without a rotation time, got %s %v", resp.Metadata.Id, resp.NextRotationAt)
+	}
+
+	if _, err := service.UpdateRotation(ctx, &interfaces.RotationSettings{Strategy: "hourly"}); err != nil {
+		t.Fatalf("UpdateRotation failed: %v", err)
+	}
+
+	// Consecutive hours cycle through the catalog in upload order
+	start := time.Date(2024, 5, 1, 0, 30, 0, 0, time.UTC)
+	first := int(start.Unix()/3600) % len(ids)
+	for hour := 0; hour < 4; hour++ {
+		now := start.Add(time.Duration(hour) * time.Hour)
+		service.now = func() time.Time { return now }
+
+		resp := current()
+		if expected := ids[(first+hour)%len(ids)]; resp.Metadata.Id != expected {
+			t.Errorf("Hour %d: expected %s, got %s", hour, expected, resp.Metadata.Id)
+		}
+		if next := now.Truncate(time.Hour).Add(time.Hour); !resp.NextRotationAt.AsTime().Equal(next) {
+			t.Errorf("Hour %d: expected the next rotation at %v, got %v", hour, next, resp.NextRotationAt.AsTime())
+		}
+	}
+
+	// Daily rotation starts each day at midnight in the configured zone
+	rotation, err := service.UpdateRotation(ctx, &interfaces.RotationSettings{Strategy: "daily", Timezone: "America/New_York"})
+	if err != nil {
+		t.Fatalf("UpdateRotation failed: %v", err)
+	}
+	newYork, _ := time.LoadLocation("America/New_York")
+	if expected := time.Date(2024, 5, 1, 0, 0, 0, 0, newYork); !rotation.NextRotationAt.Equal(expected) {
+		t.Errorf("Expected the next rotation at %v, got %v", expected, rotation.NextRotationAt)
+	}
+
+	for _, settings := range []*interfaces.RotationSettings{
+		{Strategy: "weekly"},
+		{Strategy: "interval"},
+		{Strategy: "daily", Timezone: "Mars/Olympus_Mons"},
+	} {
+		if _, err := service.UpdateRotation(ctx, settings); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for %+v, got %v", settings, err)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config holds S3-compatible object storage configuration
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+	UseSSL          bool
+	ForcePathStyle  bool
+}
+
+// S3Storage stores image files in an S3-compatible bucket (AWS S3, MinIO, etc.)
+type S3Storage struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewS3Storage creates a new S3-compatible storage client
+func NewS3Storage(ctx context.Context, config S3Config) (*S3Storage, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "s3.amazonaws.com"
+	}
+
+	// Accept endpoints given as URLs (e.g. http://localhost:9000)
+	if endpointURL, err := url.Parse(config.Endpoint); err == nil && endpointURL.Host != "" {
+		config.UseSSL = endpointURL.Scheme == "https"
+		config.Endpoint = endpointURL.Host
+	}
+
+	bucketLookup := minio.BucketLookupAuto
+	if config.ForcePathStyle {
+		bucketLookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(config.Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(config.AccessKeyID, config.SecretAccessKey, ""),
+		Secure:       config.UseSSL,
+		Region:       config.Region,
+		BucketLookup: bucketLookup,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %v", err)
+	}
+
+	exists, err := client.BucketExists(ctx, config.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check S3 bucket: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("S3 bucket %s does not exist", config.Bucket)
+	}
+
+	return &S3Storage{
+		client: client,
+		bucket: config.Bucket,
+		prefix: strings.Trim(config.Prefix, "/"),
+	}, nil
+}
+
+// NewS3StorageFromEnv creates an S3-compatible storage client from environment variables
+func NewS3StorageFromEnv(ctx context.Context) (*S3Storage, error) {
+	config := S3Config{
+		Endpoint:        os.Getenv("S3_ENDPOINT"),
+		Region:          os.Getenv("S3_REGION"),
+		Bucket:          os.Getenv("S3_BUCKET"),
+		Prefix:          os.Getenv("S3_PREFIX"),
+		AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		UseSSL:          true,
+	}
+
+	// Fall back to the standard AWS credential variables
+	if config.AccessKeyID == "" {
+		config.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if config.SecretAccessKey == "" {
+		config.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+
+	if useSSL := os.Getenv("S3_USE_SSL"); useSSL != "" {
+		value, err := strconv.ParseBool(useSSL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid S3_USE_SSL value: %s", useSSL)
+		}
+		config.UseSSL = value
+	}
+
+	if pathStyle := os.Getenv("S3_FORCE_PATH_STYLE"); pathStyle != "" {
+		value, err := strconv.ParseBool(pathStyle)
+		if err != nil {
+			return nil, fmt.Errorf("invalid S3_FORCE_PATH_STYLE value: %s", pathStyle)
+		}
+		config.ForcePathStyle = value
+	}
+
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("S3_BUCKET environment variable is required")
+	}
+
+	return NewS3Storage(ctx, config)
+}
+
+// UploadFile stores data as a new object and returns its file ID
+func (s *S3Storage) UploadFile(ctx context.Context, filename, mimeType string, data []byte) (string, error) {
+	fileID := fmt.Sprintf("%d_%s", time.Now().UnixNano(), sanitizeFilename(filename))
+	key, err := s.objectKey(fileID)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.client.PutObject(ctx, s.bucket, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{ContentType: mimeType})
+	if err != nil {
+		return "", fmt.Errorf("upload failed: %v", err)
+	}
+
+	return fileID, nil
+}
+
+// GetFile downloads the contents of an object
+func (s *S3Storage) GetFile(ctx context.Context, fileID string) ([]byte, error) {
+	key, err := s.objectKey(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %v", err)
+	}
+	defer object.Close()
+
+	data, err := io.ReadAll(object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file data: %v", err)
+	}
+
+	return data, nil
+}
+
+// GetFileURL returns a presigned download URL for an object
+func (s *S3Storage) GetFileURL(ctx context.Context, fileID string) (string, error) {
+	key, err := s.objectKey(fileID)
+	if err != nil {
+		return "", err
+	}
+
+	presignedURL, err := s.client.PresignedGetObject(ctx, s.bucket, key, time.Hour, url.Values{})
+	if err != nil {
+		return "", fmt.Errorf("failed to presign URL: %v", err)
+	}
+
+	return presignedURL.String(), nil
+}
+
+// DeleteFile removes an object
+func (s *S3Storage) DeleteFile(ctx context.Context, fileID string) error {
+	key, err := s.objectKey(fileID)
+	if err != nil {
+		return err
+	}
+
+	err = s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("delete failed: %v", err)
+	}
+	return nil
+}
+
+// ListFiles returns the IDs of all objects under the configured prefix
+func (s *S3Storage) ListFiles(ctx context.Context) ([]string, error) {
+	listPrefix := ""
+	if s.prefix != "" {
+		listPrefix = s.prefix + "/"
+	}
+
+	var fileIDs []string
+	for object := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: listPrefix, Recursive: true}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list files: %v", object.Err)
+		}
+		// Skip objects in nested folders, which have no valid file ID
+		fileID := strings.TrimPrefix(object.Key, listPrefix)
+		if _, err := s.objectKey(fileID); err != nil {
+			continue
+		}
+		fileIDs = append(fileIDs, fileID)
+	}
+
+	return fileIDs, nil
+}
+
+// objectKey maps a file ID to its object key inside the bucket. File IDs that
+// could name an object outside the prefix are rejected.
+func (s *S3Storage) objectKey(fileID string) (string, error) {
+	if fileID == "" || strings.Contains(fileID, "/") || strings.Contains(fileID, "..") ||
+		strings.HasPrefix(fileID, ".") || path.Clean(fileID) != fileID {
+		return "", fmt.Errorf("invalid file ID: %q", fileID)
+	}
+
+	if s.prefix == "" {
+		return fileID, nil
+	}
+	return s.prefix + "/" + fileID, nil
+}
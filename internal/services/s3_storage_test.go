@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+func TestS3ObjectKey(t *testing.T) {
+	t.Run("builds_keys", func(t *testing.T) {
+		tests := []struct {
+			prefix string
+			fileID string
+			key    string
+		}{
+			{"", "1700000000_photo.jpg", "1700000000_photo.jpg"},
+			{"images", "1700000000_photo.jpg", "images/1700000000_photo.jpg"},
+			{"site/images", "1700000000_photo.v2.jpg", "site/images/1700000000_photo.v2.jpg"},
+		}
+		for _, tt := range tests {
+			storage := &S3Storage{prefix: tt.prefix}
+			key, err := storage.objectKey(tt.fileID)
+			if err != nil || key != tt.key {
+				t.Errorf("objectKey(%q) with prefix %q = %q, %v; expected %q", tt.fileID, tt.prefix, key, err, tt.key)
+			}
+		}
+	})
+
+	t.Run("confines_keys_to_prefix", func(t *testing.T) {
+		storage := &S3Storage{prefix: "images"}
+		for _, fileID := range []string{"", ".", "..", "../secret", "a/b", "/abs", "a/../b", "x..y", ".hidden"} {
+			if key, err := storage.objectKey(fileID); err == nil {
+				t.Errorf("Expected error for file ID %q, got key %q", fileID, key)
+			}
+		}
+	})
+
+	t.Run("rejects_before_calling_s3", func(t *testing.T) {
+		// Without a client, any request that reached S3 would panic
+		ctx := context.Background()
+		storage := &S3Storage{prefix: "images"}
+		if _, err := storage.GetFile(ctx, "../other/file"); err == nil {
+			t.Error("Expected GetFile to reject a traversal file ID")
+		}
+		if _, err := storage.GetFileURL(ctx, "../other/file"); err == nil {
+			t.Error("Expected GetFileURL to reject a traversal file ID")
+		}
+		if err := storage.DeleteFile(ctx, "../other/file"); err == nil {
+			t.Error("Expected DeleteFile to reject a traversal file ID")
+		}
+	})
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// maxImageWeight bounds the weight of an image for the weighted_random strategy
+	maxImageWeight = 1000
+	// maxViewerTokenLength bounds the opaque token that keys a viewer's shuffle bag
+	maxViewerTokenLength = 256
+)
+
+// drawOptions are the per-request inputs of the weighted_random and shuffle strategies
+type drawOptions struct {
+	// seed overrides the seed in the rotation settings
+	seed *int64
+	// token identifies a viewer with their own shuffle bag
+	token string
+}
+
+// newDrawOptions reads the draw options of a current image request
+func newDrawOptions(req *pb.GetCurrentImageRequest) (drawOptions, error) {
+	if len(req.GetViewerToken()) > maxViewerTokenLength {
+		return drawOptions{}, status.Errorf(codes.InvalidArgument, "viewer_token must be at most %d bytes", maxViewerTokenLength)
+	}
+	return drawOptions{seed: req.Seed, token: req.GetViewerToken()}, nil
+}
+
+// validateWeight checks a client-supplied image weight
+func validateWeight(weight *float64) error {
+	if weight != nil && !(*weight > 0 && *weight <= maxImageWeight) {
+		return status.Errorf(codes.InvalidArgument, "weight must be greater than 0 and at most %d", maxImageWeight)
+	}
+	return nil
+}
+
+// imageWeight returns the weight of an image, treating unset weights as 1
+func imageWeight(image *pb.ImageMetadata) float64 {
+	if image.Weight <= 0 {
+		return 1
+	}
+	return image.Weight
+}
+
+// pickImage returns the image of images that the rotation settings select at
+// now, and when the selection next changes. pool names the set of images so
+// that a viewer keeps a separate shuffle bag for each.
+func (s *ImageService) pickImage(ctx context.Context, settings *interfaces.RotationSettings, images []*pb.ImageMetadata, now time.Time, draw drawOptions, pool string) (*pb.ImageMetadata, *time.Time) {
+	if settings.Strategy == interfaces.RotationLatest {
+		return images[len(images)-1], nil
+	}
+
+	slot, next := rotationSlot(settings, now)
+	seed := uint64(settings.Seed)
+	if draw.seed != nil {
+		seed = uint64(*draw.seed)
+	}
+
+	switch settings.Strategy {
+	case interfaces.RotationWeightedRandom:
+		return weightedImage(sortedByID(images), seed, slot), &next
+	case interfaces.RotationShuffle:
+		if draw.token != "" {
+			return s.shuffleBagImage(ctx, sortedByID(images), seed, slot, draw.token, pool, now), &next
+		}
+		return shuffledImage(sortedByID(images), seed, slot), &next
+	default:
+		// Cycle through the images in upload order, one image per period
+		return images[slot%int64(len(images))], &next
+	}
+}
+
+// sortedByID returns a copy of images ordered by ID, so that draws do not
+// depend on the order the database returns them in
+func sortedByID(images []*pb.ImageMetadata) []*pb.ImageMetadata {
+	sorted := append([]*pb.ImageMetadata(nil), images...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Id < sorted[j].Id })
+	return sorted
+}
+
+// weightedImage draws an image for a rotation period with a chance
+// proportional to its weight. The draw depends only on seed and slot.
+func weightedImage(images []*pb.ImageMetadata, seed uint64, slot int64) *pb.ImageMetadata {
+	total := 0.0
+	for _, image := range images {
+		total += imageWeight(image)
+	}
+
+	target := newSeededRand(seed, uint64(slot)).float64() * total
+	for _, image := range images {
+		target -= imageWeight(image)
+		if target < 0 {
+			return image
+		}
+	}
+	return images[len(images)-1]
+}
+
+// shuffledImage returns the image of a rotation period for viewers without a
+// shuffle bag: every image appears once per round of len(images) periods, in
+// an order that depends only on seed and the round
+func shuffledImage(images []*pb.ImageMetadata, seed uint64, slot int64) *pb.ImageMetadata {
+	count := int64(len(images))
+	round, position := slot/count, slot%count
+
+	order := make([]int, count)
+	for i := range order {
+		order[i] = i
+	}
+	newSeededRand(seed, uint64(round)).shuffle(len(order), func(i, j int) {
+		order[i], order[j] = order[j], order[i]
+	})
+	return images[order[position]]
+}
+
+// shuffleBagImage returns the image a viewer sees in a rotation period. Each
+// new period moves the viewer to the next image of their bag, and a new bag is
+// shuffled once every image has been seen. Images uploaded during a round are
+// added to the end of the bag. When the bag cannot be read, the viewer gets the
+// stateless shuffle.
+func (s *ImageService) shuffleBagImage(ctx context.Context, images []*pb.ImageMetadata, seed uint64, slot int64, token, pool string, now time.Time) *pb.ImageMetadata {
+	sum := sha256.Sum256([]byte(pool + "\x00" + token))
+	tokenHash := hex.EncodeToString(sum[:])
+	seed ^= binary.BigEndian.Uint64(sum[:8])
+
+	bag, err := s.dbService.GetShuffleBag(ctx, tokenHash)
+	if err != nil {
+		log.Printf("Warning: failed to read shuffle bag, using the shared shuffle: %v", err)
+		return shuffledImage(images, seed, slot)
+	}
+
+	byID := make(map[string]*pb.ImageMetadata, len(images))
+	for _, image := range images {
+		byID[image.Id] = image
+	}
+
+	if bag == nil {
+		bag = &interfaces.ShuffleBag{TokenHash: tokenHash, ImageIDs: shuffledIDs(images, seed, 0)}
+	} else if image := bagImage(bag, byID); image != nil && bag.Slot == slot {
+		// Requests within a period see the same image
+		return image
+	} else {
+		advanceShuffleBag(bag, images, byID, seed)
+	}
+
+	bag.Slot = slot
+	bag.UpdatedAt = now
+	if err := s.dbService.SaveShuffleBag(ctx, bag); err != nil {
+		log.Printf("Warning: failed to save shuffle bag: %v", err)
+	}
+	return byID[bag.ImageIDs[bag.Position]]
+}
+
+// bagImage returns the image at the bag's position, or nil when it is no
+// longer visible
+func bagImage(bag *interfaces.ShuffleBag, byID map[string]*pb.ImageMetadata) *pb.ImageMetadata {
+	if bag.Position < 0 || bag.Position >= len(bag.ImageIDs) {
+		return nil
+	}
+	return byID[bag.ImageIDs[bag.Position]]
+}
+
+// advanceShuffleBag moves a bag to the next visible image, starting a new
+// round when the bag is used up
+func advanceShuffleBag(bag *interfaces.ShuffleBag, images []*pb.ImageMetadata, byID map[string]*pb.ImageMetadata, seed uint64) {
+	var last string
+	if bag.Position >= 0 && bag.Position < len(bag.ImageIDs) {
+		last = bag.ImageIDs[bag.Position]
+	}
+
+	// Images that are new since the round started join the end of it
+	inBag := make(map[string]bool, len(bag.ImageIDs))
+	for _, id := range bag.ImageIDs {
+		inBag[id] = true
+	}
+	var added []*pb.ImageMetadata
+	for _, image := range images {
+		if !inBag[image.Id] {
+			added = append(added, image)
+		}
+	}
+	bag.ImageIDs = append(bag.ImageIDs, shuffledIDs(added, seed, bag.Round)...)
+
+	for position := bag.Position + 1; position < len(bag.ImageIDs); position++ {
+		if byID[bag.ImageIDs[position]] != nil {
+			bag.Position = position
+			return
+		}
+	}
+
+	bag.Round++
+	bag.ImageIDs = shuffledIDs(images, seed, bag.Round)
+	bag.Position = 0
+	// Do not show the last image of a round again first in the next
+	if n := len(bag.ImageIDs); n > 1 && bag.ImageIDs[0] == last {
+		bag.ImageIDs[0], bag.ImageIDs[n-1] = bag.ImageIDs[n-1], bag.ImageIDs[0]
+	}
+}
+
+// shuffledIDs returns the IDs of images in an order that depends only on seed
+// and round
+func shuffledIDs(images []*pb.ImageMetadata, seed uint64, round int) []string {
+	ids := make([]string, len(images))
+	for i, image := range images {
+		ids[i] = image.Id
+	}
+	newSeededRand(seed, uint64(round)).shuffle(len(ids), func(i, j int) {
+		ids[i], ids[j] = ids[j], ids[i]
+	})
+	return ids
+}
+
+// seededRand is a SplitMix64 generator. Unlike math/rand, its sequence is
+// fixed, so draws stay the same across Go versions and CDN caches and tests
+// can rely on them.
+type seededRand struct {
+	state uint64
+}
+
+// newSeededRand returns a generator whose sequence depends on every seed
+func newSeededRand(seeds ...uint64) *seededRand {
+	r := &seededRand{}
+	for _, seed := range seeds {
+		r.state ^= seed
+		r.state = r.next()
+	}
+	return r
+}
+
+func (r *seededRand) next() uint64 {
+	r.state += 0x9e3779b97f4a7c15
+	z := r.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// float64 returns a number in [0, 1)
+func (r *seededRand) float64() float64 {
+	return float64(r.next()>>11) / (1 << 53)
+}
+
+// intn returns a number in [0, n)
+func (r *seededRand) intn(n int) int {
+	return int(r.next() % uint64(n))
+}
+
+// shuffle is a Fisher-Yates shuffle of n elements
+func (r *seededRand) shuffle(n int, swap func(i, j int)) {
+	for i := n - 1; i > 0; i-- {
+		swap(i, r.intn(i+1))
+	}
+}
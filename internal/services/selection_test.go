@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSelectionStrategies(t *testing.T) {
+	ctx := context.Background()
+	storage, dbService := newTestBackends(t)
+	service := NewImageService(storage, dbService)
+
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	if _, err := service.UploadImage(ctx, &pb.UploadImageRequest{ImageData: testPNG(t, 8, 8), Weight: proto.Float64(0)}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a zero weight, got %v", err)
+	}
+
+	upload := func(size int, weight *float64) string {
+		t.Helper()
+		resp, err := service.UploadImage(ctx, &pb.UploadImageRequest{ImageData: testPNG(t, size, size), Weight: weight})
+		if err != nil || !resp.Success {
+			t.Fatalf("Upload failed: %v %v", err, resp)
+		}
+		return resp.ImageId
+	}
+	heavy := upload(8, proto.Float64(17))
+	ids := []string{heavy, upload(9, nil), upload(10, nil), upload(11, nil)}
+
+	if _, err := service.UpdateRotation(ctx, &interfaces.RotationSettings{Strategy: interfaces.RotationShuffle}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument without an interval, got %v", err)
+	}
+	rotate := func(strategy string) {
+		t.Helper()
+		if _, err := service.UpdateRotation(ctx, &interfaces.RotationSettings{Strategy: strategy, IntervalMinutes: 60, Seed: 42}); err != nil {
+			t.Fatalf("UpdateRotation failed: %v", err)
+		}
+	}
+	current := func(req *pb.GetCurrentImageRequest) string {
+		t.Helper()
+		resp, err := service.GetCurrentImage(ctx, req)
+		if err != nil || !resp.Success {
+			t.Fatalf("GetCurrentImage failed: %v %v", err, resp)
+		}
+		return resp.Metadata.Id
+	}
+	// draws returns the images of count consecutive hours from the start
+	start := now
+	draws := func(count int, req *pb.GetCurrentImageRequest) []string {
+		t.Helper()
+		var drawn []string
+		for i := 0; i < count; i++ {
+			now = start.Add(time.Duration(i) * time.Hour)
+			drawn = append(drawn, current(req))
+		}
+		return drawn
+	}
+
+	rotate(interfaces.RotationWeightedRandom)
+	first := draws(100, &pb.GetCurrentImageRequest{})
+	if again := draws(100, &pb.GetCurrentImageRequest{}); strings.Join(again, ",") != strings.Join(first, ",") {
+		t.Errorf("Expected the same draws for the same seed")
+	}
+	heavyDraws := 0
+	for _, id := range first {
+		if id == heavy {
+			heavyDraws++
+		}
+	}
+	// The heavy image has 85% of the total weight
+	if heavyDraws < 70 || heavyDraws == 100 {
+		t.Errorf("Expected most draws to favour the heavy image, got %d of 100", heavyDraws)
+	}
+	if reseeded := draws(100, &pb.GetCurrentImageRequest{Seed: proto.Int64(7)}); strings.Join(reseeded, ",") == strings.Join(first, ",") {
+		t.Errorf("Expected a request seed to change the draws")
+	}
+
+	// Every image appears once per round of four hours, for shared and per-viewer shuffles
+	rotate(interfaces.RotationShuffle)
+	for name, req := range map[string]*pb.GetCurrentImageRequest{
+		"shared": {},
+		"viewer": {ViewerToken: "visitor-1"},
+	} {
+		drawn := draws(12, req)
+		for round := 0; round < 3; round++ {
+			seen := map[string]bool{}
+			for _, id := range drawn[round*4 : round*4+4] {
+				seen[id] = true
+			}
+			if len(seen) != len(ids) {
+				t.Errorf("%s: expected every image once in round %d, got %v", name, round, drawn[round*4:round*4+4])
+			}
+		}
+		if id := current(req); id != drawn[len(drawn)-1] {
+			t.Errorf("%s: expected the same image within an hour, got %s and %s", name, drawn[len(drawn)-1], id)
+		}
+	}
+
+	if _, err := service.GetCurrentImage(ctx, &pb.GetCurrentImageRequest{ViewerToken: strings.Repeat("x", 300)}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a long viewer token, got %v", err)
+	}
+
+	updated, err := service.UpdateImage(ctx, &pb.UpdateImageRequest{ImageId: heavy, Weight: proto.Float64(2.5)})
+	if err != nil || !updated.Success || updated.Metadata.Weight != 2.5 {
+		t.Errorf("UpdateImage failed: %v %v", err, updated)
+	}
+	if resp, _ := service.GetImageById(ctx, &pb.GetImageByIdRequest{ImageId: ids[1]}); resp.Metadata.Weight != 1 {
+		t.Errorf("Expected a default weight of 1, got %v", resp.Metadata.Weight)
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/config"
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+)
+
+// StorageType represents the type of blob storage backend to use
+type StorageType string
+
+const (
+	StorageTypeDrive StorageType = "drive"
+	StorageTypeLocal StorageType = "local"
+	StorageTypeS3    StorageType = "s3"
+)
+
+// DriveAuthMode selects how the Google Drive backend authenticates
+type DriveAuthMode string
+
+const (
+	DriveAuthModeOAuth          DriveAuthMode = "oauth"
+	DriveAuthModeServiceAccount DriveAuthMode = "service_account"
+)
+
+// DriveConfig holds the settings needed to create the Google Drive backend.
+// When TokenStore is nil, the OAuth token is kept in the file at TokenPath.
+// CredentialsPath, Subject and SharedDriveID apply to service-account mode;
+// an empty CredentialsPath uses Application Default Credentials.
+type DriveConfig struct {
+	AuthMode        DriveAuthMode
+	OAuthConfigPath string
+	TokenPath       string
+	TokenStore      TokenStore
+	CredentialsPath string
+	Subject         string
+	SharedDriveID   string
+	FolderID        string
+}
+
+// defaultCredentialsPath is the service account key file used when no path is configured
+const defaultCredentialsPath = "credentials.json"
+
+// NewDriveConfig creates a DriveConfig with the auth mode, service account and
+// shared drive settings from appConfig. Without a configured key file,
+// credentials.json is used if it exists and Application Default Credentials
+// otherwise; a configured key file that cannot be read fails when the Drive
+// backend is created. OAuth paths are left to the caller.
+func NewDriveConfig(appConfig *config.Config, folderID string) DriveConfig {
+	credentialsPath := appConfig.GoogleDriveCredentialsPath
+	if credentialsPath == "" {
+		if _, err := os.Stat(defaultCredentialsPath); err == nil {
+			credentialsPath = defaultCredentialsPath
+		}
+	}
+
+	return DriveConfig{
+		AuthMode:        DriveAuthMode(appConfig.GoogleDriveAuthMode),
+		CredentialsPath: credentialsPath,
+		Subject:         appConfig.GoogleDriveImpersonateSubject,
+		SharedDriveID:   appConfig.GoogleDriveSharedDriveID,
+		FolderID:        folderID,
+	}
+}
+
+// GetStorageTypeFromEnv returns the storage type selected by STORAGE_TYPE
+func GetStorageTypeFromEnv() StorageType {
+	storageType := os.Getenv("STORAGE_TYPE")
+	if storageType == "" {
+		// Default to Google Drive to preserve existing deployments
+		return StorageTypeDrive
+	}
+	return StorageType(storageType)
+}
+
+// NewStorageServiceFromEnv creates a storage service based on environment configuration
+func NewStorageServiceFromEnv(ctx context.Context, driveConfig DriveConfig) (interfaces.DriveService, error) {
+	return NewStorageServiceWithType(ctx, GetStorageTypeFromEnv(), driveConfig)
+}
+
+// NewStorageServiceWithType creates a storage service with a specific type
+func NewStorageServiceWithType(ctx context.Context, storageType StorageType, driveConfig DriveConfig) (interfaces.DriveService, error) {
+	switch storageType {
+	case StorageTypeDrive:
+		driveUtil, err := NewDriveUtilFromConfig(ctx, driveConfig)
+		if err != nil {
+			return nil, err
+		}
+		return driveUtil, nil
+	case StorageTypeLocal:
+		localStorage, err := NewLocalStorageFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		return localStorage, nil
+	case StorageTypeS3:
+		s3Storage, err := NewS3StorageFromEnv(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return s3Storage, nil
+	default:
+		return nil, fmt.Errorf("unsupported storage type: %s", storageType)
+	}
+}
+
+// NewDriveUtilFromConfig creates the Google Drive client using the configured auth mode
+func NewDriveUtilFromConfig(ctx context.Context, driveConfig DriveConfig) (*DriveUtilOAuth, error) {
+	if driveConfig.FolderID == "" {
+		return nil, fmt.Errorf("GOOGLE_DRIVE_FOLDER_ID is required for drive storage")
+	}
+
+	switch driveConfig.AuthMode {
+	case DriveAuthModeServiceAccount:
+		return NewDriveUtilServiceAccount(ctx, driveConfig.CredentialsPath, driveConfig.FolderID, ServiceAccountOptions{
+			Subject:       driveConfig.Subject,
+			SharedDriveID: driveConfig.SharedDriveID,
+		})
+	case DriveAuthModeOAuth, "":
+		tokenStore := driveConfig.TokenStore
+		if tokenStore == nil {
+			tokenStore = NewFileTokenStore(driveConfig.TokenPath)
+		}
+
+		oauthConfig, err := LoadOAuthConfig(driveConfig.OAuthConfigPath)
+		if err != nil {
+			return nil, err
+		}
+
+		driveUtil, err := NewDriveUtilWithOAuthFlow(ctx, NewOAuthFlow(oauthConfig, tokenStore), driveConfig.FolderID)
+		if err != nil {
+			return nil, err
+		}
+		driveUtil.SetSharedDriveID(driveConfig.SharedDriveID)
+		return driveUtil, nil
+	default:
+		return nil, fmt.Errorf("unsupported Google Drive auth mode: %s", driveConfig.AuthMode)
+	}
+}
+
+// NewLocalStorageFromEnv creates a local filesystem storage from environment variables
+func NewLocalStorageFromEnv() (*LocalStorage, error) {
+	basePath := os.Getenv("LOCAL_STORAGE_PATH")
+	if basePath == "" {
+		basePath = "data/images"
+	}
+	return NewLocalStorage(basePath)
+}
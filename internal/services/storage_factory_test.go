@@ -0,0 +1,26 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/config"
+)
+
+func TestDriveConfigMissingCredentials(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "credentials.json")
+	driveConfig := NewDriveConfig(&config.Config{
+		GoogleDriveAuthMode:        string(DriveAuthModeServiceAccount),
+		GoogleDriveCredentialsPath: missing,
+	}, "folder")
+
+	// A configured key file must not silently fall back to Application Default Credentials
+	if driveConfig.CredentialsPath != missing {
+		t.Fatalf("Expected the configured credentials path to be kept, got %q", driveConfig.CredentialsPath)
+	}
+	if _, err := NewDriveUtilFromConfig(context.Background(), driveConfig); err == nil || !strings.Contains(err.Error(), "credentials") {
+		t.Errorf("Expected an error for an unreadable credentials file, got %v", err)
+	}
+}
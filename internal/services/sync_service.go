@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+)
+
+// syncUploadGracePeriod leaves recently created Drive files alone, since their
+// upload may still be writing the database row
+const syncUploadGracePeriod = 10 * time.Minute
+
+// SyncService reconciles the Google Drive folder with the images table
+type SyncService struct {
+	driveUtil *DriveUtilOAuth
+	dbService interfaces.DatabaseService
+	// now returns the time used for the upload grace period
+	now func() time.Time
+}
+
+// SyncReport describes the differences between Drive and the database.
+// Untracked files are imported when the sync is applied; missing files are only reported.
+type SyncReport struct {
+	DryRun         bool                `json:"dry_run"`
+	DriveFiles     int                 `json:"drive_files"`
+	DatabaseImages int                 `json:"database_images"`
+	UntrackedFiles []*pb.ImageMetadata `json:"untracked_files"`
+	// InFlightFiles lists files without a database row that an upload may still
+	// be adding; they are not imported
+	InFlightFiles []*pb.ImageMetadata `json:"in_flight_files"`
+	MissingFiles  []*pb.ImageMetadata `json:"missing_files"`
+	Imported      []string            `json:"imported"`
+	Errors        []string            `json:"errors,omitempty"`
+}
+
+// NewSyncService creates a new SyncService instance
+func NewSyncService(driveUtil *DriveUtilOAuth, dbService interfaces.DatabaseService) *SyncService {
+	return &SyncService{
+		driveUtil: driveUtil,
+		dbService: dbService,
+		now:       time.Now,
+	}
+}
+
+// Reconcile compares Drive with the database. When dryRun is false,
+// Drive images without a database row are imported, except for those of
+// uploads that may still be in progress.
+func (s *SyncService) Reconcile(ctx context.Context, dryRun bool) (*SyncReport, error) {
+	driveImages, err := s.driveUtil.ListImageFilesInFolder(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Drive images: %v", err)
+	}
+
+	return s.reconcile(ctx, driveImages, dryRun)
+}
+
+// reconcile compares the listed Drive images with the database
+func (s *SyncService) reconcile(ctx context.Context, driveImages []*pb.ImageMetadata, dryRun bool) (*SyncReport, error) {
+	imagesInterface, err := s.dbService.ListImages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list database images: %v", err)
+	}
+
+	// Files guarded by a pending upload get their row once the upload
+	// finishes, or are deleted by the retrier if it failed
+	operations, err := s.dbService.ListPendingOperations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending operations: %v", err)
+	}
+	pendingUploads := make(map[string]bool)
+	for _, op := range operations {
+		if op.Operation == OperationUpload {
+			pendingUploads[op.FileID] = true
+		}
+	}
+
+	// Index database images by Drive file ID
+	var dbImages []*pb.ImageMetadata
+	dbByFileID := make(map[string]*pb.ImageMetadata)
+	for _, imgInterface := range imagesInterface {
+		if img, ok := imgInterface.(*pb.ImageMetadata); ok {
+			dbImages = append(dbImages, img)
+			dbByFileID[img.DriveFileId] = img
+		}
+	}
+
+	report := &SyncReport{
+		DryRun:         dryRun,
+		DriveFiles:     len(driveImages),
+		DatabaseImages: len(dbImages),
+		UntrackedFiles: []*pb.ImageMetadata{},
+		InFlightFiles:  []*pb.ImageMetadata{},
+		MissingFiles:   []*pb.ImageMetadata{},
+		Imported:       []string{},
+	}
+
+	recent := s.now().Add(-syncUploadGracePeriod)
+	driveFileIDs := make(map[string]bool)
+	for _, driveImage := range driveImages {
+		driveFileIDs[driveImage.DriveFileId] = true
+		if _, exists := dbByFileID[driveImage.DriveFileId]; exists {
+			continue
+		}
+		if pendingUploads[driveImage.DriveFileId] || (driveImage.CreatedAt != nil && driveImage.CreatedAt.AsTime().After(recent)) {
+			report.InFlightFiles = append(report.InFlightFiles, driveImage)
+			continue
+		}
+		report.UntrackedFiles = append(report.UntrackedFiles, driveImage)
+	}
+
+	for _, img := range dbImages {
+		if img.DriveFileId != "" && !driveFileIDs[img.DriveFileId] {
+			report.MissingFiles = append(report.MissingFiles, img)
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	// Import untracked Drive images
+	for _, driveImage := range report.UntrackedFiles {
+		if err := s.dbService.CreateImage(ctx, driveImage); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("failed to import %s: %v", driveImage.DriveFileId, err))
+			continue
+		}
+		report.Imported = append(report.Imported, driveImage.Id)
+	}
+
+	return report, nil
+}
+
+// RebuildReport describes a rebuild of the images and locations tables from Drive
+type RebuildReport struct {
+	DryRun     bool `json:"dry_run"`
+	DriveFiles int  `json:"drive_files"`
+	// Placeholders lists Drive files without stored metadata, restored from the file name
+	Placeholders []string            `json:"placeholders"`
+	Images       []*pb.ImageMetadata `json:"images"`
+	Restored     []string            `json:"restored"`
+	// Variants lists the resized variants found in Drive; they are restored after their images
+	Variants []*interfaces.ImageVariant `json:"variants"`
+	Errors   []string                   `json:"errors,omitempty"`
+}
+
+// Rebuild reconstructs the images and locations tables from the metadata stored
+// in Drive. Existing rows are overwritten; rows without a Drive file are left alone.
+func (s *SyncService) Rebuild(ctx context.Context, dryRun bool) (*RebuildReport, error) {
+	files, err := s.driveUtil.listImageFiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Drive images: %v", err)
+	}
+
+	report := &RebuildReport{
+		DryRun:       dryRun,
+		DriveFiles:   len(files),
+		Placeholders: []string{},
+		Images:       []*pb.ImageMetadata{},
+		Restored:     []string{},
+		Variants:     []*interfaces.ImageVariant{},
+	}
+
+	for _, file := range files {
+		if isDriveOriginalFile(file) {
+			// Restored through the original_file_id of its image
+			continue
+		}
+		if variant, isVariant := decodeDriveVariantMetadata(file); isVariant {
+			report.Variants = append(report.Variants, variant)
+			continue
+		}
+		image, hasMetadata := imageFromDriveFile(file)
+		if !hasMetadata {
+			report.Placeholders = append(report.Placeholders, file.Id)
+		}
+		report.Images = append(report.Images, image)
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	for _, image := range report.Images {
+		if err := s.dbService.CreateImage(ctx, image); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("failed to restore %s: %v", image.DriveFileId, err))
+			continue
+		}
+		report.Restored = append(report.Restored, image.Id)
+	}
+
+	for _, variant := range report.Variants {
+		if err := s.dbService.CreateImageVariant(ctx, variant); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("failed to restore variant %s: %v", variant.StorageFileID, err))
+		}
+	}
+
+	return report, nil
+}
+
+// ExportMetadata writes the metadata of every database image to its Drive file,
+// so images uploaded before metadata mirroring can be rebuilt as well
+func (s *SyncService) ExportMetadata(ctx context.Context) ([]string, []string, error) {
+	imagesInterface, err := s.dbService.ListImages(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list database images: %v", err)
+	}
+
+	exported := []string{}
+	var errs []string
+	for _, imgInterface := range imagesInterface {
+		img, ok := imgInterface.(*pb.ImageMetadata)
+		if !ok || img.DriveFileId == "" {
+			continue
+		}
+
+		if err := s.driveUtil.SetFileMetadata(ctx, img.DriveFileId, img); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to export %s: %v", img.Id, err))
+			continue
+		}
+		exported = append(exported, img.Id)
+	}
+
+	return exported, errs, nil
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestReconcileSkipsInFlightUploads(t *testing.T) {
+	ctx := context.Background()
+	_, dbService := newTestBackends(t)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	service := &SyncService{dbService: dbService, now: func() time.Time { return now }}
+
+	driveImage := func(fileID string, age time.Duration) *pb.ImageMetadata {
+		return &pb.ImageMetadata{
+			Id:          fileID,
+			Title:       fileID,
+			DriveFileId: fileID,
+			CreatedAt:   timestamppb.New(now.Add(-age)),
+			Location:    &pb.Location{Name: "Unknown Location"},
+		}
+	}
+	driveImages := []*pb.ImageMetadata{
+		driveImage("old", 24*time.Hour),
+		driveImage("recent", time.Minute),
+		driveImage("pending", 24*time.Hour),
+	}
+
+	if err := dbService.CreatePendingOperation(ctx, newPendingOperation(OperationUpload, "img_pending", "pending")); err != nil {
+		t.Fatalf("CreatePendingOperation failed: %v", err)
+	}
+
+	report, err := service.reconcile(ctx, driveImages, false)
+	if err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if len(report.Imported) != 1 || report.Imported[0] != "old" {
+		t.Errorf("Expected only the old file to be imported, got %v", report.Imported)
+	}
+	if len(report.InFlightFiles) != 2 {
+		t.Errorf("Expected the recent and pending files to be skipped, got %d", len(report.InFlightFiles))
+	}
+
+	// Once the grace period is over, a recent file without a pending upload is imported
+	now = now.Add(syncUploadGracePeriod)
+	report, err = service.reconcile(ctx, driveImages, false)
+	if err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if len(report.Imported) != 1 || report.Imported[0] != "recent" {
+		t.Errorf("Expected the recent file to be imported after the grace period, got %v", report.Imported)
+	}
+	if len(report.InFlightFiles) != 1 || report.InFlightFiles[0].DriveFileId != "pending" {
+		t.Errorf("Expected the pending file to be skipped, got %v", report.InFlightFiles)
+	}
+}
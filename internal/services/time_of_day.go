@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/interfaces"
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/solar"
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// timeOfDayPhases maps image tags to the phases of the day they match
+var timeOfDayPhases = map[pb.TimeOfDay]solar.Phase{
+	pb.TimeOfDay_TIME_OF_DAY_DAWN:  solar.Dawn,
+	pb.TimeOfDay_TIME_OF_DAY_DAY:   solar.Day,
+	pb.TimeOfDay_TIME_OF_DAY_DUSK:  solar.Dusk,
+	pb.TimeOfDay_TIME_OF_DAY_NIGHT: solar.Night,
+}
+
+// phaseTimesOfDay is the inverse of timeOfDayPhases
+var phaseTimesOfDay = map[solar.Phase]pb.TimeOfDay{
+	solar.Dawn:  pb.TimeOfDay_TIME_OF_DAY_DAWN,
+	solar.Day:   pb.TimeOfDay_TIME_OF_DAY_DAY,
+	solar.Dusk:  pb.TimeOfDay_TIME_OF_DAY_DUSK,
+	solar.Night: pb.TimeOfDay_TIME_OF_DAY_NIGHT,
+}
+
+// validateTimeOfDay checks a client-supplied time of day tag
+func validateTimeOfDay(name string, timeOfDay pb.TimeOfDay) error {
+	if _, ok := pb.TimeOfDay_name[int32(timeOfDay)]; !ok {
+		return status.Errorf(codes.InvalidArgument, "unsupported %s %d", name, timeOfDay)
+	}
+	return nil
+}
+
+// viewerDay is the part of the day a viewer is in
+type viewerDay struct {
+	phase solar.Phase
+	// sunrise and sunset are nil during polar day and night
+	sunrise, sunset *time.Time
+	// nextChange is when the phase changes, or nil when it does not within two days
+	nextChange *time.Time
+}
+
+// newViewerDay works out the viewer's part of the day at now from the
+// coordinates or time zone in req
+func newViewerDay(req *pb.GetCurrentImageRequest, now time.Time) (*viewerDay, error) {
+	lat, lng, loc, err := viewerPosition(req, now)
+	if err != nil {
+		return nil, err
+	}
+
+	viewer := &viewerDay{phase: solar.PhaseAt(now, lat, lng)}
+	if sunrise, sunset, ok := solar.SunTimes(now.In(loc), lat, lng); ok {
+		viewer.sunrise, viewer.sunset = &sunrise, &sunset
+	}
+	if next, ok := solar.NextPhaseChange(now, lat, lng); ok {
+		viewer.nextChange = &next
+	}
+	return viewer, nil
+}
+
+// viewerPosition returns the viewer's coordinates and the location whose
+// calendar day sunrise and sunset are reported for. With only a time zone,
+// the sun is placed on the equator at the zone's standard meridian, which gets
+// the clock times of the phases roughly right but not their seasonal drift.
+func viewerPosition(req *pb.GetCurrentImageRequest, now time.Time) (float64, float64, *time.Location, error) {
+	var loc *time.Location
+	if req.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(req.Timezone); err != nil {
+			return 0, 0, nil, status.Errorf(codes.InvalidArgument, "unknown timezone %q", req.Timezone)
+		}
+	}
+
+	if req.Latitude != nil || req.Longitude != nil {
+		if req.Latitude == nil || req.Longitude == nil {
+			return 0, 0, nil, status.Error(codes.InvalidArgument, "latitude and longitude must be given together")
+		}
+		lat, lng := *req.Latitude, *req.Longitude
+		if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+			return 0, 0, nil, status.Error(codes.InvalidArgument, "latitude must be between -90 and 90 and longitude between -180 and 180")
+		}
+		if loc == nil {
+			// The local calendar day follows the sun rather than a zone
+			loc = time.FixedZone("", int(math.Round(lng/15))*3600)
+		}
+		return lat, lng, loc, nil
+	}
+
+	if loc == nil {
+		return 0, 0, nil, status.Error(codes.InvalidArgument, "the time of day mode needs latitude and longitude or a timezone")
+	}
+	return 0, float64(standardOffset(loc, now.Year())) / 3600 * 15, loc, nil
+}
+
+// standardOffset returns a location's UTC offset in seconds outside daylight
+// saving time, which only ever moves clocks forward
+func standardOffset(loc *time.Location, year int) int {
+	_, january := time.Date(year, time.January, 1, 0, 0, 0, 0, loc).Zone()
+	_, july := time.Date(year, time.July, 1, 0, 0, 0, 0, loc).Zone()
+	return min(january, july)
+}
+
+// apply reports the viewer's day in resp; it does nothing on a nil viewer
+func (v *viewerDay) apply(resp *pb.GetCurrentImageResponse) {
+	if v == nil {
+		return
+	}
+	resp.TimeOfDay = phaseTimesOfDay[v.phase]
+	if v.sunrise != nil {
+		resp.Sunrise = timestamppb.New(*v.sunrise)
+		resp.Sunset = timestamppb.New(*v.sunset)
+	}
+}
+
+// selectedImage returns the image to show at now when nothing is pinned, and
+// when the choice next changes. For a viewer it rotates among the images tagged
+// with their part of the day, falling back to the rotation when there are none.
+func (s *ImageService) selectedImage(ctx context.Context, viewer *viewerDay, now time.Time, draw drawOptions) (interface{}, *time.Time, error) {
+	if viewer == nil {
+		return s.rotatedImage(ctx, now, draw)
+	}
+
+	image, next, err := s.timeOfDayImage(ctx, viewer.phase, now, draw)
+	if err != nil {
+		log.Printf("Warning: failed to find %s images, using the rotation: %v", viewer.phase, err)
+	}
+	if image == nil {
+		var rotated interface{}
+		if rotated, next, err = s.rotatedImage(ctx, now, draw); err != nil {
+			return nil, nil, err
+		}
+		return rotated, earliest(next, viewer.nextChange), nil
+	}
+	return image, earliest(next, viewer.nextChange), nil
+}
+
+// timeOfDayImage returns the visible image tagged with phase that the rotation
+// settings select at now, or nil when no image has the tag
+func (s *ImageService) timeOfDayImage(ctx context.Context, phase solar.Phase, now time.Time, draw drawOptions) (*pb.ImageMetadata, *time.Time, error) {
+	images, err := s.visibleImages(ctx, &interfaces.ImageQuery{
+		TimeOfDay: string(phase),
+		VisibleAt: &now,
+		Ascending: true,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(images) == 0 {
+		return nil, nil, nil
+	}
+
+	settings, err := s.rotationSettings(ctx)
+	if err != nil {
+		log.Printf("Warning: failed to read rotation settings, serving the newest image: %v", err)
+		settings = defaultRotationSettings()
+	}
+	// Same schedule as the rotation, over the matching images; the viewer
+	// keeps a separate shuffle bag for each part of the day
+	image, next := s.pickImage(ctx, settings, images, now, draw, string(phase))
+	return image, next, nil
+}
+
+// visibleImages returns the images matching query
+func (s *ImageService) visibleImages(ctx context.Context, query *interfaces.ImageQuery) ([]*pb.ImageMetadata, error) {
+	imagesInterface, err := s.dbService.QueryImages(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []*pb.ImageMetadata
+	for _, imgInterface := range imagesInterface {
+		if img, ok := imgInterface.(*pb.ImageMetadata); ok {
+			images = append(images, img)
+		}
+	}
+	return images, nil
+}
+
+// earliest returns the earlier of two optional times
+func earliest(a, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.Before(*a)) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/NirvekPanda/Background-Image-Drive-API/proto/gen"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTimeOfDayMode(t *testing.T) {
+	ctx := context.Background()
+	storage, dbService := newTestBackends(t)
+	service := NewImageService(storage, dbService)
+
+	// 01:00 in New York
+	now := time.Date(2024, 6, 20, 5, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	if _, err := service.UploadImage(ctx, &pb.UploadImageRequest{ImageData: testPNG(t, 8, 8), TimeOfDay: 9}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unknown time of day, got %v", err)
+	}
+
+	upload := func(size int, timeOfDay pb.TimeOfDay) string {
+		t.Helper()
+		resp, err := service.UploadImage(ctx, &pb.UploadImageRequest{ImageData: testPNG(t, size, size), TimeOfDay: timeOfDay})
+		if err != nil || !resp.Success {
+			t.Fatalf("Upload failed: %v %v", err, resp)
+		}
+		return resp.ImageId
+	}
+	day := upload(8, pb.TimeOfDay_TIME_OF_DAY_DAY)
+	upload(9, pb.TimeOfDay_TIME_OF_DAY_NIGHT)
+	night := upload(10, pb.TimeOfDay_TIME_OF_DAY_NIGHT)
+	untagged := upload(11, pb.TimeOfDay_TIME_OF_DAY_UNSPECIFIED)
+
+	newYork := &pb.GetCurrentImageRequest{
+		Mode:      pb.CurrentImageMode_CURRENT_IMAGE_MODE_TIME_OF_DAY,
+		Latitude:  proto.Float64(40.7128),
+		Longitude: proto.Float64(-74.0060),
+	}
+	current := func(req *pb.GetCurrentImageRequest) *pb.GetCurrentImageResponse {
+		t.Helper()
+		resp, err := service.GetCurrentImage(ctx, req)
+		if err != nil || !resp.Success {
+			t.Fatalf("GetCurrentImage failed: %v %v", err, resp)
+		}
+		return resp
+	}
+
+	resp := current(newYork)
+	if resp.Metadata.Id != night || resp.TimeOfDay != pb.TimeOfDay_TIME_OF_DAY_NIGHT {
+		t.Errorf("Expected the newest night image at night, got %s %v", resp.Metadata.Id, resp.TimeOfDay)
+	}
+	// Sunrise is at 05:25 EDT, and dawn starts at civil twilight before it
+	if resp.Sunrise == nil || resp.Sunrise.AsTime().Hour() != 9 || resp.NextRotationAt == nil ||
+		!resp.NextRotationAt.AsTime().After(now) || !resp.NextRotationAt.AsTime().Before(resp.Sunrise.AsTime()) {
+		t.Errorf("Expected the next change at dawn before sunrise %v, got %v", resp.Sunrise, resp.NextRotationAt)
+	}
+	if resp := current(&pb.GetCurrentImageRequest{}); resp.Metadata.Id != untagged || resp.TimeOfDay != pb.TimeOfDay_TIME_OF_DAY_UNSPECIFIED {
+		t.Errorf("Expected the newest image without a mode, got %s %v", resp.Metadata.Id, resp.TimeOfDay)
+	}
+	if resp := current(&pb.GetCurrentImageRequest{Mode: newYork.Mode, Timezone: "America/New_York"}); resp.Metadata.Id != night {
+		t.Errorf("Expected the night image from the time zone alone, got %s", resp.Metadata.Id)
+	}
+
+	// 13:00 in New York
+	now = time.Date(2024, 6, 20, 17, 0, 0, 0, time.UTC)
+	if resp := current(newYork); resp.Metadata.Id != day || resp.TimeOfDay != pb.TimeOfDay_TIME_OF_DAY_DAY {
+		t.Errorf("Expected the day image at noon, got %s %v", resp.Metadata.Id, resp.TimeOfDay)
+	}
+
+	// 20:30 in New York: no dusk images, so the rotation decides
+	now = time.Date(2024, 6, 21, 0, 30, 0, 0, time.UTC)
+	if resp := current(newYork); resp.Metadata.Id != untagged || resp.TimeOfDay != pb.TimeOfDay_TIME_OF_DAY_DUSK {
+		t.Errorf("Expected the newest image at dusk, got %s %v", resp.Metadata.Id, resp.TimeOfDay)
+	}
+
+	for name, req := range map[string]*pb.GetCurrentImageRequest{
+		"no position":      {Mode: newYork.Mode},
+		"latitude only":    {Mode: newYork.Mode, Latitude: proto.Float64(40)},
+		"unknown timezone": {Mode: newYork.Mode, Timezone: "Mars/Olympus_Mons"},
+		"out of range":     {Mode: newYork.Mode, Latitude: proto.Float64(91), Longitude: proto.Float64(0)},
+	} {
+		if _, err := service.GetCurrentImage(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected InvalidArgument, got %v", name, err)
+		}
+	}
+
+	list, err := service.ListImages(ctx, &pb.ListImagesRequest{TimeOfDay: pb.TimeOfDay_TIME_OF_DAY_NIGHT})
+	if err != nil || len(list.Images) != 2 {
+		t.Errorf("Expected two night images, got %v %v", err, list)
+	}
+
+	// Tags can be changed and removed
+	untag := pb.TimeOfDay_TIME_OF_DAY_UNSPECIFIED
+	updated, err := service.UpdateImage(ctx, &pb.UpdateImageRequest{ImageId: day, TimeOfDay: &untag})
+	if err != nil || !updated.Success || updated.Metadata.TimeOfDay != untag {
+		t.Errorf("UpdateImage failed: %v %v", err, updated)
+	}
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/config"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrTokenNotFound is returned by a TokenStore that holds no token yet
+var ErrTokenNotFound = errors.New("oauth token not found")
+
+// TokenStore persists OAuth2 tokens
+type TokenStore interface {
+	Load(ctx context.Context) (*oauth2.Token, error)
+	Save(ctx context.Context, token *oauth2.Token) error
+}
+
+// FileTokenStore stores the OAuth2 token as JSON in a local file
+type FileTokenStore struct {
+	path string
+}
+
+// NewFileTokenStore creates a token store backed by the file at path
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+// Load reads the token from disk
+func (s *FileTokenStore) Load(ctx context.Context) (*oauth2.Token, error) {
+	tokenData, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to read token: %v", err)
+	}
+
+	return decodeToken(tokenData)
+}
+
+// Save writes the token to disk
+func (s *FileTokenStore) Save(ctx context.Context, token *oauth2.Token) error {
+	tokenData, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %v", err)
+	}
+	return os.WriteFile(s.path, tokenData, 0600)
+}
+
+// decodeToken parses a JSON-encoded OAuth2 token
+func decodeToken(tokenData []byte) (*oauth2.Token, error) {
+	if len(tokenData) == 0 {
+		return nil, ErrTokenNotFound
+	}
+
+	var token oauth2.Token
+	if err := json.Unmarshal(tokenData, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse token: %v", err)
+	}
+	return &token, nil
+}
+
+// MemoryTokenStore keeps the OAuth2 token in memory only (useful for tests and local runs)
+type MemoryTokenStore struct {
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+// NewMemoryTokenStore creates an empty in-memory token store
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{}
+}
+
+// Load returns a copy of the stored token
+func (s *MemoryTokenStore) Load(ctx context.Context) (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == nil {
+		return nil, ErrTokenNotFound
+	}
+	token := *s.token
+	return &token, nil
+}
+
+// Save replaces the stored token
+func (s *MemoryTokenStore) Save(ctx context.Context, token *oauth2.Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := *token
+	s.token = &stored
+	return nil
+}
+
+// secretVersions reads and adds versions of secrets; implemented by config.SecretManager
+type secretVersions interface {
+	GetSecret(secretName string) (string, error)
+	AddSecretVersion(secretName string, data []byte) error
+}
+
+// SecretManagerTokenStore stores the OAuth2 token as versions of a Secret Manager secret
+type SecretManagerTokenStore struct {
+	secretManager secretVersions
+	secretName    string
+
+	mu       sync.Mutex
+	lastData []byte
+}
+
+// NewSecretManagerTokenStore creates a token store backed by the named secret
+func NewSecretManagerTokenStore(secretManager *config.SecretManager, secretName string) *SecretManagerTokenStore {
+	return &SecretManagerTokenStore{
+		secretManager: secretManager,
+		secretName:    secretName,
+	}
+}
+
+// Load reads the latest version of the secret
+func (s *SecretManagerTokenStore) Load(ctx context.Context) (*oauth2.Token, error) {
+	tokenData, err := s.secretManager.GetSecret(s.secretName)
+	if err != nil {
+		if status.Code(err) == codes.NotFound || status.Code(err) == codes.FailedPrecondition {
+			return nil, ErrTokenNotFound
+		}
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.lastData = []byte(tokenData)
+	s.mu.Unlock()
+
+	return decodeToken([]byte(tokenData))
+}
+
+// Save adds a new secret version when the refresh token changed. Refreshes
+// only replace the access token and its expiry, which a restart recreates, so
+// they are not written; otherwise the secret would gain a version every hour.
+func (s *SecretManagerTokenStore) Save(ctx context.Context, token *oauth2.Token) error {
+	tokenData, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if bytes.Equal(tokenData, s.lastData) {
+		return nil
+	}
+	if s.lastData != nil {
+		if previous, err := decodeToken(s.lastData); err == nil && (token.RefreshToken == "" || token.RefreshToken == previous.RefreshToken) {
+			return nil
+		}
+	}
+
+	if err := s.secretManager.AddSecretVersion(s.secretName, tokenData); err != nil {
+		return err
+	}
+
+	s.lastData = tokenData
+	return nil
+}
+
+// NewTokenStoreFromEnv creates the token store selected by OAUTH_TOKEN_STORE
+// ("file", "secretmanager" or "memory"). It defaults to Secret Manager when a
+// client is available and to the token file otherwise.
+func NewTokenStoreFromEnv(secretManager *config.SecretManager, tokenPath string) (TokenStore, error) {
+	storeType := os.Getenv("OAUTH_TOKEN_STORE")
+	if storeType == "" {
+		storeType = "file"
+		if secretManager != nil {
+			storeType = "secretmanager"
+		}
+	}
+
+	switch storeType {
+	case "file":
+		return NewFileTokenStore(tokenPath), nil
+	case "secretmanager":
+		if secretManager == nil {
+			return nil, fmt.Errorf("secretmanager token store requires Secret Manager")
+		}
+		secretName := os.Getenv("OAUTH_TOKEN_SECRET")
+		if secretName == "" {
+			secretName = "oauth-token"
+		}
+		return NewSecretManagerTokenStore(secretManager, secretName), nil
+	case "memory":
+		return NewMemoryTokenStore(), nil
+	default:
+		return nil, fmt.Errorf("unsupported token store: %s", storeType)
+	}
+}
+
+// persistingTokenSource writes tokens back to a TokenStore whenever the
+// underlying source refreshes them, so rotated refresh tokens survive restarts
+type persistingTokenSource struct {
+	base  oauth2.TokenSource
+	store TokenStore
+
+	mu   sync.Mutex
+	last *oauth2.Token
+}
+
+// newPersistingTokenSource wraps base; current is the token already held by the store
+func newPersistingTokenSource(base oauth2.TokenSource, store TokenStore, current *oauth2.Token) *persistingTokenSource {
+	return &persistingTokenSource{
+		base:  base,
+		store: store,
+		last:  current,
+	}
+}
+
+// Token implements oauth2.TokenSource
+func (s *persistingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.base.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if tokenChanged(s.last, token) {
+		// A failed write must not break Drive calls; the next call retries it
+		if err := s.store.Save(context.Background(), token); err != nil {
+			log.Printf("Warning: failed to persist refreshed OAuth token: %v", err)
+			return token, nil
+		}
+		s.last = token
+	}
+
+	return token, nil
+}
+
+// tokenChanged reports whether token differs from the previously persisted one
+func tokenChanged(previous, token *oauth2.Token) bool {
+	if previous == nil {
+		return true
+	}
+	return previous.AccessToken != token.AccessToken ||
+		previous.RefreshToken != token.RefreshToken ||
+		!previous.Expiry.Equal(token.Expiry)
+}
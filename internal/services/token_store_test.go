@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sequenceTokenSource returns the given tokens in order, repeating the last one
+type sequenceTokenSource struct {
+	tokens []*oauth2.Token
+	calls  int
+}
+
+func (s *sequenceTokenSource) Token() (*oauth2.Token, error) {
+	index := s.calls
+	if index >= len(s.tokens) {
+		index = len(s.tokens) - 1
+	}
+	s.calls++
+	return s.tokens[index], nil
+}
+
+// countingTokenStore counts how often a token is saved
+type countingTokenStore struct {
+	MemoryTokenStore
+	saves int
+}
+
+func (s *countingTokenStore) Save(ctx context.Context, token *oauth2.Token) error {
+	s.saves++
+	return s.MemoryTokenStore.Save(ctx, token)
+}
+
+func TestTokenStores(t *testing.T) {
+	ctx := context.Background()
+	token := &oauth2.Token{AccessToken: "access", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour).Round(time.Second)}
+
+	stores := map[string]TokenStore{
+		"file":   NewFileTokenStore(filepath.Join(t.TempDir(), "token.json")),
+		"memory": NewMemoryTokenStore(),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			if _, err := store.Load(ctx); !errors.Is(err, ErrTokenNotFound) {
+				t.Fatalf("Expected ErrTokenNotFound from empty store, got %v", err)
+			}
+
+			if err := store.Save(ctx, token); err != nil {
+				t.Fatalf("Save failed: %v", err)
+			}
+
+			loaded, err := store.Load(ctx)
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if loaded.AccessToken != token.AccessToken || loaded.RefreshToken != token.RefreshToken {
+				t.Errorf("Expected token %+v, got %+v", token, loaded)
+			}
+		})
+	}
+}
+
+func TestPersistingTokenSource(t *testing.T) {
+	initial := &oauth2.Token{AccessToken: "access-1", RefreshToken: "refresh-1", Expiry: time.Now().Add(time.Hour)}
+	refreshed := &oauth2.Token{AccessToken: "access-2", RefreshToken: "refresh-2", Expiry: time.Now().Add(2 * time.Hour)}
+
+	store := &countingTokenStore{}
+	base := &sequenceTokenSource{tokens: []*oauth2.Token{initial, initial, refreshed, refreshed}}
+	source := newPersistingTokenSource(base, store, initial)
+
+	for i := 0; i < 4; i++ {
+		if _, err := source.Token(); err != nil {
+			t.Fatalf("Token failed: %v", err)
+		}
+	}
+
+	if store.saves != 1 {
+		t.Errorf("Expected exactly one save for the refreshed token, got %d", store.saves)
+	}
+
+	stored, err := store.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if stored.RefreshToken != "refresh-2" {
+		t.Errorf("Expected rotated refresh token to be persisted, got %q", stored.RefreshToken)
+	}
+}
+
+// fakeSecretVersions keeps secret versions in memory
+type fakeSecretVersions struct {
+	versions [][]byte
+}
+
+func (f *fakeSecretVersions) GetSecret(secretName string) (string, error) {
+	if len(f.versions) == 0 {
+		return "", status.Error(codes.NotFound, "secret has no versions")
+	}
+	return string(f.versions[len(f.versions)-1]), nil
+}
+
+func (f *fakeSecretVersions) AddSecretVersion(secretName string, data []byte) error {
+	f.versions = append(f.versions, data)
+	return nil
+}
+
+func TestSecretManagerTokenStoreVersions(t *testing.T) {
+	ctx := context.Background()
+	secrets := &fakeSecretVersions{}
+	store := &SecretManagerTokenStore{secretManager: secrets, secretName: "oauth-token"}
+
+	if _, err := store.Load(ctx); !errors.Is(err, ErrTokenNotFound) {
+		t.Fatalf("Expected ErrTokenNotFound from empty secret, got %v", err)
+	}
+
+	initial := &oauth2.Token{AccessToken: "access-1", RefreshToken: "refresh-1", Expiry: time.Now().Add(time.Hour)}
+	if err := store.Save(ctx, initial); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Two hourly refreshes that keep the refresh token
+	base := &sequenceTokenSource{tokens: []*oauth2.Token{
+		{AccessToken: "access-2", RefreshToken: "refresh-1", Expiry: time.Now().Add(2 * time.Hour)},
+		{AccessToken: "access-3", RefreshToken: "refresh-1", Expiry: time.Now().Add(3 * time.Hour)},
+	}}
+	source := newPersistingTokenSource(base, store, initial)
+	for i := 0; i < 2; i++ {
+		if _, err := source.Token(); err != nil {
+			t.Fatalf("Token failed: %v", err)
+		}
+	}
+	if len(secrets.versions) != 1 {
+		t.Errorf("Expected refreshes with the same refresh token to write one version, got %d", len(secrets.versions))
+	}
+
+	// A restarted instance loads the stored token and refreshes it without a new version
+	restarted := &SecretManagerTokenStore{secretManager: secrets, secretName: "oauth-token"}
+	if _, err := restarted.Load(ctx); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := restarted.Save(ctx, &oauth2.Token{AccessToken: "access-4", RefreshToken: "refresh-1"}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if len(secrets.versions) != 1 {
+		t.Errorf("Expected a refresh after a restart not to write a version, got %d", len(secrets.versions))
+	}
+
+	if err := restarted.Save(ctx, &oauth2.Token{AccessToken: "access-5", RefreshToken: "refresh-2"}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	stored, err := restarted.Load(ctx)
+	if err != nil || stored.RefreshToken != "refresh-2" || len(secrets.versions) != 2 {
+		t.Errorf("Expected a rotated refresh token to write a version, got %v versions: %+v %v", len(secrets.versions), stored, err)
+	}
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/NirvekPanda/Background-Image-Drive-API/internal/imaging"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Error details attached to upload limit errors
+const (
+	uploadLimitReason = "IMAGE_LIMIT_EXCEEDED"
+	uploadLimitDomain = "bgapi"
+)
+
+// uploadMessageOverhead is the room left in gRPC messages for the fields besides the image data
+const uploadMessageOverhead = 1 << 20
+
+// DefaultUploadLimits returns the default upload limits
+func DefaultUploadLimits() imaging.Limits {
+	return imaging.Limits{
+		MaxBytes:  20 << 20,
+		MaxPixels: 50_000_000,
+		MaxFrames: 300,
+		// About 50 frames of the largest accepted image
+		MaxTotalPixels: 250_000_000,
+	}
+}
+
+// LoadUploadLimitsFromEnv reads IMAGE_MAX_BYTES, IMAGE_MAX_PIXELS,
+// IMAGE_MAX_FRAMES and IMAGE_MAX_TOTAL_PIXELS (0 disables a limit), falling
+// back to the defaults
+func LoadUploadLimitsFromEnv() imaging.Limits {
+	limits := DefaultUploadLimits()
+
+	readLimit := func(name string, target *int64) {
+		value := os.Getenv(name)
+		if value == "" {
+			return
+		}
+		limit, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || limit < 0 {
+			log.Printf("Warning: invalid %s %q, using %d", name, value, *target)
+			return
+		}
+		*target = limit
+	}
+
+	readLimit("IMAGE_MAX_BYTES", &limits.MaxBytes)
+	readLimit("IMAGE_MAX_PIXELS", &limits.MaxPixels)
+	readLimit("IMAGE_MAX_TOTAL_PIXELS", &limits.MaxTotalPixels)
+	maxFrames := int64(limits.MaxFrames)
+	readLimit("IMAGE_MAX_FRAMES", &maxFrames)
+	limits.MaxFrames = int(min(maxFrames, math.MaxInt32))
+
+	return limits
+}
+
+// MaxUploadMessageSize returns the gRPC message size needed to carry uploads within limits
+func MaxUploadMessageSize(limits imaging.Limits) int {
+	if limits.MaxBytes <= 0 || limits.MaxBytes > math.MaxInt32-uploadMessageOverhead {
+		return math.MaxInt32
+	}
+	return int(limits.MaxBytes) + uploadMessageOverhead
+}
+
+// UploadLimits returns the limits enforced on uploaded images
+func (s *ImageService) UploadLimits() imaging.Limits {
+	return s.config.Limits
+}
+
+// uploadLimitError converts a limit violation to a gRPC status: ResourceExhausted
+// for the byte limit and FailedPrecondition for the pixel and frame limits. The
+// violated limit is attached as ErrorInfo so it survives a gRPC round trip.
+func uploadLimitError(limitErr *imaging.LimitError) error {
+	code := codes.FailedPrecondition
+	if limitErr.Limit == imaging.LimitBytes {
+		code = codes.ResourceExhausted
+	}
+
+	st := status.New(code, limitErr.Error())
+	withDetails, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: uploadLimitReason,
+		Domain: uploadLimitDomain,
+		Metadata: map[string]string{
+			"limit":  limitErr.Limit,
+			"actual": strconv.FormatInt(limitErr.Actual, 10),
+			"max":    strconv.FormatInt(limitErr.Max, 10),
+		},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// UploadLimitFromError returns the limit violation reported by an UploadImage
+// error, also after a gRPC round trip, or nil for other errors
+func UploadLimitFromError(err error) *imaging.LimitError {
+	var limitErr *imaging.LimitError
+	if errors.As(err, &limitErr) {
+		return limitErr
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.Reason != uploadLimitReason || info.Domain != uploadLimitDomain {
+			continue
+		}
+		actual, _ := strconv.ParseInt(info.Metadata["actual"], 10, 64)
+		limit, _ := strconv.ParseInt(info.Metadata["max"], 10, 64)
+		return &imaging.LimitError{Limit: info.Metadata["limit"], Actual: actual, Max: limit}
+	}
+	return nil
+}
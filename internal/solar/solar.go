@@ -0,0 +1,160 @@
+// Package solar computes the position of the sun, sunrise and sunset, and the
+// phase of the day for an observer on Earth. It uses the low-precision
+// formulas of the Astronomical Almanac, accurate to about a minute of time
+// between 1950 and 2050, and needs no external service.
+package solar
+
+import (
+	"math"
+	"time"
+)
+
+// Phase is the part of the day an observer is in
+type Phase string
+
+// Phases of the day, by the sun's elevation: dawn and dusk span civil twilight
+// and the low sun after sunrise and before sunset, while the sun is within
+// TwilightAltitude and DayAltitude of the horizon
+const (
+	Dawn  Phase = "dawn"
+	Day   Phase = "day"
+	Dusk  Phase = "dusk"
+	Night Phase = "night"
+)
+
+// Sun elevations in degrees that bound the phases of the day
+const (
+	// SunriseAltitude is the elevation of the sun's centre when its upper
+	// edge touches the horizon, allowing for atmospheric refraction
+	SunriseAltitude = -0.833
+	// TwilightAltitude ends civil twilight; below it is night
+	TwilightAltitude = -6.0
+	// DayAltitude is where dawn ends and dusk begins
+	DayAltitude = 6.0
+)
+
+// searchStep is the sampling interval when looking for elevation crossings.
+// The sun moves at most about 15 degrees an hour, so no crossing is missed
+// except when the sun barely grazes an altitude near the poles.
+const searchStep = 5 * time.Minute
+
+// ParsePhase returns the phase named s, or false when s is not a phase
+func ParsePhase(s string) (Phase, bool) {
+	switch phase := Phase(s); phase {
+	case Dawn, Day, Dusk, Night:
+		return phase, true
+	}
+	return "", false
+}
+
+// Elevation returns the angle of the sun's centre above the horizon in degrees
+// at t, for an observer at lat, lng (degrees, north and east positive).
+// Refraction is not included.
+func Elevation(t time.Time, lat, lng float64) float64 {
+	// Days since J2000.0
+	n := float64(t.UnixNano())/float64(24*time.Hour) + 2440587.5 - 2451545.0
+
+	meanLongitude := 280.460 + 0.9856474*n
+	meanAnomaly := radians(357.528 + 0.9856003*n)
+	eclipticLongitude := radians(meanLongitude + 1.915*math.Sin(meanAnomaly) + 0.020*math.Sin(2*meanAnomaly))
+	obliquity := radians(23.439 - 0.0000004*n)
+
+	rightAscension := math.Atan2(math.Cos(obliquity)*math.Sin(eclipticLongitude), math.Cos(eclipticLongitude))
+	declination := math.Asin(math.Sin(obliquity) * math.Sin(eclipticLongitude))
+
+	siderealTime := 18.697374558 + 24.06570982441908*n // Greenwich mean sidereal time in hours
+	hourAngle := radians(siderealTime*15+lng) - rightAscension
+
+	latitude := radians(lat)
+	sinElevation := math.Sin(latitude)*math.Sin(declination) + math.Cos(latitude)*math.Cos(declination)*math.Cos(hourAngle)
+	return degrees(math.Asin(math.Max(-1, math.Min(1, sinElevation))))
+}
+
+// SunTimes returns the sunrise and sunset on the calendar day of day, in its
+// location. ok is false when the sun does not both rise and set that day, as
+// in polar day and polar night.
+func SunTimes(day time.Time, lat, lng float64) (sunrise, sunset time.Time, ok bool) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+
+	sunrise, rises := crossing(start, end, lat, lng, SunriseAltitude, true)
+	sunset, sets := crossing(start, end, lat, lng, SunriseAltitude, false)
+	if !rises || !sets {
+		return time.Time{}, time.Time{}, false
+	}
+	return sunrise.In(day.Location()), sunset.In(day.Location()), true
+}
+
+// PhaseAt returns the phase of the day at t for an observer at lat, lng
+func PhaseAt(t time.Time, lat, lng float64) Phase {
+	elevation := Elevation(t, lat, lng)
+	switch {
+	case elevation >= DayAltitude:
+		return Day
+	case elevation < TwilightAltitude:
+		return Night
+	case Elevation(t.Add(time.Minute), lat, lng) > elevation:
+		return Dawn
+	default:
+		return Dusk
+	}
+}
+
+// NextPhaseChange returns when the phase at t next changes, looking up to two
+// days ahead; ok is false when it stays the same, as in polar day and night
+func NextPhaseChange(t time.Time, lat, lng float64) (time.Time, bool) {
+	phase := PhaseAt(t, lat, lng)
+	end := t.Add(48 * time.Hour)
+	for from := t; from.Before(end); from = from.Add(searchStep) {
+		to := from.Add(searchStep)
+		if PhaseAt(to, lat, lng) != phase {
+			return bisect(from, to, func(t time.Time) bool {
+				return PhaseAt(t, lat, lng) == phase
+			}), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// crossing returns the first time in [from, to) at which the sun's elevation
+// passes altitude, rising or setting
+func crossing(from, to time.Time, lat, lng, altitude float64, rising bool) (time.Time, bool) {
+	above := Elevation(from, lat, lng) >= altitude
+	for t := from; t.Before(to); t = t.Add(searchStep) {
+		next := t.Add(searchStep)
+		nextAbove := Elevation(next, lat, lng) >= altitude
+		if nextAbove != above && nextAbove == rising {
+			at := bisect(t, next, func(t time.Time) bool {
+				return (Elevation(t, lat, lng) >= altitude) == above
+			})
+			if at.Before(to) {
+				return at, true
+			}
+			return time.Time{}, false
+		}
+		above = nextAbove
+	}
+	return time.Time{}, false
+}
+
+// bisect narrows [lo, hi], where before is true at lo and false at hi, down to
+// a second and returns its end, the earliest time known to be false
+func bisect(lo, hi time.Time, before func(time.Time) bool) time.Time {
+	for hi.Sub(lo) > time.Second {
+		mid := lo.Add(hi.Sub(lo) / 2)
+		if before(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
@@ -0,0 +1,84 @@
+package solar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSunTimes(t *testing.T) {
+	tests := []struct {
+		name            string
+		day             time.Time
+		lat, lng        float64
+		sunrise, sunset string
+	}{
+		{"London winter solstice", time.Date(2024, 12, 21, 0, 0, 0, 0, time.UTC), 51.5074, -0.1278, "08:04", "15:54"},
+		{"New York summer solstice", time.Date(2024, 6, 20, 0, 0, 0, 0, time.FixedZone("EDT", -4*3600)), 40.7128, -74.0060, "05:25", "20:31"},
+		{"Sydney winter solstice", time.Date(2024, 6, 21, 0, 0, 0, 0, time.FixedZone("AEST", 10*3600)), -33.8688, 151.2093, "07:00", "16:54"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sunrise, sunset, ok := SunTimes(tt.day, tt.lat, tt.lng)
+			if !ok {
+				t.Fatal("Expected the sun to rise and set")
+			}
+			for _, check := range []struct {
+				got  time.Time
+				want string
+			}{{sunrise, tt.sunrise}, {sunset, tt.sunset}} {
+				want, _ := time.ParseInLocation("2006-01-02 15:04", tt.day.Format("2006-01-02 ")+check.want, tt.day.Location())
+				if diff := check.got.Sub(want).Abs(); diff > 2*time.Minute {
+					t.Errorf("Expected %s, got %s", check.want, check.got.Format("15:04:05"))
+				}
+			}
+		})
+	}
+
+	// Tromsø has polar night in December and midnight sun in June
+	for _, day := range []time.Time{time.Date(2024, 12, 21, 0, 0, 0, 0, time.UTC), time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC)} {
+		if _, _, ok := SunTimes(day, 69.6492, 18.9553); ok {
+			t.Errorf("Expected no sunrise and sunset in Tromsø on %s", day.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestPhaseAt(t *testing.T) {
+	edt := time.FixedZone("EDT", -4*3600)
+	lat, lng := 40.7128, -74.0060 // New York
+
+	tests := []struct {
+		at   time.Time
+		want Phase
+	}{
+		{time.Date(2024, 6, 20, 1, 0, 0, 0, edt), Night},
+		{time.Date(2024, 6, 20, 5, 15, 0, 0, edt), Dawn},
+		{time.Date(2024, 6, 20, 13, 0, 0, 0, edt), Day},
+		{time.Date(2024, 6, 20, 20, 30, 0, 0, edt), Dusk},
+		{time.Date(2024, 6, 20, 23, 0, 0, 0, edt), Night},
+	}
+	for _, tt := range tests {
+		if got := PhaseAt(tt.at, lat, lng); got != tt.want {
+			t.Errorf("At %s expected %s, got %s", tt.at.Format("15:04"), tt.want, got)
+		}
+	}
+
+	// Night ends with dawn, at the start of civil twilight
+	night := time.Date(2024, 6, 20, 1, 0, 0, 0, edt)
+	change, ok := NextPhaseChange(night, lat, lng)
+	if !ok || PhaseAt(change, lat, lng) != Dawn || PhaseAt(change.Add(-2*time.Second), lat, lng) != Night {
+		t.Errorf("Expected the next change to be dawn, got %v %v", change, ok)
+	}
+	if elevation := Elevation(change, lat, lng); elevation < TwilightAltitude-0.1 || elevation > TwilightAltitude+0.1 {
+		t.Errorf("Expected dawn to start at %v degrees, got %v", TwilightAltitude, elevation)
+	}
+
+	// The phase never changes in the midnight sun
+	if _, ok := NextPhaseChange(time.Date(2024, 6, 21, 12, 0, 0, 0, time.UTC), 78.2232, 15.6267); ok {
+		t.Error("Expected no phase change in Svalbard at midsummer")
+	}
+
+	if _, ok := ParsePhase("noon"); ok {
+		t.Error("Expected noon not to be a phase")
+	}
+}
@@ -9,7 +9,7 @@ package gen
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
-	_ "google.golang.org/protobuf/types/known/timestamppb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -22,6 +22,158 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type TimeOfDay int32
+
+const (
+	TimeOfDay_TIME_OF_DAY_UNSPECIFIED TimeOfDay = 0 // Untagged
+	TimeOfDay_TIME_OF_DAY_DAWN        TimeOfDay = 1
+	TimeOfDay_TIME_OF_DAY_DAY         TimeOfDay = 2
+	TimeOfDay_TIME_OF_DAY_DUSK        TimeOfDay = 3
+	TimeOfDay_TIME_OF_DAY_NIGHT       TimeOfDay = 4
+)
+
+// Enum value maps for TimeOfDay.
+var (
+	TimeOfDay_name = map[int32]string{
+		0: "TIME_OF_DAY_UNSPECIFIED",
+		1: "TIME_OF_DAY_DAWN",
+		2: "TIME_OF_DAY_DAY",
+		3: "TIME_OF_DAY_DUSK",
+		4: "TIME_OF_DAY_NIGHT",
+	}
+	TimeOfDay_value = map[string]int32{
+		"TIME_OF_DAY_UNSPECIFIED": 0,
+		"TIME_OF_DAY_DAWN":        1,
+		"TIME_OF_DAY_DAY":         2,
+		"TIME_OF_DAY_DUSK":        3,
+		"TIME_OF_DAY_NIGHT":       4,
+	}
+)
+
+func (x TimeOfDay) Enum() *TimeOfDay {
+	p := new(TimeOfDay)
+	*p = x
+	return p
+}
+
+func (x TimeOfDay) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TimeOfDay) Descriptor() protoreflect.EnumDescriptor {
+	return file_imageservice_proto_enumTypes[0].Descriptor()
+}
+
+func (TimeOfDay) Type() protoreflect.EnumType {
+	return &file_imageservice_proto_enumTypes[0]
+}
+
+func (x TimeOfDay) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TimeOfDay.Descriptor instead.
+func (TimeOfDay) EnumDescriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{0}
+}
+
+type CurrentImageMode int32
+
+const (
+	CurrentImageMode_CURRENT_IMAGE_MODE_ROTATION CurrentImageMode = 0 // The pin or the rotation settings decide
+	// Prefer images tagged with the viewer's part of the day, rotating among them;
+	// falls back to the rotation when none match
+	CurrentImageMode_CURRENT_IMAGE_MODE_TIME_OF_DAY CurrentImageMode = 1
+)
+
+// Enum value maps for CurrentImageMode.
+var (
+	CurrentImageMode_name = map[int32]string{
+		0: "CURRENT_IMAGE_MODE_ROTATION",
+		1: "CURRENT_IMAGE_MODE_TIME_OF_DAY",
+	}
+	CurrentImageMode_value = map[string]int32{
+		"CURRENT_IMAGE_MODE_ROTATION":    0,
+		"CURRENT_IMAGE_MODE_TIME_OF_DAY": 1,
+	}
+)
+
+func (x CurrentImageMode) Enum() *CurrentImageMode {
+	p := new(CurrentImageMode)
+	*p = x
+	return p
+}
+
+func (x CurrentImageMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CurrentImageMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_imageservice_proto_enumTypes[1].Descriptor()
+}
+
+func (CurrentImageMode) Type() protoreflect.EnumType {
+	return &file_imageservice_proto_enumTypes[1]
+}
+
+func (x CurrentImageMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CurrentImageMode.Descriptor instead.
+func (CurrentImageMode) EnumDescriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{1}
+}
+
+type DuplicatePolicy int32
+
+const (
+	DuplicatePolicy_DUPLICATE_POLICY_ALLOW  DuplicatePolicy = 0 // Upload without checking
+	DuplicatePolicy_DUPLICATE_POLICY_WARN   DuplicatePolicy = 1 // Upload and list the near duplicates in similar_images
+	DuplicatePolicy_DUPLICATE_POLICY_REJECT DuplicatePolicy = 2 // Fail with ALREADY_EXISTS when there are near duplicates
+)
+
+// Enum value maps for DuplicatePolicy.
+var (
+	DuplicatePolicy_name = map[int32]string{
+		0: "DUPLICATE_POLICY_ALLOW",
+		1: "DUPLICATE_POLICY_WARN",
+		2: "DUPLICATE_POLICY_REJECT",
+	}
+	DuplicatePolicy_value = map[string]int32{
+		"DUPLICATE_POLICY_ALLOW":  0,
+		"DUPLICATE_POLICY_WARN":   1,
+		"DUPLICATE_POLICY_REJECT": 2,
+	}
+)
+
+func (x DuplicatePolicy) Enum() *DuplicatePolicy {
+	p := new(DuplicatePolicy)
+	*p = x
+	return p
+}
+
+func (x DuplicatePolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DuplicatePolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_imageservice_proto_enumTypes[2].Descriptor()
+}
+
+func (DuplicatePolicy) Type() protoreflect.EnumType {
+	return &file_imageservice_proto_enumTypes[2]
+}
+
+func (x DuplicatePolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DuplicatePolicy.Descriptor instead.
+func (DuplicatePolicy) EnumDescriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{2}
+}
+
 // Location data structure
 type Location struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -109,12 +261,46 @@ func (x *Location) GetAddress() string {
 
 // Image metadata structure
 type ImageMetadata struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Location      *Location              `protobuf:"bytes,4,opt,name=location,proto3" json:"location,omitempty"`
-	DriveFileId   string                 `protobuf:"bytes,5,opt,name=drive_file_id,json=driveFileId,proto3" json:"drive_file_id,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title       string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Location    *Location              `protobuf:"bytes,4,opt,name=location,proto3" json:"location,omitempty"`
+	DriveFileId string                 `protobuf:"bytes,5,opt,name=drive_file_id,json=driveFileId,proto3" json:"drive_file_id,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ContentHash string                 `protobuf:"bytes,7,opt,name=content_hash,json=contentHash,proto3" json:"content_hash,omitempty"` // Hex-encoded SHA-256 of the uploaded image bytes
+	MimeType    string                 `protobuf:"bytes,8,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`          // Detected type of the stored image, e.g. image/jpeg
+	// Capture metadata read from EXIF at upload; unset when the photo does not carry it
+	TakenAt       *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=taken_at,json=takenAt,proto3" json:"taken_at,omitempty"`
+	CameraMake    string                 `protobuf:"bytes,10,opt,name=camera_make,json=cameraMake,proto3" json:"camera_make,omitempty"`
+	CameraModel   string                 `protobuf:"bytes,11,opt,name=camera_model,json=cameraModel,proto3" json:"camera_model,omitempty"`
+	LensModel     string                 `protobuf:"bytes,12,opt,name=lens_model,json=lensModel,proto3" json:"lens_model,omitempty"`
+	FocalLengthMm float64                `protobuf:"fixed64,13,opt,name=focal_length_mm,json=focalLengthMm,proto3" json:"focal_length_mm,omitempty"`
+	Aperture      float64                `protobuf:"fixed64,14,opt,name=aperture,proto3" json:"aperture,omitempty"` // f-number
+	Iso           int32                  `protobuf:"varint,15,opt,name=iso,proto3" json:"iso,omitempty"`
+	ExposureTime  float64                `protobuf:"fixed64,16,opt,name=exposure_time,json=exposureTime,proto3" json:"exposure_time,omitempty"` // Seconds
+	ColorTheme    *ColorTheme            `protobuf:"bytes,17,opt,name=color_theme,json=colorTheme,proto3" json:"color_theme,omitempty"`
+	Blurhash      string                 `protobuf:"bytes,18,opt,name=blurhash,proto3" json:"blurhash,omitempty"` // BlurHash of the image (https://blurha.sh)
+	Lqip          string                 `protobuf:"bytes,19,opt,name=lqip,proto3" json:"lqip,omitempty"`         // Tiny base64 data URI rendition for use as a placeholder
+	// Point kept in view when cropping, as fractions of the width and height from
+	// the top left corner. Detected on upload and editable with UpdateImage.
+	FocalX *float64 `protobuf:"fixed64,20,opt,name=focal_x,json=focalX,proto3,oneof" json:"focal_x,omitempty"`
+	FocalY *float64 `protobuf:"fixed64,21,opt,name=focal_y,json=focalY,proto3,oneof" json:"focal_y,omitempty"`
+	// Storage file ID of the unmodified upload, kept privately when uploads are
+	// sanitized; drive_file_id then holds the metadata-stripped public copy
+	OriginalFileId string `protobuf:"bytes,22,opt,name=original_file_id,json=originalFileId,proto3" json:"original_file_id,omitempty"`
+	// Hex-encoded 64-bit difference hash (dHash) of the served image; near
+	// duplicates differ in few bits
+	PerceptualHash string `protobuf:"bytes,23,opt,name=perceptual_hash,json=perceptualHash,proto3" json:"perceptual_hash,omitempty"`
+	// Window in which the image is listed and shown; unset means no bound.
+	// Outside it the image is hidden from everything but admins.
+	PublishAt *timestamppb.Timestamp `protobuf:"bytes,24,opt,name=publish_at,json=publishAt,proto3" json:"publish_at,omitempty"`
+	ExpireAt  *timestamppb.Timestamp `protobuf:"bytes,25,opt,name=expire_at,json=expireAt,proto3" json:"expire_at,omitempty"`
+	// Part of the day the photo shows, matched against the viewer's in
+	// CURRENT_IMAGE_MODE_TIME_OF_DAY
+	TimeOfDay TimeOfDay `protobuf:"varint,26,opt,name=time_of_day,json=timeOfDay,proto3,enum=imageservice.TimeOfDay" json:"time_of_day,omitempty"`
+	// Relative chance of being drawn by the weighted_random rotation strategy
+	Weight        float64 `protobuf:"fixed64,27,opt,name=weight,proto3" json:"weight,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -184,140 +370,186 @@ func (x *ImageMetadata) GetDriveFileId() string {
 	return ""
 }
 
-// Request messages
-type GetCurrentImageRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *ImageMetadata) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
 }
 
-func (x *GetCurrentImageRequest) Reset() {
-	*x = GetCurrentImageRequest{}
-	mi := &file_imageservice_proto_msgTypes[2]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *ImageMetadata) GetContentHash() string {
+	if x != nil {
+		return x.ContentHash
+	}
+	return ""
 }
 
-func (x *GetCurrentImageRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *ImageMetadata) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
 }
 
-func (*GetCurrentImageRequest) ProtoMessage() {}
+func (x *ImageMetadata) GetTakenAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TakenAt
+	}
+	return nil
+}
 
-func (x *GetCurrentImageRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_imageservice_proto_msgTypes[2]
+func (x *ImageMetadata) GetCameraMake() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.CameraMake
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use GetCurrentImageRequest.ProtoReflect.Descriptor instead.
-func (*GetCurrentImageRequest) Descriptor() ([]byte, []int) {
-	return file_imageservice_proto_rawDescGZIP(), []int{2}
+func (x *ImageMetadata) GetCameraModel() string {
+	if x != nil {
+		return x.CameraModel
+	}
+	return ""
 }
 
-type UploadImageRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Location      *Location              `protobuf:"bytes,4,opt,name=location,proto3" json:"location,omitempty"`
-	ImageData     []byte                 `protobuf:"bytes,5,opt,name=image_data,json=imageData,proto3" json:"image_data,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *ImageMetadata) GetLensModel() string {
+	if x != nil {
+		return x.LensModel
+	}
+	return ""
 }
 
-func (x *UploadImageRequest) Reset() {
-	*x = UploadImageRequest{}
-	mi := &file_imageservice_proto_msgTypes[3]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *ImageMetadata) GetFocalLengthMm() float64 {
+	if x != nil {
+		return x.FocalLengthMm
+	}
+	return 0
 }
 
-func (x *UploadImageRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *ImageMetadata) GetAperture() float64 {
+	if x != nil {
+		return x.Aperture
+	}
+	return 0
 }
 
-func (*UploadImageRequest) ProtoMessage() {}
+func (x *ImageMetadata) GetIso() int32 {
+	if x != nil {
+		return x.Iso
+	}
+	return 0
+}
 
-func (x *UploadImageRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_imageservice_proto_msgTypes[3]
+func (x *ImageMetadata) GetExposureTime() float64 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.ExposureTime
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use UploadImageRequest.ProtoReflect.Descriptor instead.
-func (*UploadImageRequest) Descriptor() ([]byte, []int) {
-	return file_imageservice_proto_rawDescGZIP(), []int{3}
+func (x *ImageMetadata) GetColorTheme() *ColorTheme {
+	if x != nil {
+		return x.ColorTheme
+	}
+	return nil
 }
 
-func (x *UploadImageRequest) GetId() string {
+func (x *ImageMetadata) GetBlurhash() string {
 	if x != nil {
-		return x.Id
+		return x.Blurhash
 	}
 	return ""
 }
 
-func (x *UploadImageRequest) GetTitle() string {
+func (x *ImageMetadata) GetLqip() string {
 	if x != nil {
-		return x.Title
+		return x.Lqip
 	}
 	return ""
 }
 
-func (x *UploadImageRequest) GetDescription() string {
+func (x *ImageMetadata) GetFocalX() float64 {
+	if x != nil && x.FocalX != nil {
+		return *x.FocalX
+	}
+	return 0
+}
+
+func (x *ImageMetadata) GetFocalY() float64 {
+	if x != nil && x.FocalY != nil {
+		return *x.FocalY
+	}
+	return 0
+}
+
+func (x *ImageMetadata) GetOriginalFileId() string {
 	if x != nil {
-		return x.Description
+		return x.OriginalFileId
 	}
 	return ""
 }
 
-func (x *UploadImageRequest) GetLocation() *Location {
+func (x *ImageMetadata) GetPerceptualHash() string {
 	if x != nil {
-		return x.Location
+		return x.PerceptualHash
+	}
+	return ""
+}
+
+func (x *ImageMetadata) GetPublishAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PublishAt
 	}
 	return nil
 }
 
-func (x *UploadImageRequest) GetImageData() []byte {
+func (x *ImageMetadata) GetExpireAt() *timestamppb.Timestamp {
 	if x != nil {
-		return x.ImageData
+		return x.ExpireAt
 	}
 	return nil
 }
 
-type GetImageCountRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *ImageMetadata) GetTimeOfDay() TimeOfDay {
+	if x != nil {
+		return x.TimeOfDay
+	}
+	return TimeOfDay_TIME_OF_DAY_UNSPECIFIED
 }
 
-func (x *GetImageCountRequest) Reset() {
-	*x = GetImageCountRequest{}
-	mi := &file_imageservice_proto_msgTypes[4]
+func (x *ImageMetadata) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+// Colors of an image, used to theme UI drawn on top of it
+type ColorTheme struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	DominantColor    string                 `protobuf:"bytes,1,opt,name=dominant_color,json=dominantColor,proto3" json:"dominant_color,omitempty"`            // #rrggbb
+	Palette          []string               `protobuf:"bytes,2,rep,name=palette,proto3" json:"palette,omitempty"`                                             // #rrggbb, most common first
+	AverageLuminance float64                `protobuf:"fixed64,3,opt,name=average_luminance,json=averageLuminance,proto3" json:"average_luminance,omitempty"` // 0 (black) to 1 (white)
+	Foreground       string                 `protobuf:"bytes,4,opt,name=foreground,proto3" json:"foreground,omitempty"`                                       // Recommended text color: "light" or "dark"
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ColorTheme) Reset() {
+	*x = ColorTheme{}
+	mi := &file_imageservice_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetImageCountRequest) String() string {
+func (x *ColorTheme) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetImageCountRequest) ProtoMessage() {}
+func (*ColorTheme) ProtoMessage() {}
 
-func (x *GetImageCountRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_imageservice_proto_msgTypes[4]
+func (x *ColorTheme) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -328,32 +560,73 @@ func (x *GetImageCountRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetImageCountRequest.ProtoReflect.Descriptor instead.
-func (*GetImageCountRequest) Descriptor() ([]byte, []int) {
-	return file_imageservice_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use ColorTheme.ProtoReflect.Descriptor instead.
+func (*ColorTheme) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{2}
 }
 
-type ListImagesRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+func (x *ColorTheme) GetDominantColor() string {
+	if x != nil {
+		return x.DominantColor
+	}
+	return ""
+}
+
+func (x *ColorTheme) GetPalette() []string {
+	if x != nil {
+		return x.Palette
+	}
+	return nil
+}
+
+func (x *ColorTheme) GetAverageLuminance() float64 {
+	if x != nil {
+		return x.AverageLuminance
+	}
+	return 0
+}
+
+func (x *ColorTheme) GetForeground() string {
+	if x != nil {
+		return x.Foreground
+	}
+	return ""
+}
+
+// Request messages
+type GetCurrentImageRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Mode  CurrentImageMode       `protobuf:"varint,1,opt,name=mode,proto3,enum=imageservice.CurrentImageMode" json:"mode,omitempty"`
+	// Viewer position for CURRENT_IMAGE_MODE_TIME_OF_DAY. Without coordinates,
+	// timezone (an IANA name) approximates the sun from the zone's UTC offset.
+	Latitude  *float64 `protobuf:"fixed64,2,opt,name=latitude,proto3,oneof" json:"latitude,omitempty"`
+	Longitude *float64 `protobuf:"fixed64,3,opt,name=longitude,proto3,oneof" json:"longitude,omitempty"`
+	Timezone  string   `protobuf:"bytes,4,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	// Seed of the weighted_random and shuffle strategies, replacing the one in
+	// the rotation settings; the same seed always selects the same images
+	Seed *int64 `protobuf:"varint,5,opt,name=seed,proto3,oneof" json:"seed,omitempty"`
+	// Opaque token identifying a viewer. With the shuffle strategy each viewer
+	// sees every image once, one per rotation period, before any repeats.
+	ViewerToken   string `protobuf:"bytes,6,opt,name=viewer_token,json=viewerToken,proto3" json:"viewer_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListImagesRequest) Reset() {
-	*x = ListImagesRequest{}
-	mi := &file_imageservice_proto_msgTypes[5]
+func (x *GetCurrentImageRequest) Reset() {
+	*x = GetCurrentImageRequest{}
+	mi := &file_imageservice_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListImagesRequest) String() string {
+func (x *GetCurrentImageRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListImagesRequest) ProtoMessage() {}
+func (*GetCurrentImageRequest) ProtoMessage() {}
 
-func (x *ListImagesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_imageservice_proto_msgTypes[5]
+func (x *GetCurrentImageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -364,33 +637,1196 @@ func (x *ListImagesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListImagesRequest.ProtoReflect.Descriptor instead.
-func (*ListImagesRequest) Descriptor() ([]byte, []int) {
-	return file_imageservice_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use GetCurrentImageRequest.ProtoReflect.Descriptor instead.
+func (*GetCurrentImageRequest) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{3}
 }
 
-type GetImageByIdRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ImageId       string                 `protobuf:"bytes,1,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *GetCurrentImageRequest) GetMode() CurrentImageMode {
+	if x != nil {
+		return x.Mode
+	}
+	return CurrentImageMode_CURRENT_IMAGE_MODE_ROTATION
 }
 
-func (x *GetImageByIdRequest) Reset() {
-	*x = GetImageByIdRequest{}
-	mi := &file_imageservice_proto_msgTypes[6]
+func (x *GetCurrentImageRequest) GetLatitude() float64 {
+	if x != nil && x.Latitude != nil {
+		return *x.Latitude
+	}
+	return 0
+}
+
+func (x *GetCurrentImageRequest) GetLongitude() float64 {
+	if x != nil && x.Longitude != nil {
+		return *x.Longitude
+	}
+	return 0
+}
+
+func (x *GetCurrentImageRequest) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *GetCurrentImageRequest) GetSeed() int64 {
+	if x != nil && x.Seed != nil {
+		return *x.Seed
+	}
+	return 0
+}
+
+func (x *GetCurrentImageRequest) GetViewerToken() string {
+	if x != nil {
+		return x.ViewerToken
+	}
+	return ""
+}
+
+type UploadImageRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title       string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Location    *Location              `protobuf:"bytes,4,opt,name=location,proto3" json:"location,omitempty"`
+	ImageData   []byte                 `protobuf:"bytes,5,opt,name=image_data,json=imageData,proto3" json:"image_data,omitempty"`
+	// Retries with the same key return the original response instead of a new image
+	IdempotencyKey string `protobuf:"bytes,6,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// What to do when the image is a near duplicate of a stored image
+	DuplicatePolicy DuplicatePolicy `protobuf:"varint,7,opt,name=duplicate_policy,json=duplicatePolicy,proto3,enum=imageservice.DuplicatePolicy" json:"duplicate_policy,omitempty"`
+	// Largest perceptual hash distance (0-64) counted as a near duplicate;
+	// defaults to the server's IMAGE_DUPLICATE_THRESHOLD
+	DuplicateThreshold *int32 `protobuf:"varint,8,opt,name=duplicate_threshold,json=duplicateThreshold,proto3,oneof" json:"duplicate_threshold,omitempty"`
+	// Optional publish window; expire_at must be after publish_at
+	PublishAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=publish_at,json=publishAt,proto3" json:"publish_at,omitempty"`
+	ExpireAt      *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=expire_at,json=expireAt,proto3" json:"expire_at,omitempty"`
+	TimeOfDay     TimeOfDay              `protobuf:"varint,11,opt,name=time_of_day,json=timeOfDay,proto3,enum=imageservice.TimeOfDay" json:"time_of_day,omitempty"`
+	Weight        *float64               `protobuf:"fixed64,12,opt,name=weight,proto3,oneof" json:"weight,omitempty"` // Greater than 0 and at most 1000; defaults to 1
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadImageRequest) Reset() {
+	*x = UploadImageRequest{}
+	mi := &file_imageservice_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadImageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadImageRequest) ProtoMessage() {}
+
+func (x *UploadImageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadImageRequest.ProtoReflect.Descriptor instead.
+func (*UploadImageRequest) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UploadImageRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UploadImageRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *UploadImageRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *UploadImageRequest) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *UploadImageRequest) GetImageData() []byte {
+	if x != nil {
+		return x.ImageData
+	}
+	return nil
+}
+
+func (x *UploadImageRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *UploadImageRequest) GetDuplicatePolicy() DuplicatePolicy {
+	if x != nil {
+		return x.DuplicatePolicy
+	}
+	return DuplicatePolicy_DUPLICATE_POLICY_ALLOW
+}
+
+func (x *UploadImageRequest) GetDuplicateThreshold() int32 {
+	if x != nil && x.DuplicateThreshold != nil {
+		return *x.DuplicateThreshold
+	}
+	return 0
+}
+
+func (x *UploadImageRequest) GetPublishAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PublishAt
+	}
+	return nil
+}
+
+func (x *UploadImageRequest) GetExpireAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpireAt
+	}
+	return nil
+}
+
+func (x *UploadImageRequest) GetTimeOfDay() TimeOfDay {
+	if x != nil {
+		return x.TimeOfDay
+	}
+	return TimeOfDay_TIME_OF_DAY_UNSPECIFIED
+}
+
+func (x *UploadImageRequest) GetWeight() float64 {
+	if x != nil && x.Weight != nil {
+		return *x.Weight
+	}
+	return 0
+}
+
+type GetImageCountRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One of "visible", "scheduled" (not yet published) or "expired"; empty or
+	// "all" counts every image
+	Visibility    string `protobuf:"bytes,1,opt,name=visibility,proto3" json:"visibility,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetImageCountRequest) Reset() {
+	*x = GetImageCountRequest{}
+	mi := &file_imageservice_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetImageCountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetImageCountRequest) ProtoMessage() {}
+
+func (x *GetImageCountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetImageCountRequest.ProtoReflect.Descriptor instead.
+func (*GetImageCountRequest) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetImageCountRequest) GetVisibility() string {
+	if x != nil {
+		return x.Visibility
+	}
+	return ""
+}
+
+type ListImagesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Filters; unset fields match every image
+	TakenAfter       *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=taken_after,json=takenAfter,proto3" json:"taken_after,omitempty"`
+	TakenBefore      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=taken_before,json=takenBefore,proto3" json:"taken_before,omitempty"`
+	CameraMake       string                 `protobuf:"bytes,3,opt,name=camera_make,json=cameraMake,proto3" json:"camera_make,omitempty"`    // Case-insensitive
+	CameraModel      string                 `protobuf:"bytes,4,opt,name=camera_model,json=cameraModel,proto3" json:"camera_model,omitempty"` // Case-insensitive
+	LensModel        string                 `protobuf:"bytes,5,opt,name=lens_model,json=lensModel,proto3" json:"lens_model,omitempty"`       // Case-insensitive
+	MinIso           *int32                 `protobuf:"varint,6,opt,name=min_iso,json=minIso,proto3,oneof" json:"min_iso,omitempty"`
+	MaxIso           *int32                 `protobuf:"varint,7,opt,name=max_iso,json=maxIso,proto3,oneof" json:"max_iso,omitempty"`
+	MinFocalLengthMm *float64               `protobuf:"fixed64,8,opt,name=min_focal_length_mm,json=minFocalLengthMm,proto3,oneof" json:"min_focal_length_mm,omitempty"`
+	MaxFocalLengthMm *float64               `protobuf:"fixed64,9,opt,name=max_focal_length_mm,json=maxFocalLengthMm,proto3,oneof" json:"max_focal_length_mm,omitempty"`
+	// created_at (default), taken_at, camera, focal_length, aperture, iso or exposure_time
+	SortBy string `protobuf:"bytes,10,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`
+	// asc or desc (default)
+	SortOrder string `protobuf:"bytes,11,opt,name=sort_order,json=sortOrder,proto3" json:"sort_order,omitempty"`
+	// Include images outside their publish window (admin only over HTTP)
+	IncludeHidden bool      `protobuf:"varint,12,opt,name=include_hidden,json=includeHidden,proto3" json:"include_hidden,omitempty"`
+	TimeOfDay     TimeOfDay `protobuf:"varint,13,opt,name=time_of_day,json=timeOfDay,proto3,enum=imageservice.TimeOfDay" json:"time_of_day,omitempty"` // Only images with this tag when set
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListImagesRequest) Reset() {
+	*x = ListImagesRequest{}
+	mi := &file_imageservice_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListImagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListImagesRequest) ProtoMessage() {}
+
+func (x *ListImagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListImagesRequest.ProtoReflect.Descriptor instead.
+func (*ListImagesRequest) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListImagesRequest) GetTakenAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TakenAfter
+	}
+	return nil
+}
+
+func (x *ListImagesRequest) GetTakenBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TakenBefore
+	}
+	return nil
+}
+
+func (x *ListImagesRequest) GetCameraMake() string {
+	if x != nil {
+		return x.CameraMake
+	}
+	return ""
+}
+
+func (x *ListImagesRequest) GetCameraModel() string {
+	if x != nil {
+		return x.CameraModel
+	}
+	return ""
+}
+
+func (x *ListImagesRequest) GetLensModel() string {
+	if x != nil {
+		return x.LensModel
+	}
+	return ""
+}
+
+func (x *ListImagesRequest) GetMinIso() int32 {
+	if x != nil && x.MinIso != nil {
+		return *x.MinIso
+	}
+	return 0
+}
+
+func (x *ListImagesRequest) GetMaxIso() int32 {
+	if x != nil && x.MaxIso != nil {
+		return *x.MaxIso
+	}
+	return 0
+}
+
+func (x *ListImagesRequest) GetMinFocalLengthMm() float64 {
+	if x != nil && x.MinFocalLengthMm != nil {
+		return *x.MinFocalLengthMm
+	}
+	return 0
+}
+
+func (x *ListImagesRequest) GetMaxFocalLengthMm() float64 {
+	if x != nil && x.MaxFocalLengthMm != nil {
+		return *x.MaxFocalLengthMm
+	}
+	return 0
+}
+
+func (x *ListImagesRequest) GetSortBy() string {
+	if x != nil {
+		return x.SortBy
+	}
+	return ""
+}
+
+func (x *ListImagesRequest) GetSortOrder() string {
+	if x != nil {
+		return x.SortOrder
+	}
+	return ""
+}
+
+func (x *ListImagesRequest) GetIncludeHidden() bool {
+	if x != nil {
+		return x.IncludeHidden
+	}
+	return false
+}
+
+func (x *ListImagesRequest) GetTimeOfDay() TimeOfDay {
+	if x != nil {
+		return x.TimeOfDay
+	}
+	return TimeOfDay_TIME_OF_DAY_UNSPECIFIED
+}
+
+type GetImageByIdRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ImageId       string                 `protobuf:"bytes,1,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
+	IncludeHidden bool                   `protobuf:"varint,2,opt,name=include_hidden,json=includeHidden,proto3" json:"include_hidden,omitempty"` // Return the image even outside its publish window
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetImageByIdRequest) Reset() {
+	*x = GetImageByIdRequest{}
+	mi := &file_imageservice_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetImageByIdRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetImageByIdRequest) ProtoMessage() {}
+
+func (x *GetImageByIdRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetImageByIdRequest.ProtoReflect.Descriptor instead.
+func (*GetImageByIdRequest) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetImageByIdRequest) GetImageId() string {
+	if x != nil {
+		return x.ImageId
+	}
+	return ""
+}
+
+func (x *GetImageByIdRequest) GetIncludeHidden() bool {
+	if x != nil {
+		return x.IncludeHidden
+	}
+	return false
+}
+
+type UpdateImageRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ImageId        string                 `protobuf:"bytes,1,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
+	Title          *string                `protobuf:"bytes,2,opt,name=title,proto3,oneof" json:"title,omitempty"`
+	Description    *string                `protobuf:"bytes,3,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	Location       *Location              `protobuf:"bytes,4,opt,name=location,proto3" json:"location,omitempty"`                   // Replaces the stored location when set
+	FocalX         *float64               `protobuf:"fixed64,5,opt,name=focal_x,json=focalX,proto3,oneof" json:"focal_x,omitempty"` // Between 0 and 1; cached crops are regenerated
+	FocalY         *float64               `protobuf:"fixed64,6,opt,name=focal_y,json=focalY,proto3,oneof" json:"focal_y,omitempty"`
+	PublishAt      *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=publish_at,json=publishAt,proto3" json:"publish_at,omitempty"`                                       // Replaces the publish time when set
+	ExpireAt       *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=expire_at,json=expireAt,proto3" json:"expire_at,omitempty"`                                          // Replaces the expiry time when set
+	ClearPublishAt bool                   `protobuf:"varint,9,opt,name=clear_publish_at,json=clearPublishAt,proto3" json:"clear_publish_at,omitempty"`                     // Publish immediately
+	ClearExpireAt  bool                   `protobuf:"varint,10,opt,name=clear_expire_at,json=clearExpireAt,proto3" json:"clear_expire_at,omitempty"`                       // Never expire
+	TimeOfDay      *TimeOfDay             `protobuf:"varint,11,opt,name=time_of_day,json=timeOfDay,proto3,enum=imageservice.TimeOfDay,oneof" json:"time_of_day,omitempty"` // TIME_OF_DAY_UNSPECIFIED removes the tag
+	Weight         *float64               `protobuf:"fixed64,12,opt,name=weight,proto3,oneof" json:"weight,omitempty"`                                                     // Greater than 0 and at most 1000
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UpdateImageRequest) Reset() {
+	*x = UpdateImageRequest{}
+	mi := &file_imageservice_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateImageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateImageRequest) ProtoMessage() {}
+
+func (x *UpdateImageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateImageRequest.ProtoReflect.Descriptor instead.
+func (*UpdateImageRequest) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdateImageRequest) GetImageId() string {
+	if x != nil {
+		return x.ImageId
+	}
+	return ""
+}
+
+func (x *UpdateImageRequest) GetTitle() string {
+	if x != nil && x.Title != nil {
+		return *x.Title
+	}
+	return ""
+}
+
+func (x *UpdateImageRequest) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *UpdateImageRequest) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *UpdateImageRequest) GetFocalX() float64 {
+	if x != nil && x.FocalX != nil {
+		return *x.FocalX
+	}
+	return 0
+}
+
+func (x *UpdateImageRequest) GetFocalY() float64 {
+	if x != nil && x.FocalY != nil {
+		return *x.FocalY
+	}
+	return 0
+}
+
+func (x *UpdateImageRequest) GetPublishAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PublishAt
+	}
+	return nil
+}
+
+func (x *UpdateImageRequest) GetExpireAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpireAt
+	}
+	return nil
+}
+
+func (x *UpdateImageRequest) GetClearPublishAt() bool {
+	if x != nil {
+		return x.ClearPublishAt
+	}
+	return false
+}
+
+func (x *UpdateImageRequest) GetClearExpireAt() bool {
+	if x != nil {
+		return x.ClearExpireAt
+	}
+	return false
+}
+
+func (x *UpdateImageRequest) GetTimeOfDay() TimeOfDay {
+	if x != nil && x.TimeOfDay != nil {
+		return *x.TimeOfDay
+	}
+	return TimeOfDay_TIME_OF_DAY_UNSPECIFIED
+}
+
+func (x *UpdateImageRequest) GetWeight() float64 {
+	if x != nil && x.Weight != nil {
+		return *x.Weight
+	}
+	return 0
+}
+
+type DeleteImageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ImageId       string                 `protobuf:"bytes,1,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteImageRequest) Reset() {
+	*x = DeleteImageRequest{}
+	mi := &file_imageservice_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteImageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteImageRequest) ProtoMessage() {}
+
+func (x *DeleteImageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteImageRequest.ProtoReflect.Descriptor instead.
+func (*DeleteImageRequest) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeleteImageRequest) GetImageId() string {
+	if x != nil {
+		return x.ImageId
+	}
+	return ""
+}
+
+type PinCurrentImageRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	ImageId string                 `protobuf:"bytes,1,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
+	// When the pin expires and the rotation resumes; unset pins until
+	// UnpinCurrentImage. Replaces any active pin.
+	Until         *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=until,proto3" json:"until,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PinCurrentImageRequest) Reset() {
+	*x = PinCurrentImageRequest{}
+	mi := &file_imageservice_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PinCurrentImageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PinCurrentImageRequest) ProtoMessage() {}
+
+func (x *PinCurrentImageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PinCurrentImageRequest.ProtoReflect.Descriptor instead.
+func (*PinCurrentImageRequest) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PinCurrentImageRequest) GetImageId() string {
+	if x != nil {
+		return x.ImageId
+	}
+	return ""
+}
+
+func (x *PinCurrentImageRequest) GetUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Until
+	}
+	return nil
+}
+
+type UnpinCurrentImageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnpinCurrentImageRequest) Reset() {
+	*x = UnpinCurrentImageRequest{}
+	mi := &file_imageservice_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnpinCurrentImageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnpinCurrentImageRequest) ProtoMessage() {}
+
+func (x *UnpinCurrentImageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnpinCurrentImageRequest.ProtoReflect.Descriptor instead.
+func (*UnpinCurrentImageRequest) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{11}
+}
+
+type ListPinsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"` // Defaults to 50, at most 500
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPinsRequest) Reset() {
+	*x = ListPinsRequest{}
+	mi := &file_imageservice_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPinsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPinsRequest) ProtoMessage() {}
+
+func (x *ListPinsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPinsRequest.ProtoReflect.Descriptor instead.
+func (*ListPinsRequest) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListPinsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetSimilarImagesRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	ImageId string                 `protobuf:"bytes,1,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
+	// Largest perceptual hash distance (0-64) to include; defaults to the
+	// server's IMAGE_DUPLICATE_THRESHOLD
+	MaxDistance   *int32 `protobuf:"varint,2,opt,name=max_distance,json=maxDistance,proto3,oneof" json:"max_distance,omitempty"`
+	Limit         int32  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`                                      // Defaults to 20, at most 100
+	IncludeHidden bool   `protobuf:"varint,4,opt,name=include_hidden,json=includeHidden,proto3" json:"include_hidden,omitempty"` // Also match images outside their publish window
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSimilarImagesRequest) Reset() {
+	*x = GetSimilarImagesRequest{}
+	mi := &file_imageservice_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSimilarImagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSimilarImagesRequest) ProtoMessage() {}
+
+func (x *GetSimilarImagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSimilarImagesRequest.ProtoReflect.Descriptor instead.
+func (*GetSimilarImagesRequest) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetSimilarImagesRequest) GetImageId() string {
+	if x != nil {
+		return x.ImageId
+	}
+	return ""
+}
+
+func (x *GetSimilarImagesRequest) GetMaxDistance() int32 {
+	if x != nil && x.MaxDistance != nil {
+		return *x.MaxDistance
+	}
+	return 0
+}
+
+func (x *GetSimilarImagesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetSimilarImagesRequest) GetIncludeHidden() bool {
+	if x != nil {
+		return x.IncludeHidden
+	}
+	return false
+}
+
+// Response messages
+type GetCurrentImageResponse struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Success    bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message    string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Metadata   *ImageMetadata         `protobuf:"bytes,3,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	ColorTheme *ColorTheme            `protobuf:"bytes,4,opt,name=color_theme,json=colorTheme,proto3" json:"color_theme,omitempty"` // Same as metadata.color_theme
+	// When the current image next changes, by a scheduled rotation or an expiring
+	// pin; unset when it only changes with uploads
+	NextRotationAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=next_rotation_at,json=nextRotationAt,proto3" json:"next_rotation_at,omitempty"`
+	Pin            *ImagePin              `protobuf:"bytes,6,opt,name=pin,proto3" json:"pin,omitempty"` // Set while a pinned image overrides the rotation
+	// Set in CURRENT_IMAGE_MODE_TIME_OF_DAY: the viewer's part of the day, and
+	// their sunrise and sunset today (unset during polar day and night)
+	TimeOfDay     TimeOfDay              `protobuf:"varint,7,opt,name=time_of_day,json=timeOfDay,proto3,enum=imageservice.TimeOfDay" json:"time_of_day,omitempty"`
+	Sunrise       *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=sunrise,proto3" json:"sunrise,omitempty"`
+	Sunset        *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=sunset,proto3" json:"sunset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCurrentImageResponse) Reset() {
+	*x = GetCurrentImageResponse{}
+	mi := &file_imageservice_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCurrentImageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCurrentImageResponse) ProtoMessage() {}
+
+func (x *GetCurrentImageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCurrentImageResponse.ProtoReflect.Descriptor instead.
+func (*GetCurrentImageResponse) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetCurrentImageResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetCurrentImageResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *GetCurrentImageResponse) GetMetadata() *ImageMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *GetCurrentImageResponse) GetColorTheme() *ColorTheme {
+	if x != nil {
+		return x.ColorTheme
+	}
+	return nil
+}
+
+func (x *GetCurrentImageResponse) GetNextRotationAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NextRotationAt
+	}
+	return nil
+}
+
+func (x *GetCurrentImageResponse) GetPin() *ImagePin {
+	if x != nil {
+		return x.Pin
+	}
+	return nil
+}
+
+func (x *GetCurrentImageResponse) GetTimeOfDay() TimeOfDay {
+	if x != nil {
+		return x.TimeOfDay
+	}
+	return TimeOfDay_TIME_OF_DAY_UNSPECIFIED
+}
+
+func (x *GetCurrentImageResponse) GetSunrise() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Sunrise
+	}
+	return nil
+}
+
+func (x *GetCurrentImageResponse) GetSunset() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Sunset
+	}
+	return nil
+}
+
+// An image featured as the current image
+type ImagePin struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ImageId       string                 `protobuf:"bytes,2,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
+	PinnedAt      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=pinned_at,json=pinnedAt,proto3" json:"pinned_at,omitempty"`
+	Until         *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=until,proto3" json:"until,omitempty"`                             // Unset when the pin does not expire
+	UnpinnedAt    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=unpinned_at,json=unpinnedAt,proto3" json:"unpinned_at,omitempty"` // Set when the pin was ended early
+	Active        bool                   `protobuf:"varint,6,opt,name=active,proto3" json:"active,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImagePin) Reset() {
+	*x = ImagePin{}
+	mi := &file_imageservice_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImagePin) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImagePin) ProtoMessage() {}
+
+func (x *ImagePin) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImagePin.ProtoReflect.Descriptor instead.
+func (*ImagePin) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ImagePin) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ImagePin) GetImageId() string {
+	if x != nil {
+		return x.ImageId
+	}
+	return ""
+}
+
+func (x *ImagePin) GetPinnedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PinnedAt
+	}
+	return nil
+}
+
+func (x *ImagePin) GetUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Until
+	}
+	return nil
+}
+
+func (x *ImagePin) GetUnpinnedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UnpinnedAt
+	}
+	return nil
+}
+
+func (x *ImagePin) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+type UploadImageResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Success  bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message  string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	ImageId  string                 `protobuf:"bytes,3,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
+	Metadata *ImageMetadata         `protobuf:"bytes,4,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Replayed bool                   `protobuf:"varint,5,opt,name=replayed,proto3" json:"replayed,omitempty"` // True when an earlier upload was returned instead of creating a new image
+	// Location fields filled from the image's EXIF GPS data (including reverse-geocoded fields)
+	ExifFields []string `protobuf:"bytes,6,rep,name=exif_fields,json=exifFields,proto3" json:"exif_fields,omitempty"`
+	// Near duplicates of the upload, closest first, with DUPLICATE_POLICY_WARN
+	SimilarImages []*SimilarImage `protobuf:"bytes,7,rep,name=similar_images,json=similarImages,proto3" json:"similar_images,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadImageResponse) Reset() {
+	*x = UploadImageResponse{}
+	mi := &file_imageservice_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadImageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadImageResponse) ProtoMessage() {}
+
+func (x *UploadImageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadImageResponse.ProtoReflect.Descriptor instead.
+func (*UploadImageResponse) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *UploadImageResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *UploadImageResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *UploadImageResponse) GetImageId() string {
+	if x != nil {
+		return x.ImageId
+	}
+	return ""
+}
+
+func (x *UploadImageResponse) GetMetadata() *ImageMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *UploadImageResponse) GetReplayed() bool {
+	if x != nil {
+		return x.Replayed
+	}
+	return false
+}
+
+func (x *UploadImageResponse) GetExifFields() []string {
+	if x != nil {
+		return x.ExifFields
+	}
+	return nil
+}
+
+func (x *UploadImageResponse) GetSimilarImages() []*SimilarImage {
+	if x != nil {
+		return x.SimilarImages
+	}
+	return nil
+}
+
+type GetImageCountResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Count         int32                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"` // Images in the requested visibility state
+	Visible       int32                  `protobuf:"varint,2,opt,name=visible,proto3" json:"visible,omitempty"`
+	Scheduled     int32                  `protobuf:"varint,3,opt,name=scheduled,proto3" json:"scheduled,omitempty"`
+	Expired       int32                  `protobuf:"varint,4,opt,name=expired,proto3" json:"expired,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetImageCountResponse) Reset() {
+	*x = GetImageCountResponse{}
+	mi := &file_imageservice_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetImageCountResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetImageCountResponse) ProtoMessage() {}
+
+func (x *GetImageCountResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetImageCountResponse.ProtoReflect.Descriptor instead.
+func (*GetImageCountResponse) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetImageCountResponse) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *GetImageCountResponse) GetVisible() int32 {
+	if x != nil {
+		return x.Visible
+	}
+	return 0
+}
+
+func (x *GetImageCountResponse) GetScheduled() int32 {
+	if x != nil {
+		return x.Scheduled
+	}
+	return 0
+}
+
+func (x *GetImageCountResponse) GetExpired() int32 {
+	if x != nil {
+		return x.Expired
+	}
+	return 0
+}
+
+type ListImagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Images        []*ImageMetadata       `protobuf:"bytes,3,rep,name=images,proto3" json:"images,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListImagesResponse) Reset() {
+	*x = ListImagesResponse{}
+	mi := &file_imageservice_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetImageByIdRequest) String() string {
+func (x *ListImagesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetImageByIdRequest) ProtoMessage() {}
+func (*ListImagesResponse) ProtoMessage() {}
 
-func (x *GetImageByIdRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_imageservice_proto_msgTypes[6]
+func (x *ListImagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -401,40 +1837,56 @@ func (x *GetImageByIdRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetImageByIdRequest.ProtoReflect.Descriptor instead.
-func (*GetImageByIdRequest) Descriptor() ([]byte, []int) {
-	return file_imageservice_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use ListImagesResponse.ProtoReflect.Descriptor instead.
+func (*ListImagesResponse) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{18}
 }
 
-func (x *GetImageByIdRequest) GetImageId() string {
+func (x *ListImagesResponse) GetSuccess() bool {
 	if x != nil {
-		return x.ImageId
+		return x.Success
+	}
+	return false
+}
+
+func (x *ListImagesResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
 	}
 	return ""
 }
 
-type DeleteImageRequest struct {
+func (x *ListImagesResponse) GetImages() []*ImageMetadata {
+	if x != nil {
+		return x.Images
+	}
+	return nil
+}
+
+type GetImageByIdResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ImageId       string                 `protobuf:"bytes,1,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Metadata      *ImageMetadata         `protobuf:"bytes,3,opt,name=metadata,proto3" json:"metadata,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteImageRequest) Reset() {
-	*x = DeleteImageRequest{}
-	mi := &file_imageservice_proto_msgTypes[7]
+func (x *GetImageByIdResponse) Reset() {
+	*x = GetImageByIdResponse{}
+	mi := &file_imageservice_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteImageRequest) String() string {
+func (x *GetImageByIdResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteImageRequest) ProtoMessage() {}
+func (*GetImageByIdResponse) ProtoMessage() {}
 
-func (x *DeleteImageRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_imageservice_proto_msgTypes[7]
+func (x *GetImageByIdResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -445,20 +1897,33 @@ func (x *DeleteImageRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteImageRequest.ProtoReflect.Descriptor instead.
-func (*DeleteImageRequest) Descriptor() ([]byte, []int) {
-	return file_imageservice_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use GetImageByIdResponse.ProtoReflect.Descriptor instead.
+func (*GetImageByIdResponse) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{19}
 }
 
-func (x *DeleteImageRequest) GetImageId() string {
+func (x *GetImageByIdResponse) GetSuccess() bool {
 	if x != nil {
-		return x.ImageId
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetImageByIdResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
 	}
 	return ""
 }
 
-// Response messages
-type GetCurrentImageResponse struct {
+func (x *GetImageByIdResponse) GetMetadata() *ImageMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type UpdateImageResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
@@ -467,21 +1932,21 @@ type GetCurrentImageResponse struct {
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetCurrentImageResponse) Reset() {
-	*x = GetCurrentImageResponse{}
-	mi := &file_imageservice_proto_msgTypes[8]
+func (x *UpdateImageResponse) Reset() {
+	*x = UpdateImageResponse{}
+	mi := &file_imageservice_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetCurrentImageResponse) String() string {
+func (x *UpdateImageResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetCurrentImageResponse) ProtoMessage() {}
+func (*UpdateImageResponse) ProtoMessage() {}
 
-func (x *GetCurrentImageResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_imageservice_proto_msgTypes[8]
+func (x *UpdateImageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -492,57 +1957,55 @@ func (x *GetCurrentImageResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetCurrentImageResponse.ProtoReflect.Descriptor instead.
-func (*GetCurrentImageResponse) Descriptor() ([]byte, []int) {
-	return file_imageservice_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use UpdateImageResponse.ProtoReflect.Descriptor instead.
+func (*UpdateImageResponse) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{20}
 }
 
-func (x *GetCurrentImageResponse) GetSuccess() bool {
+func (x *UpdateImageResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *GetCurrentImageResponse) GetMessage() string {
+func (x *UpdateImageResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-func (x *GetCurrentImageResponse) GetMetadata() *ImageMetadata {
+func (x *UpdateImageResponse) GetMetadata() *ImageMetadata {
 	if x != nil {
 		return x.Metadata
 	}
 	return nil
 }
 
-type UploadImageResponse struct {
+type DeleteImageResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	ImageId       string                 `protobuf:"bytes,3,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
-	Metadata      *ImageMetadata         `protobuf:"bytes,4,opt,name=metadata,proto3" json:"metadata,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UploadImageResponse) Reset() {
-	*x = UploadImageResponse{}
-	mi := &file_imageservice_proto_msgTypes[9]
+func (x *DeleteImageResponse) Reset() {
+	*x = DeleteImageResponse{}
+	mi := &file_imageservice_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UploadImageResponse) String() string {
+func (x *DeleteImageResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UploadImageResponse) ProtoMessage() {}
+func (*DeleteImageResponse) ProtoMessage() {}
 
-func (x *UploadImageResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_imageservice_proto_msgTypes[9]
+func (x *DeleteImageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -553,61 +2016,102 @@ func (x *UploadImageResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UploadImageResponse.ProtoReflect.Descriptor instead.
-func (*UploadImageResponse) Descriptor() ([]byte, []int) {
-	return file_imageservice_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use DeleteImageResponse.ProtoReflect.Descriptor instead.
+func (*DeleteImageResponse) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{21}
 }
 
-func (x *UploadImageResponse) GetSuccess() bool {
+func (x *DeleteImageResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *UploadImageResponse) GetMessage() string {
+func (x *DeleteImageResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-func (x *UploadImageResponse) GetImageId() string {
+// An image and its perceptual hash distance from another image
+type SimilarImage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Metadata      *ImageMetadata         `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Distance      int32                  `protobuf:"varint,2,opt,name=distance,proto3" json:"distance,omitempty"` // Number of differing hash bits, 0-64
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SimilarImage) Reset() {
+	*x = SimilarImage{}
+	mi := &file_imageservice_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimilarImage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimilarImage) ProtoMessage() {}
+
+func (x *SimilarImage) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[22]
 	if x != nil {
-		return x.ImageId
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *UploadImageResponse) GetMetadata() *ImageMetadata {
+// Deprecated: Use SimilarImage.ProtoReflect.Descriptor instead.
+func (*SimilarImage) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SimilarImage) GetMetadata() *ImageMetadata {
 	if x != nil {
 		return x.Metadata
 	}
 	return nil
 }
 
-type GetImageCountResponse struct {
+func (x *SimilarImage) GetDistance() int32 {
+	if x != nil {
+		return x.Distance
+	}
+	return 0
+}
+
+type PinCurrentImageResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Count         int32                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Pin           *ImagePin              `protobuf:"bytes,3,opt,name=pin,proto3" json:"pin,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetImageCountResponse) Reset() {
-	*x = GetImageCountResponse{}
-	mi := &file_imageservice_proto_msgTypes[10]
+func (x *PinCurrentImageResponse) Reset() {
+	*x = PinCurrentImageResponse{}
+	mi := &file_imageservice_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetImageCountResponse) String() string {
+func (x *PinCurrentImageResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetImageCountResponse) ProtoMessage() {}
+func (*PinCurrentImageResponse) ProtoMessage() {}
 
-func (x *GetImageCountResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_imageservice_proto_msgTypes[10]
+func (x *PinCurrentImageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -618,42 +2122,56 @@ func (x *GetImageCountResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetImageCountResponse.ProtoReflect.Descriptor instead.
-func (*GetImageCountResponse) Descriptor() ([]byte, []int) {
-	return file_imageservice_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use PinCurrentImageResponse.ProtoReflect.Descriptor instead.
+func (*PinCurrentImageResponse) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{23}
 }
 
-func (x *GetImageCountResponse) GetCount() int32 {
+func (x *PinCurrentImageResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Count
+		return x.Success
 	}
-	return 0
+	return false
 }
 
-type ListImagesResponse struct {
+func (x *PinCurrentImageResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *PinCurrentImageResponse) GetPin() *ImagePin {
+	if x != nil {
+		return x.Pin
+	}
+	return nil
+}
+
+type UnpinCurrentImageResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Images        []*ImageMetadata       `protobuf:"bytes,3,rep,name=images,proto3" json:"images,omitempty"`
+	Pin           *ImagePin              `protobuf:"bytes,3,opt,name=pin,proto3" json:"pin,omitempty"` // The pin that was ended
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListImagesResponse) Reset() {
-	*x = ListImagesResponse{}
-	mi := &file_imageservice_proto_msgTypes[11]
+func (x *UnpinCurrentImageResponse) Reset() {
+	*x = UnpinCurrentImageResponse{}
+	mi := &file_imageservice_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListImagesResponse) String() string {
+func (x *UnpinCurrentImageResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListImagesResponse) ProtoMessage() {}
+func (*UnpinCurrentImageResponse) ProtoMessage() {}
 
-func (x *ListImagesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_imageservice_proto_msgTypes[11]
+func (x *UnpinCurrentImageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -664,56 +2182,56 @@ func (x *ListImagesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListImagesResponse.ProtoReflect.Descriptor instead.
-func (*ListImagesResponse) Descriptor() ([]byte, []int) {
-	return file_imageservice_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use UnpinCurrentImageResponse.ProtoReflect.Descriptor instead.
+func (*UnpinCurrentImageResponse) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *ListImagesResponse) GetSuccess() bool {
+func (x *UnpinCurrentImageResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *ListImagesResponse) GetMessage() string {
+func (x *UnpinCurrentImageResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-func (x *ListImagesResponse) GetImages() []*ImageMetadata {
+func (x *UnpinCurrentImageResponse) GetPin() *ImagePin {
 	if x != nil {
-		return x.Images
+		return x.Pin
 	}
 	return nil
 }
 
-type GetImageByIdResponse struct {
+type ListPinsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Metadata      *ImageMetadata         `protobuf:"bytes,3,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Pins          []*ImagePin            `protobuf:"bytes,3,rep,name=pins,proto3" json:"pins,omitempty"` // Newest first
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetImageByIdResponse) Reset() {
-	*x = GetImageByIdResponse{}
-	mi := &file_imageservice_proto_msgTypes[12]
+func (x *ListPinsResponse) Reset() {
+	*x = ListPinsResponse{}
+	mi := &file_imageservice_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetImageByIdResponse) String() string {
+func (x *ListPinsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetImageByIdResponse) ProtoMessage() {}
+func (*ListPinsResponse) ProtoMessage() {}
 
-func (x *GetImageByIdResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_imageservice_proto_msgTypes[12]
+func (x *ListPinsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -724,55 +2242,56 @@ func (x *GetImageByIdResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetImageByIdResponse.ProtoReflect.Descriptor instead.
-func (*GetImageByIdResponse) Descriptor() ([]byte, []int) {
-	return file_imageservice_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use ListPinsResponse.ProtoReflect.Descriptor instead.
+func (*ListPinsResponse) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *GetImageByIdResponse) GetSuccess() bool {
+func (x *ListPinsResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *GetImageByIdResponse) GetMessage() string {
+func (x *ListPinsResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-func (x *GetImageByIdResponse) GetMetadata() *ImageMetadata {
+func (x *ListPinsResponse) GetPins() []*ImagePin {
 	if x != nil {
-		return x.Metadata
+		return x.Pins
 	}
 	return nil
 }
 
-type DeleteImageResponse struct {
+type GetSimilarImagesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Images        []*SimilarImage        `protobuf:"bytes,3,rep,name=images,proto3" json:"images,omitempty"` // Closest first
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteImageResponse) Reset() {
-	*x = DeleteImageResponse{}
-	mi := &file_imageservice_proto_msgTypes[13]
+func (x *GetSimilarImagesResponse) Reset() {
+	*x = GetSimilarImagesResponse{}
+	mi := &file_imageservice_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteImageResponse) String() string {
+func (x *GetSimilarImagesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteImageResponse) ProtoMessage() {}
+func (*GetSimilarImagesResponse) ProtoMessage() {}
 
-func (x *DeleteImageResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_imageservice_proto_msgTypes[13]
+func (x *GetSimilarImagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_imageservice_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -783,25 +2302,32 @@ func (x *DeleteImageResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteImageResponse.ProtoReflect.Descriptor instead.
-func (*DeleteImageResponse) Descriptor() ([]byte, []int) {
-	return file_imageservice_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use GetSimilarImagesResponse.ProtoReflect.Descriptor instead.
+func (*GetSimilarImagesResponse) Descriptor() ([]byte, []int) {
+	return file_imageservice_proto_rawDescGZIP(), []int{26}
 }
 
-func (x *DeleteImageResponse) GetSuccess() bool {
+func (x *GetSimilarImagesResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *DeleteImageResponse) GetMessage() string {
+func (x *GetSimilarImagesResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
+func (x *GetSimilarImagesResponse) GetImages() []*SimilarImage {
+	if x != nil {
+		return x.Images
+	}
+	return nil
+}
+
 // Location service messages
 type GetLocationFromCoordsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -813,7 +2339,7 @@ type GetLocationFromCoordsRequest struct {
 
 func (x *GetLocationFromCoordsRequest) Reset() {
 	*x = GetLocationFromCoordsRequest{}
-	mi := &file_imageservice_proto_msgTypes[14]
+	mi := &file_imageservice_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -825,7 +2351,7 @@ func (x *GetLocationFromCoordsRequest) String() string {
 func (*GetLocationFromCoordsRequest) ProtoMessage() {}
 
 func (x *GetLocationFromCoordsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_imageservice_proto_msgTypes[14]
+	mi := &file_imageservice_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -838,7 +2364,7 @@ func (x *GetLocationFromCoordsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetLocationFromCoordsRequest.ProtoReflect.Descriptor instead.
 func (*GetLocationFromCoordsRequest) Descriptor() ([]byte, []int) {
-	return file_imageservice_proto_rawDescGZIP(), []int{14}
+	return file_imageservice_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *GetLocationFromCoordsRequest) GetLatitude() float64 {
@@ -864,7 +2390,7 @@ type GetLocationFromNameRequest struct {
 
 func (x *GetLocationFromNameRequest) Reset() {
 	*x = GetLocationFromNameRequest{}
-	mi := &file_imageservice_proto_msgTypes[15]
+	mi := &file_imageservice_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -876,7 +2402,7 @@ func (x *GetLocationFromNameRequest) String() string {
 func (*GetLocationFromNameRequest) ProtoMessage() {}
 
 func (x *GetLocationFromNameRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_imageservice_proto_msgTypes[15]
+	mi := &file_imageservice_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -889,7 +2415,7 @@ func (x *GetLocationFromNameRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetLocationFromNameRequest.ProtoReflect.Descriptor instead.
 func (*GetLocationFromNameRequest) Descriptor() ([]byte, []int) {
-	return file_imageservice_proto_rawDescGZIP(), []int{15}
+	return file_imageservice_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *GetLocationFromNameRequest) GetLocationName() string {
@@ -910,7 +2436,7 @@ type GetLocationFromCoordsResponse struct {
 
 func (x *GetLocationFromCoordsResponse) Reset() {
 	*x = GetLocationFromCoordsResponse{}
-	mi := &file_imageservice_proto_msgTypes[16]
+	mi := &file_imageservice_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -922,7 +2448,7 @@ func (x *GetLocationFromCoordsResponse) String() string {
 func (*GetLocationFromCoordsResponse) ProtoMessage() {}
 
 func (x *GetLocationFromCoordsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_imageservice_proto_msgTypes[16]
+	mi := &file_imageservice_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -935,7 +2461,7 @@ func (x *GetLocationFromCoordsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetLocationFromCoordsResponse.ProtoReflect.Descriptor instead.
 func (*GetLocationFromCoordsResponse) Descriptor() ([]byte, []int) {
-	return file_imageservice_proto_rawDescGZIP(), []int{16}
+	return file_imageservice_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *GetLocationFromCoordsResponse) GetSuccess() bool {
@@ -970,7 +2496,7 @@ type GetLocationFromNameResponse struct {
 
 func (x *GetLocationFromNameResponse) Reset() {
 	*x = GetLocationFromNameResponse{}
-	mi := &file_imageservice_proto_msgTypes[17]
+	mi := &file_imageservice_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -982,7 +2508,7 @@ func (x *GetLocationFromNameResponse) String() string {
 func (*GetLocationFromNameResponse) ProtoMessage() {}
 
 func (x *GetLocationFromNameResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_imageservice_proto_msgTypes[17]
+	mi := &file_imageservice_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -995,7 +2521,7 @@ func (x *GetLocationFromNameResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetLocationFromNameResponse.ProtoReflect.Descriptor instead.
 func (*GetLocationFromNameResponse) Descriptor() ([]byte, []int) {
-	return file_imageservice_proto_rawDescGZIP(), []int{17}
+	return file_imageservice_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *GetLocationFromNameResponse) GetSuccess() bool {
@@ -1030,38 +2556,184 @@ const file_imageservice_proto_rawDesc = "" +
 	"\x04name\x18\x03 \x01(\tR\x04name\x12\x18\n" +
 	"\acountry\x18\x04 \x01(\tR\acountry\x12\x12\n" +
 	"\x04city\x18\x05 \x01(\tR\x04city\x12\x18\n" +
-	"\aaddress\x18\x06 \x01(\tR\aaddress\"\xaf\x01\n" +
+	"\aaddress\x18\x06 \x01(\tR\aaddress\"\x96\b\n" +
 	"\rImageMetadata\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
 	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
 	"\vdescription\x18\x03 \x01(\tR\vdescription\x122\n" +
 	"\blocation\x18\x04 \x01(\v2\x16.imageservice.LocationR\blocation\x12\"\n" +
-	"\rdrive_file_id\x18\x05 \x01(\tR\vdriveFileId\"\x18\n" +
-	"\x16GetCurrentImageRequest\"\xaf\x01\n" +
+	"\rdrive_file_id\x18\x05 \x01(\tR\vdriveFileId\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12!\n" +
+	"\fcontent_hash\x18\a \x01(\tR\vcontentHash\x12\x1b\n" +
+	"\tmime_type\x18\b \x01(\tR\bmimeType\x125\n" +
+	"\btaken_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\atakenAt\x12\x1f\n" +
+	"\vcamera_make\x18\n" +
+	" \x01(\tR\n" +
+	"cameraMake\x12!\n" +
+	"\fcamera_model\x18\v \x01(\tR\vcameraModel\x12\x1d\n" +
+	"\n" +
+	"lens_model\x18\f \x01(\tR\tlensModel\x12&\n" +
+	"\x0ffocal_length_mm\x18\r \x01(\x01R\rfocalLengthMm\x12\x1a\n" +
+	"\baperture\x18\x0e \x01(\x01R\baperture\x12\x10\n" +
+	"\x03iso\x18\x0f \x01(\x05R\x03iso\x12#\n" +
+	"\rexposure_time\x18\x10 \x01(\x01R\fexposureTime\x129\n" +
+	"\vcolor_theme\x18\x11 \x01(\v2\x18.imageservice.ColorThemeR\n" +
+	"colorTheme\x12\x1a\n" +
+	"\bblurhash\x18\x12 \x01(\tR\bblurhash\x12\x12\n" +
+	"\x04lqip\x18\x13 \x01(\tR\x04lqip\x12\x1c\n" +
+	"\afocal_x\x18\x14 \x01(\x01H\x00R\x06focalX\x88\x01\x01\x12\x1c\n" +
+	"\afocal_y\x18\x15 \x01(\x01H\x01R\x06focalY\x88\x01\x01\x12(\n" +
+	"\x10original_file_id\x18\x16 \x01(\tR\x0eoriginalFileId\x12'\n" +
+	"\x0fperceptual_hash\x18\x17 \x01(\tR\x0eperceptualHash\x129\n" +
+	"\n" +
+	"publish_at\x18\x18 \x01(\v2\x1a.google.protobuf.TimestampR\tpublishAt\x127\n" +
+	"\texpire_at\x18\x19 \x01(\v2\x1a.google.protobuf.TimestampR\bexpireAt\x127\n" +
+	"\vtime_of_day\x18\x1a \x01(\x0e2\x17.imageservice.TimeOfDayR\ttimeOfDay\x12\x16\n" +
+	"\x06weight\x18\x1b \x01(\x01R\x06weightB\n" +
+	"\n" +
+	"\b_focal_xB\n" +
+	"\n" +
+	"\b_focal_y\"\x9a\x01\n" +
+	"\n" +
+	"ColorTheme\x12%\n" +
+	"\x0edominant_color\x18\x01 \x01(\tR\rdominantColor\x12\x18\n" +
+	"\apalette\x18\x02 \x03(\tR\apalette\x12+\n" +
+	"\x11average_luminance\x18\x03 \x01(\x01R\x10averageLuminance\x12\x1e\n" +
+	"\n" +
+	"foreground\x18\x04 \x01(\tR\n" +
+	"foreground\"\x8c\x02\n" +
+	"\x16GetCurrentImageRequest\x122\n" +
+	"\x04mode\x18\x01 \x01(\x0e2\x1e.imageservice.CurrentImageModeR\x04mode\x12\x1f\n" +
+	"\blatitude\x18\x02 \x01(\x01H\x00R\blatitude\x88\x01\x01\x12!\n" +
+	"\tlongitude\x18\x03 \x01(\x01H\x01R\tlongitude\x88\x01\x01\x12\x1a\n" +
+	"\btimezone\x18\x04 \x01(\tR\btimezone\x12\x17\n" +
+	"\x04seed\x18\x05 \x01(\x03H\x02R\x04seed\x88\x01\x01\x12!\n" +
+	"\fviewer_token\x18\x06 \x01(\tR\vviewerTokenB\v\n" +
+	"\t_latitudeB\f\n" +
+	"\n" +
+	"_longitudeB\a\n" +
+	"\x05_seed\"\xc5\x04\n" +
 	"\x12UploadImageRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
 	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
 	"\vdescription\x18\x03 \x01(\tR\vdescription\x122\n" +
 	"\blocation\x18\x04 \x01(\v2\x16.imageservice.LocationR\blocation\x12\x1d\n" +
 	"\n" +
-	"image_data\x18\x05 \x01(\fR\timageData\"\x16\n" +
-	"\x14GetImageCountRequest\"\x13\n" +
-	"\x11ListImagesRequest\"0\n" +
+	"image_data\x18\x05 \x01(\fR\timageData\x12'\n" +
+	"\x0fidempotency_key\x18\x06 \x01(\tR\x0eidempotencyKey\x12H\n" +
+	"\x10duplicate_policy\x18\a \x01(\x0e2\x1d.imageservice.DuplicatePolicyR\x0fduplicatePolicy\x124\n" +
+	"\x13duplicate_threshold\x18\b \x01(\x05H\x00R\x12duplicateThreshold\x88\x01\x01\x129\n" +
+	"\n" +
+	"publish_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tpublishAt\x127\n" +
+	"\texpire_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\bexpireAt\x127\n" +
+	"\vtime_of_day\x18\v \x01(\x0e2\x17.imageservice.TimeOfDayR\ttimeOfDay\x12\x1b\n" +
+	"\x06weight\x18\f \x01(\x01H\x01R\x06weight\x88\x01\x01B\x16\n" +
+	"\x14_duplicate_thresholdB\t\n" +
+	"\a_weight\"6\n" +
+	"\x14GetImageCountRequest\x12\x1e\n" +
+	"\n" +
+	"visibility\x18\x01 \x01(\tR\n" +
+	"visibility\"\xf6\x04\n" +
+	"\x11ListImagesRequest\x12;\n" +
+	"\vtaken_after\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"takenAfter\x12=\n" +
+	"\ftaken_before\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\vtakenBefore\x12\x1f\n" +
+	"\vcamera_make\x18\x03 \x01(\tR\n" +
+	"cameraMake\x12!\n" +
+	"\fcamera_model\x18\x04 \x01(\tR\vcameraModel\x12\x1d\n" +
+	"\n" +
+	"lens_model\x18\x05 \x01(\tR\tlensModel\x12\x1c\n" +
+	"\amin_iso\x18\x06 \x01(\x05H\x00R\x06minIso\x88\x01\x01\x12\x1c\n" +
+	"\amax_iso\x18\a \x01(\x05H\x01R\x06maxIso\x88\x01\x01\x122\n" +
+	"\x13min_focal_length_mm\x18\b \x01(\x01H\x02R\x10minFocalLengthMm\x88\x01\x01\x122\n" +
+	"\x13max_focal_length_mm\x18\t \x01(\x01H\x03R\x10maxFocalLengthMm\x88\x01\x01\x12\x17\n" +
+	"\asort_by\x18\n" +
+	" \x01(\tR\x06sortBy\x12\x1d\n" +
+	"\n" +
+	"sort_order\x18\v \x01(\tR\tsortOrder\x12%\n" +
+	"\x0einclude_hidden\x18\f \x01(\bR\rincludeHidden\x127\n" +
+	"\vtime_of_day\x18\r \x01(\x0e2\x17.imageservice.TimeOfDayR\ttimeOfDayB\n" +
+	"\n" +
+	"\b_min_isoB\n" +
+	"\n" +
+	"\b_max_isoB\x16\n" +
+	"\x14_min_focal_length_mmB\x16\n" +
+	"\x14_max_focal_length_mm\"W\n" +
 	"\x13GetImageByIdRequest\x12\x19\n" +
-	"\bimage_id\x18\x01 \x01(\tR\aimageId\"/\n" +
+	"\bimage_id\x18\x01 \x01(\tR\aimageId\x12%\n" +
+	"\x0einclude_hidden\x18\x02 \x01(\bR\rincludeHidden\"\xcf\x04\n" +
+	"\x12UpdateImageRequest\x12\x19\n" +
+	"\bimage_id\x18\x01 \x01(\tR\aimageId\x12\x19\n" +
+	"\x05title\x18\x02 \x01(\tH\x00R\x05title\x88\x01\x01\x12%\n" +
+	"\vdescription\x18\x03 \x01(\tH\x01R\vdescription\x88\x01\x01\x122\n" +
+	"\blocation\x18\x04 \x01(\v2\x16.imageservice.LocationR\blocation\x12\x1c\n" +
+	"\afocal_x\x18\x05 \x01(\x01H\x02R\x06focalX\x88\x01\x01\x12\x1c\n" +
+	"\afocal_y\x18\x06 \x01(\x01H\x03R\x06focalY\x88\x01\x01\x129\n" +
+	"\n" +
+	"publish_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tpublishAt\x127\n" +
+	"\texpire_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\bexpireAt\x12(\n" +
+	"\x10clear_publish_at\x18\t \x01(\bR\x0eclearPublishAt\x12&\n" +
+	"\x0fclear_expire_at\x18\n" +
+	" \x01(\bR\rclearExpireAt\x12<\n" +
+	"\vtime_of_day\x18\v \x01(\x0e2\x17.imageservice.TimeOfDayH\x04R\ttimeOfDay\x88\x01\x01\x12\x1b\n" +
+	"\x06weight\x18\f \x01(\x01H\x05R\x06weight\x88\x01\x01B\b\n" +
+	"\x06_titleB\x0e\n" +
+	"\f_descriptionB\n" +
+	"\n" +
+	"\b_focal_xB\n" +
+	"\n" +
+	"\b_focal_yB\x0e\n" +
+	"\f_time_of_dayB\t\n" +
+	"\a_weight\"/\n" +
 	"\x12DeleteImageRequest\x12\x19\n" +
-	"\bimage_id\x18\x01 \x01(\tR\aimageId\"\x86\x01\n" +
+	"\bimage_id\x18\x01 \x01(\tR\aimageId\"e\n" +
+	"\x16PinCurrentImageRequest\x12\x19\n" +
+	"\bimage_id\x18\x01 \x01(\tR\aimageId\x120\n" +
+	"\x05until\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x05until\"\x1a\n" +
+	"\x18UnpinCurrentImageRequest\"'\n" +
+	"\x0fListPinsRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\"\xaa\x01\n" +
+	"\x17GetSimilarImagesRequest\x12\x19\n" +
+	"\bimage_id\x18\x01 \x01(\tR\aimageId\x12&\n" +
+	"\fmax_distance\x18\x02 \x01(\x05H\x00R\vmaxDistance\x88\x01\x01\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12%\n" +
+	"\x0einclude_hidden\x18\x04 \x01(\bR\rincludeHiddenB\x0f\n" +
+	"\r_max_distance\"\xd4\x03\n" +
 	"\x17GetCurrentImageResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x127\n" +
-	"\bmetadata\x18\x03 \x01(\v2\x1b.imageservice.ImageMetadataR\bmetadata\"\x9d\x01\n" +
+	"\bmetadata\x18\x03 \x01(\v2\x1b.imageservice.ImageMetadataR\bmetadata\x129\n" +
+	"\vcolor_theme\x18\x04 \x01(\v2\x18.imageservice.ColorThemeR\n" +
+	"colorTheme\x12D\n" +
+	"\x10next_rotation_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x0enextRotationAt\x12(\n" +
+	"\x03pin\x18\x06 \x01(\v2\x16.imageservice.ImagePinR\x03pin\x127\n" +
+	"\vtime_of_day\x18\a \x01(\x0e2\x17.imageservice.TimeOfDayR\ttimeOfDay\x124\n" +
+	"\asunrise\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\asunrise\x122\n" +
+	"\x06sunset\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\x06sunset\"\xf5\x01\n" +
+	"\bImagePin\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x19\n" +
+	"\bimage_id\x18\x02 \x01(\tR\aimageId\x127\n" +
+	"\tpinned_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\bpinnedAt\x120\n" +
+	"\x05until\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x05until\x12;\n" +
+	"\vunpinned_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"unpinnedAt\x12\x16\n" +
+	"\x06active\x18\x06 \x01(\bR\x06active\"\x9d\x02\n" +
 	"\x13UploadImageResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x19\n" +
 	"\bimage_id\x18\x03 \x01(\tR\aimageId\x127\n" +
-	"\bmetadata\x18\x04 \x01(\v2\x1b.imageservice.ImageMetadataR\bmetadata\"-\n" +
+	"\bmetadata\x18\x04 \x01(\v2\x1b.imageservice.ImageMetadataR\bmetadata\x12\x1a\n" +
+	"\breplayed\x18\x05 \x01(\bR\breplayed\x12\x1f\n" +
+	"\vexif_fields\x18\x06 \x03(\tR\n" +
+	"exifFields\x12A\n" +
+	"\x0esimilar_images\x18\a \x03(\v2\x1a.imageservice.SimilarImageR\rsimilarImages\"\x7f\n" +
 	"\x15GetImageCountResponse\x12\x14\n" +
-	"\x05count\x18\x01 \x01(\x05R\x05count\"}\n" +
+	"\x05count\x18\x01 \x01(\x05R\x05count\x12\x18\n" +
+	"\avisible\x18\x02 \x01(\x05R\avisible\x12\x1c\n" +
+	"\tscheduled\x18\x03 \x01(\x05R\tscheduled\x12\x18\n" +
+	"\aexpired\x18\x04 \x01(\x05R\aexpired\"}\n" +
 	"\x12ListImagesResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x123\n" +
@@ -1069,10 +2741,33 @@ const file_imageservice_proto_rawDesc = "" +
 	"\x14GetImageByIdResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x127\n" +
+	"\bmetadata\x18\x03 \x01(\v2\x1b.imageservice.ImageMetadataR\bmetadata\"\x82\x01\n" +
+	"\x13UpdateImageResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x127\n" +
 	"\bmetadata\x18\x03 \x01(\v2\x1b.imageservice.ImageMetadataR\bmetadata\"I\n" +
 	"\x13DeleteImageResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"X\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"c\n" +
+	"\fSimilarImage\x127\n" +
+	"\bmetadata\x18\x01 \x01(\v2\x1b.imageservice.ImageMetadataR\bmetadata\x12\x1a\n" +
+	"\bdistance\x18\x02 \x01(\x05R\bdistance\"w\n" +
+	"\x17PinCurrentImageResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12(\n" +
+	"\x03pin\x18\x03 \x01(\v2\x16.imageservice.ImagePinR\x03pin\"y\n" +
+	"\x19UnpinCurrentImageResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12(\n" +
+	"\x03pin\x18\x03 \x01(\v2\x16.imageservice.ImagePinR\x03pin\"r\n" +
+	"\x10ListPinsResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12*\n" +
+	"\x04pins\x18\x03 \x03(\v2\x16.imageservice.ImagePinR\x04pins\"\x82\x01\n" +
+	"\x18GetSimilarImagesResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x122\n" +
+	"\x06images\x18\x03 \x03(\v2\x1a.imageservice.SimilarImageR\x06images\"X\n" +
 	"\x1cGetLocationFromCoordsRequest\x12\x1a\n" +
 	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
 	"\tlongitude\x18\x02 \x01(\x01R\tlongitude\"A\n" +
@@ -1085,7 +2780,20 @@ const file_imageservice_proto_rawDesc = "" +
 	"\x1bGetLocationFromNameResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x122\n" +
-	"\blocation\x18\x03 \x01(\v2\x16.imageservice.LocationR\blocation2\x98\x04\n" +
+	"\blocation\x18\x03 \x01(\v2\x16.imageservice.LocationR\blocation*\x80\x01\n" +
+	"\tTimeOfDay\x12\x1b\n" +
+	"\x17TIME_OF_DAY_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10TIME_OF_DAY_DAWN\x10\x01\x12\x13\n" +
+	"\x0fTIME_OF_DAY_DAY\x10\x02\x12\x14\n" +
+	"\x10TIME_OF_DAY_DUSK\x10\x03\x12\x15\n" +
+	"\x11TIME_OF_DAY_NIGHT\x10\x04*W\n" +
+	"\x10CurrentImageMode\x12\x1f\n" +
+	"\x1bCURRENT_IMAGE_MODE_ROTATION\x10\x00\x12\"\n" +
+	"\x1eCURRENT_IMAGE_MODE_TIME_OF_DAY\x10\x01*e\n" +
+	"\x0fDuplicatePolicy\x12\x1a\n" +
+	"\x16DUPLICATE_POLICY_ALLOW\x10\x00\x12\x19\n" +
+	"\x15DUPLICATE_POLICY_WARN\x10\x01\x12\x1b\n" +
+	"\x17DUPLICATE_POLICY_REJECT\x10\x022\xe0\a\n" +
 	"\fImageService\x12^\n" +
 	"\x0fGetCurrentImage\x12$.imageservice.GetCurrentImageRequest\x1a%.imageservice.GetCurrentImageResponse\x12R\n" +
 	"\vUploadImage\x12 .imageservice.UploadImageRequest\x1a!.imageservice.UploadImageResponse\x12X\n" +
@@ -1093,7 +2801,12 @@ const file_imageservice_proto_rawDesc = "" +
 	"\n" +
 	"ListImages\x12\x1f.imageservice.ListImagesRequest\x1a .imageservice.ListImagesResponse\x12U\n" +
 	"\fGetImageById\x12!.imageservice.GetImageByIdRequest\x1a\".imageservice.GetImageByIdResponse\x12R\n" +
-	"\vDeleteImage\x12 .imageservice.DeleteImageRequest\x1a!.imageservice.DeleteImageResponse2\xef\x01\n" +
+	"\vUpdateImage\x12 .imageservice.UpdateImageRequest\x1a!.imageservice.UpdateImageResponse\x12R\n" +
+	"\vDeleteImage\x12 .imageservice.DeleteImageRequest\x1a!.imageservice.DeleteImageResponse\x12a\n" +
+	"\x10GetSimilarImages\x12%.imageservice.GetSimilarImagesRequest\x1a&.imageservice.GetSimilarImagesResponse\x12^\n" +
+	"\x0fPinCurrentImage\x12$.imageservice.PinCurrentImageRequest\x1a%.imageservice.PinCurrentImageResponse\x12d\n" +
+	"\x11UnpinCurrentImage\x12&.imageservice.UnpinCurrentImageRequest\x1a'.imageservice.UnpinCurrentImageResponse\x12I\n" +
+	"\bListPins\x12\x1d.imageservice.ListPinsRequest\x1a\x1e.imageservice.ListPinsResponse2\xef\x01\n" +
 	"\x0fLocationService\x12p\n" +
 	"\x15GetLocationFromCoords\x12*.imageservice.GetLocationFromCoordsRequest\x1a+.imageservice.GetLocationFromCoordsResponse\x12j\n" +
 	"\x13GetLocationFromName\x12(.imageservice.GetLocationFromNameRequest\x1a).imageservice.GetLocationFromNameResponseB=Z;github.com/NirvekPanda/Background-Image-Drive-API/proto/genb\x06proto3"
@@ -1110,57 +2823,120 @@ func file_imageservice_proto_rawDescGZIP() []byte {
 	return file_imageservice_proto_rawDescData
 }
 
-var file_imageservice_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_imageservice_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_imageservice_proto_msgTypes = make([]protoimpl.MessageInfo, 31)
 var file_imageservice_proto_goTypes = []any{
-	(*Location)(nil),                      // 0: imageservice.Location
-	(*ImageMetadata)(nil),                 // 1: imageservice.ImageMetadata
-	(*GetCurrentImageRequest)(nil),        // 2: imageservice.GetCurrentImageRequest
-	(*UploadImageRequest)(nil),            // 3: imageservice.UploadImageRequest
-	(*GetImageCountRequest)(nil),          // 4: imageservice.GetImageCountRequest
-	(*ListImagesRequest)(nil),             // 5: imageservice.ListImagesRequest
-	(*GetImageByIdRequest)(nil),           // 6: imageservice.GetImageByIdRequest
-	(*DeleteImageRequest)(nil),            // 7: imageservice.DeleteImageRequest
-	(*GetCurrentImageResponse)(nil),       // 8: imageservice.GetCurrentImageResponse
-	(*UploadImageResponse)(nil),           // 9: imageservice.UploadImageResponse
-	(*GetImageCountResponse)(nil),         // 10: imageservice.GetImageCountResponse
-	(*ListImagesResponse)(nil),            // 11: imageservice.ListImagesResponse
-	(*GetImageByIdResponse)(nil),          // 12: imageservice.GetImageByIdResponse
-	(*DeleteImageResponse)(nil),           // 13: imageservice.DeleteImageResponse
-	(*GetLocationFromCoordsRequest)(nil),  // 14: imageservice.GetLocationFromCoordsRequest
-	(*GetLocationFromNameRequest)(nil),    // 15: imageservice.GetLocationFromNameRequest
-	(*GetLocationFromCoordsResponse)(nil), // 16: imageservice.GetLocationFromCoordsResponse
-	(*GetLocationFromNameResponse)(nil),   // 17: imageservice.GetLocationFromNameResponse
+	(TimeOfDay)(0),                        // 0: imageservice.TimeOfDay
+	(CurrentImageMode)(0),                 // 1: imageservice.CurrentImageMode
+	(DuplicatePolicy)(0),                  // 2: imageservice.DuplicatePolicy
+	(*Location)(nil),                      // 3: imageservice.Location
+	(*ImageMetadata)(nil),                 // 4: imageservice.ImageMetadata
+	(*ColorTheme)(nil),                    // 5: imageservice.ColorTheme
+	(*GetCurrentImageRequest)(nil),        // 6: imageservice.GetCurrentImageRequest
+	(*UploadImageRequest)(nil),            // 7: imageservice.UploadImageRequest
+	(*GetImageCountRequest)(nil),          // 8: imageservice.GetImageCountRequest
+	(*ListImagesRequest)(nil),             // 9: imageservice.ListImagesRequest
+	(*GetImageByIdRequest)(nil),           // 10: imageservice.GetImageByIdRequest
+	(*UpdateImageRequest)(nil),            // 11: imageservice.UpdateImageRequest
+	(*DeleteImageRequest)(nil),            // 12: imageservice.DeleteImageRequest
+	(*PinCurrentImageRequest)(nil),        // 13: imageservice.PinCurrentImageRequest
+	(*UnpinCurrentImageRequest)(nil),      // 14: imageservice.UnpinCurrentImageRequest
+	(*ListPinsRequest)(nil),               // 15: imageservice.ListPinsRequest
+	(*GetSimilarImagesRequest)(nil),       // 16: imageservice.GetSimilarImagesRequest
+	(*GetCurrentImageResponse)(nil),       // 17: imageservice.GetCurrentImageResponse
+	(*ImagePin)(nil),                      // 18: imageservice.ImagePin
+	(*UploadImageResponse)(nil),           // 19: imageservice.UploadImageResponse
+	(*GetImageCountResponse)(nil),         // 20: imageservice.GetImageCountResponse
+	(*ListImagesResponse)(nil),            // 21: imageservice.ListImagesResponse
+	(*GetImageByIdResponse)(nil),          // 22: imageservice.GetImageByIdResponse
+	(*UpdateImageResponse)(nil),           // 23: imageservice.UpdateImageResponse
+	(*DeleteImageResponse)(nil),           // 24: imageservice.DeleteImageResponse
+	(*SimilarImage)(nil),                  // 25: imageservice.SimilarImage
+	(*PinCurrentImageResponse)(nil),       // 26: imageservice.PinCurrentImageResponse
+	(*UnpinCurrentImageResponse)(nil),     // 27: imageservice.UnpinCurrentImageResponse
+	(*ListPinsResponse)(nil),              // 28: imageservice.ListPinsResponse
+	(*GetSimilarImagesResponse)(nil),      // 29: imageservice.GetSimilarImagesResponse
+	(*GetLocationFromCoordsRequest)(nil),  // 30: imageservice.GetLocationFromCoordsRequest
+	(*GetLocationFromNameRequest)(nil),    // 31: imageservice.GetLocationFromNameRequest
+	(*GetLocationFromCoordsResponse)(nil), // 32: imageservice.GetLocationFromCoordsResponse
+	(*GetLocationFromNameResponse)(nil),   // 33: imageservice.GetLocationFromNameResponse
+	(*timestamppb.Timestamp)(nil),         // 34: google.protobuf.Timestamp
 }
 var file_imageservice_proto_depIdxs = []int32{
-	0,  // 0: imageservice.ImageMetadata.location:type_name -> imageservice.Location
-	0,  // 1: imageservice.UploadImageRequest.location:type_name -> imageservice.Location
-	1,  // 2: imageservice.GetCurrentImageResponse.metadata:type_name -> imageservice.ImageMetadata
-	1,  // 3: imageservice.UploadImageResponse.metadata:type_name -> imageservice.ImageMetadata
-	1,  // 4: imageservice.ListImagesResponse.images:type_name -> imageservice.ImageMetadata
-	1,  // 5: imageservice.GetImageByIdResponse.metadata:type_name -> imageservice.ImageMetadata
-	0,  // 6: imageservice.GetLocationFromCoordsResponse.location:type_name -> imageservice.Location
-	0,  // 7: imageservice.GetLocationFromNameResponse.location:type_name -> imageservice.Location
-	2,  // 8: imageservice.ImageService.GetCurrentImage:input_type -> imageservice.GetCurrentImageRequest
-	3,  // 9: imageservice.ImageService.UploadImage:input_type -> imageservice.UploadImageRequest
-	4,  // 10: imageservice.ImageService.GetImageCount:input_type -> imageservice.GetImageCountRequest
-	5,  // 11: imageservice.ImageService.ListImages:input_type -> imageservice.ListImagesRequest
-	6,  // 12: imageservice.ImageService.GetImageById:input_type -> imageservice.GetImageByIdRequest
-	7,  // 13: imageservice.ImageService.DeleteImage:input_type -> imageservice.DeleteImageRequest
-	14, // 14: imageservice.LocationService.GetLocationFromCoords:input_type -> imageservice.GetLocationFromCoordsRequest
-	15, // 15: imageservice.LocationService.GetLocationFromName:input_type -> imageservice.GetLocationFromNameRequest
-	8,  // 16: imageservice.ImageService.GetCurrentImage:output_type -> imageservice.GetCurrentImageResponse
-	9,  // 17: imageservice.ImageService.UploadImage:output_type -> imageservice.UploadImageResponse
-	10, // 18: imageservice.ImageService.GetImageCount:output_type -> imageservice.GetImageCountResponse
-	11, // 19: imageservice.ImageService.ListImages:output_type -> imageservice.ListImagesResponse
-	12, // 20: imageservice.ImageService.GetImageById:output_type -> imageservice.GetImageByIdResponse
-	13, // 21: imageservice.ImageService.DeleteImage:output_type -> imageservice.DeleteImageResponse
-	16, // 22: imageservice.LocationService.GetLocationFromCoords:output_type -> imageservice.GetLocationFromCoordsResponse
-	17, // 23: imageservice.LocationService.GetLocationFromName:output_type -> imageservice.GetLocationFromNameResponse
-	16, // [16:24] is the sub-list for method output_type
-	8,  // [8:16] is the sub-list for method input_type
-	8,  // [8:8] is the sub-list for extension type_name
-	8,  // [8:8] is the sub-list for extension extendee
-	0,  // [0:8] is the sub-list for field type_name
+	3,  // 0: imageservice.ImageMetadata.location:type_name -> imageservice.Location
+	34, // 1: imageservice.ImageMetadata.created_at:type_name -> google.protobuf.Timestamp
+	34, // 2: imageservice.ImageMetadata.taken_at:type_name -> google.protobuf.Timestamp
+	5,  // 3: imageservice.ImageMetadata.color_theme:type_name -> imageservice.ColorTheme
+	34, // 4: imageservice.ImageMetadata.publish_at:type_name -> google.protobuf.Timestamp
+	34, // 5: imageservice.ImageMetadata.expire_at:type_name -> google.protobuf.Timestamp
+	0,  // 6: imageservice.ImageMetadata.time_of_day:type_name -> imageservice.TimeOfDay
+	1,  // 7: imageservice.GetCurrentImageRequest.mode:type_name -> imageservice.CurrentImageMode
+	3,  // 8: imageservice.UploadImageRequest.location:type_name -> imageservice.Location
+	2,  // 9: imageservice.UploadImageRequest.duplicate_policy:type_name -> imageservice.DuplicatePolicy
+	34, // 10: imageservice.UploadImageRequest.publish_at:type_name -> google.protobuf.Timestamp
+	34, // 11: imageservice.UploadImageRequest.expire_at:type_name -> google.protobuf.Timestamp
+	0,  // 12: imageservice.UploadImageRequest.time_of_day:type_name -> imageservice.TimeOfDay
+	34, // 13: imageservice.ListImagesRequest.taken_after:type_name -> google.protobuf.Timestamp
+	34, // 14: imageservice.ListImagesRequest.taken_before:type_name -> google.protobuf.Timestamp
+	0,  // 15: imageservice.ListImagesRequest.time_of_day:type_name -> imageservice.TimeOfDay
+	3,  // 16: imageservice.UpdateImageRequest.location:type_name -> imageservice.Location
+	34, // 17: imageservice.UpdateImageRequest.publish_at:type_name -> google.protobuf.Timestamp
+	34, // 18: imageservice.UpdateImageRequest.expire_at:type_name -> google.protobuf.Timestamp
+	0,  // 19: imageservice.UpdateImageRequest.time_of_day:type_name -> imageservice.TimeOfDay
+	34, // 20: imageservice.PinCurrentImageRequest.until:type_name -> google.protobuf.Timestamp
+	4,  // 21: imageservice.GetCurrentImageResponse.metadata:type_name -> imageservice.ImageMetadata
+	5,  // 22: imageservice.GetCurrentImageResponse.color_theme:type_name -> imageservice.ColorTheme
+	34, // 23: imageservice.GetCurrentImageResponse.next_rotation_at:type_name -> google.protobuf.Timestamp
+	18, // 24: imageservice.GetCurrentImageResponse.pin:type_name -> imageservice.ImagePin
+	0,  // 25: imageservice.GetCurrentImageResponse.time_of_day:type_name -> imageservice.TimeOfDay
+	34, // 26: imageservice.GetCurrentImageResponse.sunrise:type_name -> google.protobuf.Timestamp
+	34, // 27: imageservice.GetCurrentImageResponse.sunset:type_name -> google.protobuf.Timestamp
+	34, // 28: imageservice.ImagePin.pinned_at:type_name -> google.protobuf.Timestamp
+	34, // 29: imageservice.ImagePin.until:type_name -> google.protobuf.Timestamp
+	34, // 30: imageservice.ImagePin.unpinned_at:type_name -> google.protobuf.Timestamp
+	4,  // 31: imageservice.UploadImageResponse.metadata:type_name -> imageservice.ImageMetadata
+	25, // 32: imageservice.UploadImageResponse.similar_images:type_name -> imageservice.SimilarImage
+	4,  // 33: imageservice.ListImagesResponse.images:type_name -> imageservice.ImageMetadata
+	4,  // 34: imageservice.GetImageByIdResponse.metadata:type_name -> imageservice.ImageMetadata
+	4,  // 35: imageservice.UpdateImageResponse.metadata:type_name -> imageservice.ImageMetadata
+	4,  // 36: imageservice.SimilarImage.metadata:type_name -> imageservice.ImageMetadata
+	18, // 37: imageservice.PinCurrentImageResponse.pin:type_name -> imageservice.ImagePin
+	18, // 38: imageservice.UnpinCurrentImageResponse.pin:type_name -> imageservice.ImagePin
+	18, // 39: imageservice.ListPinsResponse.pins:type_name -> imageservice.ImagePin
+	25, // 40: imageservice.GetSimilarImagesResponse.images:type_name -> imageservice.SimilarImage
+	3,  // 41: imageservice.GetLocationFromCoordsResponse.location:type_name -> imageservice.Location
+	3,  // 42: imageservice.GetLocationFromNameResponse.location:type_name -> imageservice.Location
+	6,  // 43: imageservice.ImageService.GetCurrentImage:input_type -> imageservice.GetCurrentImageRequest
+	7,  // 44: imageservice.ImageService.UploadImage:input_type -> imageservice.UploadImageRequest
+	8,  // 45: imageservice.ImageService.GetImageCount:input_type -> imageservice.GetImageCountRequest
+	9,  // 46: imageservice.ImageService.ListImages:input_type -> imageservice.ListImagesRequest
+	10, // 47: imageservice.ImageService.GetImageById:input_type -> imageservice.GetImageByIdRequest
+	11, // 48: imageservice.ImageService.UpdateImage:input_type -> imageservice.UpdateImageRequest
+	12, // 49: imageservice.ImageService.DeleteImage:input_type -> imageservice.DeleteImageRequest
+	16, // 50: imageservice.ImageService.GetSimilarImages:input_type -> imageservice.GetSimilarImagesRequest
+	13, // 51: imageservice.ImageService.PinCurrentImage:input_type -> imageservice.PinCurrentImageRequest
+	14, // 52: imageservice.ImageService.UnpinCurrentImage:input_type -> imageservice.UnpinCurrentImageRequest
+	15, // 53: imageservice.ImageService.ListPins:input_type -> imageservice.ListPinsRequest
+	30, // 54: imageservice.LocationService.GetLocationFromCoords:input_type -> imageservice.GetLocationFromCoordsRequest
+	31, // 55: imageservice.LocationService.GetLocationFromName:input_type -> imageservice.GetLocationFromNameRequest
+	17, // 56: imageservice.ImageService.GetCurrentImage:output_type -> imageservice.GetCurrentImageResponse
+	19, // 57: imageservice.ImageService.UploadImage:output_type -> imageservice.UploadImageResponse
+	20, // 58: imageservice.ImageService.GetImageCount:output_type -> imageservice.GetImageCountResponse
+	21, // 59: imageservice.ImageService.ListImages:output_type -> imageservice.ListImagesResponse
+	22, // 60: imageservice.ImageService.GetImageById:output_type -> imageservice.GetImageByIdResponse
+	23, // 61: imageservice.ImageService.UpdateImage:output_type -> imageservice.UpdateImageResponse
+	24, // 62: imageservice.ImageService.DeleteImage:output_type -> imageservice.DeleteImageResponse
+	29, // 63: imageservice.ImageService.GetSimilarImages:output_type -> imageservice.GetSimilarImagesResponse
+	26, // 64: imageservice.ImageService.PinCurrentImage:output_type -> imageservice.PinCurrentImageResponse
+	27, // 65: imageservice.ImageService.UnpinCurrentImage:output_type -> imageservice.UnpinCurrentImageResponse
+	28, // 66: imageservice.ImageService.ListPins:output_type -> imageservice.ListPinsResponse
+	32, // 67: imageservice.LocationService.GetLocationFromCoords:output_type -> imageservice.GetLocationFromCoordsResponse
+	33, // 68: imageservice.LocationService.GetLocationFromName:output_type -> imageservice.GetLocationFromNameResponse
+	56, // [56:69] is the sub-list for method output_type
+	43, // [43:56] is the sub-list for method input_type
+	43, // [43:43] is the sub-list for extension type_name
+	43, // [43:43] is the sub-list for extension extendee
+	0,  // [0:43] is the sub-list for field type_name
 }
 
 func init() { file_imageservice_proto_init() }
@@ -1168,18 +2944,25 @@ func file_imageservice_proto_init() {
 	if File_imageservice_proto != nil {
 		return
 	}
+	file_imageservice_proto_msgTypes[1].OneofWrappers = []any{}
+	file_imageservice_proto_msgTypes[3].OneofWrappers = []any{}
+	file_imageservice_proto_msgTypes[4].OneofWrappers = []any{}
+	file_imageservice_proto_msgTypes[6].OneofWrappers = []any{}
+	file_imageservice_proto_msgTypes[8].OneofWrappers = []any{}
+	file_imageservice_proto_msgTypes[13].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_imageservice_proto_rawDesc), len(file_imageservice_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   18,
+			NumEnums:      3,
+			NumMessages:   31,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
 		GoTypes:           file_imageservice_proto_goTypes,
 		DependencyIndexes: file_imageservice_proto_depIdxs,
+		EnumInfos:         file_imageservice_proto_enumTypes,
 		MessageInfos:      file_imageservice_proto_msgTypes,
 	}.Build()
 	File_imageservice_proto = out.File
@@ -19,12 +19,17 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	ImageService_GetCurrentImage_FullMethodName = "/imageservice.ImageService/GetCurrentImage"
-	ImageService_UploadImage_FullMethodName     = "/imageservice.ImageService/UploadImage"
-	ImageService_GetImageCount_FullMethodName   = "/imageservice.ImageService/GetImageCount"
-	ImageService_ListImages_FullMethodName      = "/imageservice.ImageService/ListImages"
-	ImageService_GetImageById_FullMethodName    = "/imageservice.ImageService/GetImageById"
-	ImageService_DeleteImage_FullMethodName     = "/imageservice.ImageService/DeleteImage"
+	ImageService_GetCurrentImage_FullMethodName   = "/imageservice.ImageService/GetCurrentImage"
+	ImageService_UploadImage_FullMethodName       = "/imageservice.ImageService/UploadImage"
+	ImageService_GetImageCount_FullMethodName     = "/imageservice.ImageService/GetImageCount"
+	ImageService_ListImages_FullMethodName        = "/imageservice.ImageService/ListImages"
+	ImageService_GetImageById_FullMethodName      = "/imageservice.ImageService/GetImageById"
+	ImageService_UpdateImage_FullMethodName       = "/imageservice.ImageService/UpdateImage"
+	ImageService_DeleteImage_FullMethodName       = "/imageservice.ImageService/DeleteImage"
+	ImageService_GetSimilarImages_FullMethodName  = "/imageservice.ImageService/GetSimilarImages"
+	ImageService_PinCurrentImage_FullMethodName   = "/imageservice.ImageService/PinCurrentImage"
+	ImageService_UnpinCurrentImage_FullMethodName = "/imageservice.ImageService/UnpinCurrentImage"
+	ImageService_ListPins_FullMethodName          = "/imageservice.ImageService/ListPins"
 )
 
 // ImageServiceClient is the client API for ImageService service.
@@ -43,8 +48,18 @@ type ImageServiceClient interface {
 	ListImages(ctx context.Context, in *ListImagesRequest, opts ...grpc.CallOption) (*ListImagesResponse, error)
 	// Get specific image by ID
 	GetImageById(ctx context.Context, in *GetImageByIdRequest, opts ...grpc.CallOption) (*GetImageByIdResponse, error)
+	// Update an image's title, description or location
+	UpdateImage(ctx context.Context, in *UpdateImageRequest, opts ...grpc.CallOption) (*UpdateImageResponse, error)
 	// Delete an image
 	DeleteImage(ctx context.Context, in *DeleteImageRequest, opts ...grpc.CallOption) (*DeleteImageResponse, error)
+	// Find images that look like an image, by perceptual hash distance
+	GetSimilarImages(ctx context.Context, in *GetSimilarImagesRequest, opts ...grpc.CallOption) (*GetSimilarImagesResponse, error)
+	// Feature an image as the current image, optionally until a given time
+	PinCurrentImage(ctx context.Context, in *PinCurrentImageRequest, opts ...grpc.CallOption) (*PinCurrentImageResponse, error)
+	// End the active pin so the rotation resumes
+	UnpinCurrentImage(ctx context.Context, in *UnpinCurrentImageRequest, opts ...grpc.CallOption) (*UnpinCurrentImageResponse, error)
+	// List the pin history
+	ListPins(ctx context.Context, in *ListPinsRequest, opts ...grpc.CallOption) (*ListPinsResponse, error)
 }
 
 type imageServiceClient struct {
@@ -105,6 +120,16 @@ func (c *imageServiceClient) GetImageById(ctx context.Context, in *GetImageByIdR
 	return out, nil
 }
 
+func (c *imageServiceClient) UpdateImage(ctx context.Context, in *UpdateImageRequest, opts ...grpc.CallOption) (*UpdateImageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateImageResponse)
+	err := c.cc.Invoke(ctx, ImageService_UpdateImage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *imageServiceClient) DeleteImage(ctx context.Context, in *DeleteImageRequest, opts ...grpc.CallOption) (*DeleteImageResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(DeleteImageResponse)
@@ -115,6 +140,46 @@ func (c *imageServiceClient) DeleteImage(ctx context.Context, in *DeleteImageReq
 	return out, nil
 }
 
+func (c *imageServiceClient) GetSimilarImages(ctx context.Context, in *GetSimilarImagesRequest, opts ...grpc.CallOption) (*GetSimilarImagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSimilarImagesResponse)
+	err := c.cc.Invoke(ctx, ImageService_GetSimilarImages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) PinCurrentImage(ctx context.Context, in *PinCurrentImageRequest, opts ...grpc.CallOption) (*PinCurrentImageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PinCurrentImageResponse)
+	err := c.cc.Invoke(ctx, ImageService_PinCurrentImage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) UnpinCurrentImage(ctx context.Context, in *UnpinCurrentImageRequest, opts ...grpc.CallOption) (*UnpinCurrentImageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnpinCurrentImageResponse)
+	err := c.cc.Invoke(ctx, ImageService_UnpinCurrentImage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) ListPins(ctx context.Context, in *ListPinsRequest, opts ...grpc.CallOption) (*ListPinsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPinsResponse)
+	err := c.cc.Invoke(ctx, ImageService_ListPins_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ImageServiceServer is the server API for ImageService service.
 // All implementations must embed UnimplementedImageServiceServer
 // for forward compatibility.
@@ -131,8 +196,18 @@ type ImageServiceServer interface {
 	ListImages(context.Context, *ListImagesRequest) (*ListImagesResponse, error)
 	// Get specific image by ID
 	GetImageById(context.Context, *GetImageByIdRequest) (*GetImageByIdResponse, error)
+	// Update an image's title, description or location
+	UpdateImage(context.Context, *UpdateImageRequest) (*UpdateImageResponse, error)
 	// Delete an image
 	DeleteImage(context.Context, *DeleteImageRequest) (*DeleteImageResponse, error)
+	// Find images that look like an image, by perceptual hash distance
+	GetSimilarImages(context.Context, *GetSimilarImagesRequest) (*GetSimilarImagesResponse, error)
+	// Feature an image as the current image, optionally until a given time
+	PinCurrentImage(context.Context, *PinCurrentImageRequest) (*PinCurrentImageResponse, error)
+	// End the active pin so the rotation resumes
+	UnpinCurrentImage(context.Context, *UnpinCurrentImageRequest) (*UnpinCurrentImageResponse, error)
+	// List the pin history
+	ListPins(context.Context, *ListPinsRequest) (*ListPinsResponse, error)
 	mustEmbedUnimplementedImageServiceServer()
 }
 
@@ -158,9 +233,24 @@ func (UnimplementedImageServiceServer) ListImages(context.Context, *ListImagesRe
 func (UnimplementedImageServiceServer) GetImageById(context.Context, *GetImageByIdRequest) (*GetImageByIdResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetImageById not implemented")
 }
+func (UnimplementedImageServiceServer) UpdateImage(context.Context, *UpdateImageRequest) (*UpdateImageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateImage not implemented")
+}
 func (UnimplementedImageServiceServer) DeleteImage(context.Context, *DeleteImageRequest) (*DeleteImageResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteImage not implemented")
 }
+func (UnimplementedImageServiceServer) GetSimilarImages(context.Context, *GetSimilarImagesRequest) (*GetSimilarImagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSimilarImages not implemented")
+}
+func (UnimplementedImageServiceServer) PinCurrentImage(context.Context, *PinCurrentImageRequest) (*PinCurrentImageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PinCurrentImage not implemented")
+}
+func (UnimplementedImageServiceServer) UnpinCurrentImage(context.Context, *UnpinCurrentImageRequest) (*UnpinCurrentImageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnpinCurrentImage not implemented")
+}
+func (UnimplementedImageServiceServer) ListPins(context.Context, *ListPinsRequest) (*ListPinsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPins not implemented")
+}
 func (UnimplementedImageServiceServer) mustEmbedUnimplementedImageServiceServer() {}
 func (UnimplementedImageServiceServer) testEmbeddedByValue()                      {}
 
@@ -272,6 +362,24 @@ func _ImageService_GetImageById_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ImageService_UpdateImage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateImageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).UpdateImage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_UpdateImage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).UpdateImage(ctx, req.(*UpdateImageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ImageService_DeleteImage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeleteImageRequest)
 	if err := dec(in); err != nil {
@@ -290,6 +398,78 @@ func _ImageService_DeleteImage_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ImageService_GetSimilarImages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSimilarImagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).GetSimilarImages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_GetSimilarImages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).GetSimilarImages(ctx, req.(*GetSimilarImagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_PinCurrentImage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PinCurrentImageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).PinCurrentImage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_PinCurrentImage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).PinCurrentImage(ctx, req.(*PinCurrentImageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_UnpinCurrentImage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnpinCurrentImageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).UnpinCurrentImage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_UnpinCurrentImage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).UnpinCurrentImage(ctx, req.(*UnpinCurrentImageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_ListPins_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPinsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).ListPins(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_ListPins_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).ListPins(ctx, req.(*ListPinsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ImageService_ServiceDesc is the grpc.ServiceDesc for ImageService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -317,10 +497,30 @@ var ImageService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetImageById",
 			Handler:    _ImageService_GetImageById_Handler,
 		},
+		{
+			MethodName: "UpdateImage",
+			Handler:    _ImageService_UpdateImage_Handler,
+		},
 		{
 			MethodName: "DeleteImage",
 			Handler:    _ImageService_DeleteImage_Handler,
 		},
+		{
+			MethodName: "GetSimilarImages",
+			Handler:    _ImageService_GetSimilarImages_Handler,
+		},
+		{
+			MethodName: "PinCurrentImage",
+			Handler:    _ImageService_PinCurrentImage_Handler,
+		},
+		{
+			MethodName: "UnpinCurrentImage",
+			Handler:    _ImageService_UnpinCurrentImage_Handler,
+		},
+		{
+			MethodName: "ListPins",
+			Handler:    _ImageService_ListPins_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "imageservice.proto",